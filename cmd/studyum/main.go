@@ -12,11 +12,18 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"net"
 	"net/http"
 	"os"
+	"strings"
+	"studyum/grpc/auth/protoauth"
 	applications "studyum/internal/apps"
 	"studyum/internal/auth"
+	authControllers "studyum/internal/auth/controllers"
+	authHandlers "studyum/internal/auth/handlers"
 	"studyum/internal/codes"
 	"studyum/internal/general"
 	"studyum/internal/journal"
@@ -26,9 +33,9 @@ import (
 	"studyum/internal/utils/middlewares"
 	"studyum/pkg/encryption"
 	"studyum/pkg/jwt"
-	"studyum/pkg/jwt/entities"
 	"studyum/pkg/mail"
 	_ "studyum/pkg/validators"
+	"studyum/pkg/webauthn"
 	"time"
 )
 
@@ -74,7 +81,7 @@ func main() {
 	engine.Any("", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, "uptime")
 	})
-	loadSwagger(engine.RouterGroup, "general", "auth", "user", "schedule", "journal")
+	loadSwagger(engine.RouterGroup, "general", "auth", "user", "schedule", "journal", "apps")
 	setupSSL(engine.RouterGroup)
 
 	db := client.Database("Studyum")
@@ -84,44 +91,49 @@ func main() {
 		Password: os.Getenv("REDIS_DB_PASSWORD"),
 	})
 
-	j := jwt.NewWithRedis[jUtils.Claims]("", time.Minute*15, time.Hour*24*30, time.Second*30, os.Getenv("JWT_SECRET"), redisClient)
-	codesController := codes.New(time.Minute*15, time.Minute, mailer, db)
+	j := newJWT(redisClient, encrypt)
+	j.LaunchCron()
+	defer j.StopCron()
+
+	codesController := codes.New(time.Minute*15, time.Minute, os.Getenv("CODES_SECRET"), mailer, db)
 
 	api := engine.Group("/api")
 	api.Use(gin.Logger(), gin.Recovery())
 
-	apps := applications.New(db, encrypt)
+	oidcOptions := authControllers.OIDCOptions{Issuer: os.Getenv("OIDC_ISSUER"), AuthorizationURL: os.Getenv("OIDC_AUTHORIZATION_URL")}
+	webauthnConfig := webauthn.Config{RPID: os.Getenv("WEBAUTHN_RP_ID"), RPName: "Studyum", Origins: strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",")}
+	authMiddleware, authHandler, _, oidcHandler := auth.New(api.Group("/user"), codesController, encrypt, j, db, redisClient, oidcOptions, webauthnConfig)
+
+	grpcServer := newGRPC(authMiddleware)
+	protoauth.RegisterAuthServer(grpcServer, authHandler)
+	authMiddleware.GrpcPublic("/Auth/AuthUser")
 
-	grpcServer := grpc.NewServer()
-	authMiddleware, _, _ := auth.New(api.Group("/user"), grpcServer, codesController, encrypt, j, db)
+	apps, appsOutbox := applications.New(api.Group("/apps"), grpcServer, authMiddleware, db, redisClient, encrypt)
+	go appsOutbox.Run(ctx, time.Second*5)
+
+	engine.GET("/.well-known/jwks.json", authHandler.JWKS)
+	if oidcHandler != nil {
+		engine.GET("/.well-known/openid-configuration", oidcHandler.Configuration)
+	}
 
 	_, generalController := general.New(api, grpcServer, authMiddleware, db)
-	_ = journal.New(api.Group("/journal"), authMiddleware, apps, encrypt, db)
-	_ = schedule.New(api.Group("/schedule"), authMiddleware, apps, generalController, db)
-	_, controller := user.New(api.Group("/user"), authMiddleware, encrypt, codesController, j, db)
+	_, journalController, digestController, checkInController := journal.New(api.Group("/journal"), grpcServer, authMiddleware, apps, encrypt, mailer, nil, db, redisClient)
+	go digestController.Run(ctx, "0 8 * * 1")
+	go checkInController.Run(ctx, time.Minute)
+	_ = schedule.New(api.Group("/schedule"), grpcServer, authMiddleware, apps, generalController, journalController, db)
+	_, controller := user.New(api.Group("/user"), authMiddleware, encrypt, codesController, j, mailer, db, redisClient)
 	j.SetCreateClaimsFunc(func(ctx context.Context, id, userID string) (jUtils.Claims, error) {
 		u, err := controller.GetByID(ctx, userID)
 		if err != nil {
 			return jUtils.Claims{}, err
 		}
 
-		return jUtils.Claims{
-			IDClaims:      entities.IDClaims{ID: id},
-			UserID:        u.Id.Hex(),
-			Login:         u.Login,
-			PictureURL:    u.PictureUrl,
-			Email:         u.Email,
-			VerifiedEmail: u.VerifiedEmail,
-			StudyPlaceInfo: jUtils.ClaimsStudyPlaceInfo{
-				Id:           u.StudyPlaceInfo.ID.Hex(),
-				Name:         u.StudyPlaceInfo.Name,
-				Role:         u.StudyPlaceInfo.Role,
-				RoleName:     u.StudyPlaceInfo.RoleName,
-				TuitionGroup: u.StudyPlaceInfo.TuitionGroup,
-				Permissions:  u.StudyPlaceInfo.Permissions,
-				Accepted:     u.StudyPlaceInfo.Accepted,
-			},
-		}, nil
+		u, err = authMiddleware.ResolvePermissions(ctx, u)
+		if err != nil {
+			return jUtils.Claims{}, err
+		}
+
+		return jUtils.NewClaims(id, u), nil
 	})
 
 	go launchGRPC(grpcServer)
@@ -143,6 +155,22 @@ func main() {
 	logrus.Fatalf("Error launching server %s", engine.RunTLS(":443", cert, key).Error())
 }
 
+// newJWT signs tokens with JWT_SECRET unless JWT_ALGORITHM asks for rotated RS256 or EdDSA keys,
+// which are stored encrypted with ENCRYPTION_SECRET
+func newJWT(redisClient *redis.Client, encrypt encryption.Encryption) jUtils.JWT {
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" || algorithm == "HS256" {
+		return jwt.NewWithRedis[jUtils.Claims]("", time.Minute*15, time.Hour*24*30, time.Second*30, os.Getenv("JWT_SECRET"), redisClient)
+	}
+
+	j, err := jwt.NewWithRedisKeys[jUtils.Claims]("", time.Minute*15, time.Hour*24*30, time.Second*30, algorithm, time.Hour*24*7, redisClient, encrypt)
+	if err != nil {
+		logrus.Fatalf("Can't create signing keys, error: %s", err.Error())
+	}
+
+	return j
+}
+
 func loadSwagger(e gin.RouterGroup, names ...string) {
 	for _, name := range names {
 		s := ginSwagger.WrapHandler(swaggerfiles.Handler, ginSwagger.InstanceName(name))
@@ -170,6 +198,22 @@ func redirectFunc() gin.HandlerFunc {
 	}
 }
 
+// newGRPC chains the logging, recovery and error interceptors before the auth ones and registers the public health and reflection services
+func newGRPC(authMiddleware authHandlers.Middleware) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(append(middlewares.GrpcUnaryInterceptors(), authMiddleware.GrpcInterceptor())...),
+		grpc.ChainStreamInterceptor(append(middlewares.GrpcStreamInterceptors(), authMiddleware.GrpcStreamInterceptor())...),
+	)
+
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+
+	authMiddleware.GrpcPublic("/grpc.health.v1.Health/*")
+	authMiddleware.GrpcPublic("/grpc.reflection.v1alpha.ServerReflection/*")
+
+	return server
+}
+
 func launchGRPC(server *grpc.Server) {
 	listener, err := net.Listen("tcp", ":"+os.Getenv("GRPC_PORT"))
 
@@ -2,12 +2,19 @@ package apps
 
 import (
 	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
 	"studyum/internal/apps/apps/kbp"
 	"studyum/internal/apps/controllers"
 	"studyum/internal/apps/entities"
+	"studyum/internal/apps/handlers"
+	"studyum/internal/apps/handlers/swagger"
 	"studyum/internal/apps/repositories"
 	"studyum/internal/apps/shared"
+	auth "studyum/internal/auth/handlers"
 	"studyum/pkg/encryption"
 )
 
@@ -17,7 +24,12 @@ func proceedApps() []entities.App {
 	}
 }
 
-func New(db *mongo.Database, encryption encryption.Encryption) controllers.Controller {
+// @BasePath /api/apps
+
+//go:generate swag init --instanceName apps -o handlers/swagger -g apps.go -ot go,yaml
+func New(core *gin.RouterGroup, grpcServer *grpc.Server, authMiddleware auth.Middleware, db *mongo.Database, redisClient *redis.Client, encryption encryption.Encryption) (controllers.Controller, controllers.Outbox) {
+	swagger.SwaggerInfoapps.BasePath = "/api/apps"
+
 	apps := proceedApps()
 
 	lessons := db.Collection("Lessons")
@@ -31,7 +43,18 @@ func New(db *mongo.Database, encryption encryption.Encryption) controllers.Contr
 
 	appsRepository := repositories.NewApps(apps)
 	dataRepository := repositories.NewData(db)
-	controller := controllers.NewController(appsRepository, dataRepository)
+	outboxRepository := repositories.NewOutbox(db.Client(), db.Collection("AppsOutbox"))
+	if err := outboxRepository.CheckTransactions(context.Background()); err != nil {
+		logrus.Fatalf("Can't write app events, error: %s", err.Error())
+	}
+	eventsRepository := repositories.NewEvents(redisClient, 10000)
+
+	events := controllers.NewEvents(eventsRepository)
+	controller := controllers.NewController(appsRepository, dataRepository, outboxRepository, events)
+	outbox := controllers.NewOutbox(outboxRepository, controller)
+
+	handlers.NewOutbox(authMiddleware, outbox, core.Group("/outbox"))
+	handlers.NewGrpc(authMiddleware, events, grpcServer)
 
-	return controller
+	return controller, outbox
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"reflect"
 	"strings"
 	"studyum/internal/apps/entities"
@@ -13,79 +14,185 @@ import (
 	"studyum/internal/apps/shared"
 )
 
+var ErrDeliveryFailed = errors.New("app returned no data")
+
 type Controller interface {
-	EventWithContext(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any)
-	AsyncEventWithContext(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any)
-	Event(studyPlaceID primitive.ObjectID, name string, data ...any)
-	AsyncEvent(studyPlaceID primitive.ObjectID, name string, data ...any)
+	Transaction(ctx context.Context, write func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+	Event(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any) error
+	Deliver(ctx context.Context, event entities.OutboxEvent) (shared.Data, error)
 }
 
 type controller struct {
-	apps repositories.Apps
-	data repositories.Data
+	apps   repositories.Apps
+	data   repositories.Data
+	outbox repositories.Outbox
+	events Events
+}
+
+// transactionKey keeps the transaction state, subscribers get its events and
+// afterCommit functions run only once the transaction is committed
+type transactionKey struct{}
+
+type transaction struct {
+	events      []entities.Event
+	afterCommit []func(ctx context.Context)
 }
 
-func NewController(apps repositories.Apps, data repositories.Data) Controller {
-	return &controller{apps: apps, data: data}
+func NewController(apps repositories.Apps, data repositories.Data, outbox repositories.Outbox, events Events) Controller {
+	return &controller{apps: apps, data: data, outbox: outbox, events: events}
 }
 
-func (c *controller) EventWithContext(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any) {
-	defer c.panicRecovery(logrus.Errorf)
+// Transaction runs write in a mongo transaction, so events recorded by Event with its context
+// are kept only if the change itself is written. Nested calls join the outer transaction
+func (c *controller) Transaction(ctx context.Context, write func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		return write(ctx)
+	}
 
-	app, err := c.apps.GetByStudyPlaceID(ctx, studyPlaceID)
+	var tx transaction
+	err := c.outbox.Transaction(ctx, func(ctx context.Context) error {
+		tx = transaction{}
+		return write(context.WithValue(ctx, transactionKey{}, &tx))
+	})
 	if err != nil {
-		return
+		return err
 	}
 
-	method, ok := c.findMethod(app, name)
-	if !ok {
-		return
+	for _, event := range tx.events {
+		c.events.Publish(ctx, event)
 	}
 
-	if method.Func.Type().NumIn()-3 != len(data) {
-		logrus.Errorf("No enough params. Passed %d, required %d\n", len(data), method.Func.Type().NumIn()-3)
+	for _, fn := range tx.afterCommit {
+		fn(ctx)
+	}
+
+	return nil
+}
+
+// AfterCommit runs fn once the transaction of ctx is committed or right away outside a transaction,
+// e.g. caches must not be invalidated before the change is visible to other readers
+func (c *controller) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if tx, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		tx.afterCommit = append(tx.afterCommit, fn)
 		return
 	}
 
-	values := c.toReflect(app, ctx, data)
-	trackable := c.getTrackable(values[2:])
+	fn(ctx)
+}
 
-	dataField, ok := c.findDataField(ctx, trackable)
+// Event records the event in the outbox if the study place has an app, the outbox worker delivers it later
+func (c *controller) Event(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any) error {
+	event, ok := newEvent(studyPlaceID, name, data)
 	if !ok {
-		return
+		logrus.Warningln("No payload for event " + name)
+		return nil
 	}
 
-	values = append(values, values[len(values)-1])
-	copy(values[2:], values[2:])
-	values[2] = dataField
+	if tx, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		tx.events = append(tx.events, event)
+	} else {
+		c.events.Publish(ctx, event)
+	}
 
-	if !c.checkMethodInput(method, values) {
-		return
+	if _, err := c.apps.GetByStudyPlaceID(ctx, studyPlaceID); err != nil {
+		return nil
 	}
 
-	go func() {
-		defer c.panicRecovery(logrus.Debugf)
+	values := make([]reflect.Value, len(data))
+	for i, el := range data {
+		values[i] = reflect.ValueOf(el)
+	}
+	trackable := c.getTrackable(values)
+
+	outboxEvent := entities.OutboxEvent{
+		ID:          primitive.NewObjectID(),
+		AggregateID: trackable.Value,
+		Event:       event,
+		Status:      entities.OutboxPending,
+		NextAttempt: event.Time,
+		CreatedAt:   event.Time,
+	}
+	if outboxEvent.AggregateID.IsZero() {
+		outboxEvent.AggregateID = outboxEvent.ID
+	}
 
-		result := method.Func.Call(values)
-		resultData, ok := c.getDataFromReturnValue(result)
-		if !ok {
-			return
+	// removed records can not be read when the event is delivered, so their app data is kept with the event
+	if strings.HasPrefix(name, "Remove") && trackable.Collection != "" {
+		appData, err := c.findData(ctx, trackable)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
 		}
 
-		c.insertData(ctx, trackable, resultData)
-	}()
-}
+		outboxEvent.Data = appData
+	}
 
-func (c *controller) AsyncEventWithContext(ctx context.Context, studyPlaceID primitive.ObjectID, name string, data ...any) {
-	go c.EventWithContext(ctx, studyPlaceID, name, data...)
+	return c.outbox.Add(ctx, outboxEvent)
 }
 
-func (c *controller) Event(studyPlaceID primitive.ObjectID, name string, data ...any) {
-	c.EventWithContext(context.Background(), studyPlaceID, name, data...)
+// Deliver calls the method of the app named after the event and saves the returned data to the record,
+// an error means the app has not got the event
+func (c *controller) Deliver(ctx context.Context, event entities.OutboxEvent) (result shared.Data, err error) {
+	defer c.panicRecovery(&err)
+
+	app, err := c.apps.GetByStudyPlaceID(ctx, event.Event.StudyPlaceID)
+	if err != nil {
+		return nil, nil
+	}
+
+	method, ok := c.findMethod(app, event.Event.Name)
+	if !ok {
+		return nil, nil
+	}
+
+	payload, ok := eventPayload(event.Event)
+	if !ok {
+		return nil, errors.New("no payload for event " + event.Event.Name)
+	}
+
+	values := c.toReflect(app, ctx, []any{payload})
+	trackable := c.getTrackable(values[2:])
+
+	appData := event.Data
+	if trackable.Collection != "" {
+		data, err := c.findData(ctx, trackable)
+		switch {
+		case err == nil:
+			appData = data
+		case !errors.Is(err, mongo.ErrNoDocuments):
+			return nil, err
+		}
+	}
+
+	values = append(values[:2], append([]reflect.Value{reflect.ValueOf(appData)}, values[2:]...)...)
+	if err = c.checkMethodInput(method, values); err != nil {
+		return nil, err
+	}
+
+	result, ok = c.getDataFromReturnValue(method.Func.Call(values))
+	if !ok {
+		return nil, nil
+	}
+
+	if result == nil {
+		return nil, ErrDeliveryFailed
+	}
+
+	c.insertData(ctx, trackable, result)
+	return result, nil
 }
 
-func (c *controller) AsyncEvent(studyPlaceID primitive.ObjectID, name string, data ...any) {
-	c.AsyncEventWithContext(context.Background(), studyPlaceID, name, data...)
+func eventPayload(event entities.Event) (any, bool) {
+	switch {
+	case event.Lesson != nil:
+		return *event.Lesson, true
+	case event.Mark != nil:
+		return *event.Mark, true
+	case event.Absence != nil:
+		return *event.Absence, true
+	default:
+		return nil, false
+	}
 }
 
 func (c *controller) toReflect(app entities.App, ctx context.Context, data []any) []reflect.Value {
@@ -203,9 +310,10 @@ func (c *controller) getTrackable(values []reflect.Value) entities.Trackable {
 	return trackable
 }
 
-func (c *controller) panicRecovery(logger func(str string, args ...any)) {
+func (c *controller) panicRecovery(err *error) {
 	if r := recover(); r != nil {
-		logger("Panic recieved: %s", r)
+		logrus.Errorf("Panic recieved: %s", r)
+		*err = errors.Errorf("panic: %v", r)
 	}
 }
 
@@ -231,46 +339,42 @@ func (c *controller) findMethod(app entities.App, name string) (reflect.Method,
 	return method, true
 }
 
-func (c *controller) findDataField(ctx context.Context, trackable entities.Trackable) (reflect.Value, bool) {
+func (c *controller) findData(ctx context.Context, trackable entities.Trackable) (shared.Data, error) {
 	var record map[string]any
 	var err error
 	switch trackable.Type {
 	case entities.Field:
 		record, err = c.data.Get(ctx, trackable.Collection, trackable.Property, trackable.Value)
-		if err != nil {
-			logrus.Errorln("Error getting data object: " + err.Error())
-			return reflect.Value{}, false
-		}
 	case entities.Array:
 		record, err = c.data.GetNested(ctx, trackable.Collection, trackable.Nested, trackable.Property, trackable.Value)
-		if err != nil {
-			logrus.Errorln("Error getting data object: " + err.Error())
-			return reflect.Value{}, false
-		}
 	default:
-		logrus.Errorln("No such trackable type")
-		return reflect.Value{}, false
+		return nil, errors.New("No such trackable type")
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	dataField := reflect.ValueOf(shared.Data{})
 	if dataMap, ok := record[trackable.DataProperty]; ok {
 		if data, ok := dataMap.(bson.M); ok {
-			dataField = reflect.ValueOf(shared.Data(data))
+			return shared.Data(data), nil
 		}
 	}
 
-	return dataField, true
+	return shared.Data{}, nil
 }
 
-func (c *controller) checkMethodInput(method reflect.Method, values []reflect.Value) bool {
+func (c *controller) checkMethodInput(method reflect.Method, values []reflect.Value) error {
+	if method.Func.Type().NumIn() != len(values) {
+		return errors.Errorf("No enough params. Passed %d, required %d", len(values), method.Func.Type().NumIn())
+	}
+
 	for index := 0; index < method.Func.Type().NumIn(); index++ {
 		if t := method.Func.Type().In(index); !values[index].Type().AssignableTo(t) {
-			logrus.Errorf("Cannot call method with param. Passed %s, required %s\n", values[index].Type().String(), t.String())
-			return false
+			return errors.Errorf("Cannot call method with param. Passed %s, required %s", values[index].Type().String(), t.String())
 		}
 	}
 
-	return true
+	return nil
 }
 
 func (c *controller) getDataFromReturnValue(result []reflect.Value) (data shared.Data, ok bool) {
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"strings"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/repositories"
+	auth "studyum/internal/auth/entities"
+	journal "studyum/internal/journal/entities"
+	schedule "studyum/internal/schedule/entities"
+	"time"
+)
+
+var (
+	ErrNotValidResumeToken = errors.New("not valid resume token")
+	ErrResumeTokenExpired  = errors.New("resume token expired")
+)
+
+// eventsBlock is how long a subscription waits for new events before checking if it is still alive
+const eventsBlock = time.Second * 5
+
+type Events interface {
+	Publish(ctx context.Context, event entities.Event)
+	Subscribe(ctx context.Context, studyPlaceID primitive.ObjectID, resumeToken string, send func(event entities.Event) error) error
+}
+
+type events struct {
+	repository repositories.Events
+}
+
+func NewEvents(repository repositories.Events) Events {
+	return &events{repository: repository}
+}
+
+func (e *events) Publish(ctx context.Context, event entities.Event) {
+	if _, err := e.repository.Add(ctx, event); err != nil {
+		logrus.Errorln("Error publishing event: " + err.Error())
+	}
+}
+
+// Subscribe sends events after the resume token, or only new ones if the token is empty, until ctx is done
+func (e *events) Subscribe(ctx context.Context, studyPlaceID primitive.ObjectID, resumeToken string, send func(event entities.Event) error) error {
+	after, err := e.start(ctx, studyPlaceID, resumeToken)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		batch, err := e.repository.Read(ctx, studyPlaceID, after, eventsBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		for _, event := range batch {
+			if err = send(event); err != nil {
+				return err
+			}
+
+			after = event.ID
+		}
+	}
+}
+
+// start returns the stream id to read after. Tokens older than the first kept event are rejected,
+// as the events in between could have been trimmed
+func (e *events) start(ctx context.Context, studyPlaceID primitive.ObjectID, resumeToken string) (string, error) {
+	if resumeToken == "" {
+		last, err := e.repository.Last(ctx, studyPlaceID)
+		if err != nil {
+			return "", err
+		}
+		if last == "" {
+			return "0-0", nil
+		}
+
+		return last, nil
+	}
+
+	token, ok := parseStreamID(resumeToken)
+	if !ok {
+		return "", ErrNotValidResumeToken
+	}
+
+	first, err := e.repository.First(ctx, studyPlaceID)
+	if err != nil {
+		return "", err
+	}
+
+	if firstID, ok := parseStreamID(first); ok && token.before(firstID) {
+		return "", ErrResumeTokenExpired
+	}
+
+	return resumeToken, nil
+}
+
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func (s streamID) before(id streamID) bool {
+	return s.ms < id.ms || s.ms == id.ms && s.seq < id.seq
+}
+
+func parseStreamID(id string) (streamID, bool) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return streamID{}, false
+	}
+
+	parsedMs, err := strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return streamID{}, false
+	}
+
+	parsedSeq, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return streamID{}, false
+	}
+
+	return streamID{ms: parsedMs, seq: parsedSeq}, true
+}
+
+// newEvent takes the lesson, mark or absence out of the app event data, deletions pass the whole removed record.
+// Marks and absences get the scope of their lesson passed as auth.Resource
+func newEvent(studyPlaceID primitive.ObjectID, name string, data []any) (entities.Event, bool) {
+	event := entities.Event{StudyPlaceID: studyPlaceID, Name: name, Time: time.Now()}
+
+	found := false
+	for _, value := range data {
+		if resource, ok := value.(auth.Resource); ok {
+			event.Teacher, event.Group = resource.Teacher, resource.Group
+			continue
+		}
+
+		if !found {
+			found = setEventPayload(&event, value)
+		}
+	}
+
+	if !found {
+		return entities.Event{}, false
+	}
+
+	return event, true
+}
+
+func setEventPayload(event *entities.Event, value any) bool {
+	switch value := value.(type) {
+	case schedule.Lesson:
+		event.Lesson = &value
+		event.Teacher, event.Group = value.Teacher, value.Group
+	case journal.Mark:
+		event.Mark = &value
+	case journal.Absence:
+		event.Absence = &value
+	default:
+		return false
+	}
+
+	return true
+}
+
+// VisibleEvent reports whether the user may view journals of the event lesson,
+// comments visible only for teachers are kept if the user may edit the journal too
+func VisibleEvent(user auth.User, event entities.Event) (entities.Event, bool) {
+	resource := auth.Resource{Teacher: event.Teacher, Group: event.Group}
+	if !user.Can(auth.PermissionViewJournals, resource) {
+		return entities.Event{}, false
+	}
+
+	if user.Can(auth.PermissionEditJournal, resource) {
+		return event, true
+	}
+
+	if event.Mark != nil && event.Mark.CommentVisibility == journal.TeacherVisibility {
+		mark := *event.Mark
+		mark.Comment = ""
+		mark.CommentVisibility = ""
+		event.Mark = &mark
+	}
+
+	if event.Absence != nil && event.Absence.CommentVisibility == journal.TeacherVisibility {
+		absence := *event.Absence
+		absence.Comment = ""
+		absence.CommentVisibility = ""
+		event.Absence = &absence
+	}
+
+	return event, true
+}
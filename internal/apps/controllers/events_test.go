@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/repositories"
+	auth "studyum/internal/auth/entities"
+	journal "studyum/internal/journal/entities"
+	schedule "studyum/internal/schedule/entities"
+	"testing"
+)
+
+func TestEventsResume(t *testing.T) {
+	server := miniredis.RunT(t)
+	repository := repositories.NewEvents(redis.NewClient(&redis.Options{Addr: server.Addr()}), 100)
+	events := NewEvents(repository)
+
+	ctx := context.Background()
+	studyPlaceID := primitive.NewObjectID()
+	mark := journal.Mark{ID: primitive.NewObjectID(), Mark: "5"}
+	absenceID := primitive.NewObjectID()
+
+	for _, event := range []struct {
+		name string
+		data any
+	}{
+		{name: "AddMark", data: mark},
+		{name: "UpdateMark", data: mark},
+		{name: "RemoveAbsence", data: journal.Absence{ID: absenceID}},
+	} {
+		e, ok := newEvent(studyPlaceID, event.name, []any{event.data})
+		assert.Equal(t, ok, true)
+
+		events.Publish(ctx, e)
+	}
+
+	_, ok := newEvent(studyPlaceID, "Unknown", []any{"no payload"})
+	assert.Equal(t, ok, false)
+
+	first, err := repository.First(ctx, studyPlaceID)
+	assert.Equal(t, err, nil)
+
+	// events after the token are replayed in order
+	done := errors.New("done")
+	var received []entities.Event
+	err = events.Subscribe(ctx, studyPlaceID, first, func(event entities.Event) error {
+		received = append(received, event)
+		if len(received) == 2 {
+			return done
+		}
+		return nil
+	})
+	assert.Equal(t, errors.Is(err, done), true)
+	assert.Equal(t, received[0].Name, "UpdateMark")
+	assert.Equal(t, received[0].Mark.Mark, "5")
+	assert.Equal(t, received[1].Name, "RemoveAbsence")
+	assert.Equal(t, received[1].Absence.ID, absenceID)
+	assert.NotEqual(t, received[0].ID, received[1].ID)
+
+	err = events.Subscribe(ctx, studyPlaceID, "1-0", nil)
+	assert.Equal(t, errors.Is(err, ErrResumeTokenExpired), true)
+
+	err = events.Subscribe(ctx, studyPlaceID, "token", nil)
+	assert.Equal(t, errors.Is(err, ErrNotValidResumeToken), true)
+
+	// subscriptions end with the context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = events.Subscribe(cancelled, studyPlaceID, "", nil)
+	assert.Equal(t, errors.Is(err, context.Canceled), true)
+}
+
+func TestVisibleEvent(t *testing.T) {
+	studyPlaceID := primitive.NewObjectID()
+	mark := journal.Mark{ID: primitive.NewObjectID(), Mark: "5", Comment: "late", CommentVisibility: journal.TeacherVisibility}
+
+	event, ok := newEvent(studyPlaceID, "AddMark", []any{mark, auth.Resource{Teacher: "Teacher", Group: "Group"}})
+	assert.Equal(t, ok, true)
+	assert.Equal(t, event.Teacher, "Teacher")
+	assert.Equal(t, event.Group, "Group")
+
+	teacher := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{Permissions: []string{
+		auth.PermissionViewJournals,
+		auth.PermissionEditJournal + ":teacher=Teacher",
+	}}}
+	visible, ok := VisibleEvent(teacher, event)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, visible.Mark.Comment, "late")
+
+	group := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{Permissions: []string{auth.PermissionViewJournals + ":group=Group"}}}
+	visible, ok = VisibleEvent(group, event)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, visible.Mark.Comment, "")
+	assert.Equal(t, visible.Mark.CommentVisibility, journal.CommentVisibility(""))
+	assert.Equal(t, event.Mark.Comment, "late")
+
+	other := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{Permissions: []string{auth.PermissionViewJournals + ":group=Other"}}}
+	_, ok = VisibleEvent(other, event)
+	assert.Equal(t, ok, false)
+
+	// removed lessons keep the scope of the lesson
+	removed, ok := newEvent(studyPlaceID, "RemoveLesson", []any{schedule.Lesson{Id: primitive.NewObjectID(), Teacher: "Teacher", Group: "Group"}})
+	assert.Equal(t, ok, true)
+	_, ok = VisibleEvent(group, removed)
+	assert.Equal(t, ok, true)
+	_, ok = VisibleEvent(other, removed)
+	assert.Equal(t, ok, false)
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/repositories"
+	"studyum/internal/apps/shared"
+	journal "studyum/internal/journal/entities"
+)
+
+// Fakes shared by the controller tests keep data in memory and implement only the methods the tests call
+
+type testApp struct {
+	studyPlaceID primitive.ObjectID
+	down         bool
+	removed      shared.Data
+}
+
+func (a *testApp) Init(shared.Shared) {}
+
+func (a *testApp) GetStudyPlaceID(context.Context) primitive.ObjectID {
+	return a.studyPlaceID
+}
+
+func (a *testApp) AddMark(_ context.Context, _ shared.Data, mark journal.Mark) shared.Data {
+	if a.down {
+		return nil
+	}
+	return shared.Data{"markID": mark.Mark}
+}
+
+func (a *testApp) UpdateMark(_ context.Context, data shared.Data, _ journal.Mark) shared.Data {
+	return data
+}
+
+func (a *testApp) RemoveMark(_ context.Context, data shared.Data, _ journal.Mark) {
+	a.removed = data
+}
+
+// testData has no records, as if every mark was deleted
+type testData struct {
+	repositories.Data
+}
+
+func (d testData) GetNested(context.Context, string, string, string, primitive.ObjectID) (bson.M, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+func (d testData) InsertNested(context.Context, string, string, string, primitive.ObjectID, string, shared.Data) error {
+	return nil
+}
+
+type testOutbox struct {
+	repositories.Outbox
+
+	transactions int
+}
+
+func (o *testOutbox) Transaction(ctx context.Context, write func(ctx context.Context) error) error {
+	o.transactions++
+	return write(ctx)
+}
+
+type testEvents struct {
+	Events
+
+	published []entities.Event
+}
+
+func (e *testEvents) Publish(_ context.Context, event entities.Event) {
+	e.published = append(e.published, event)
+}
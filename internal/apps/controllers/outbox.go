@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/repositories"
+	auth "studyum/internal/auth/entities"
+	"time"
+)
+
+var NotValidParams = errors.New("not valid params")
+
+const (
+	outboxBatch       = 100
+	outboxLease       = time.Minute * 5
+	outboxMaxAttempts = 10
+	outboxBackoff     = time.Second * 5
+	outboxMaxBackoff  = time.Hour
+)
+
+type Outbox interface {
+	Run(ctx context.Context, interval time.Duration)
+	Process(ctx context.Context, now time.Time) error
+
+	GetDeadLetters(ctx context.Context, user auth.User) ([]entities.OutboxEvent, error)
+	Replay(ctx context.Context, user auth.User, idHex string) error
+	Discard(ctx context.Context, user auth.User, idHex string) error
+}
+
+type outbox struct {
+	repository repositories.Outbox
+	controller Controller
+}
+
+func NewOutbox(repository repositories.Outbox, controller Controller) Outbox {
+	return &outbox{repository: repository, controller: controller}
+}
+
+func (o *outbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := o.Process(ctx, now); err != nil {
+				logrus.Warningln("Error processing apps outbox: " + err.Error())
+			}
+		}
+	}
+}
+
+// Process delivers the first due event of every aggregate, failed events are retried with exponential backoff
+// and moved to the dead letters after outboxMaxAttempts. Events are claimed first, so instances do not deliver them twice
+func (o *outbox) Process(ctx context.Context, now time.Time) error {
+	due, err := o.repository.GetDue(ctx, now, outboxBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range due {
+		event, err = o.repository.Claim(ctx, event.ID, now, now.Add(outboxLease))
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue
+			}
+			return err
+		}
+
+		data, err := o.controller.Deliver(ctx, event)
+		if err == nil {
+			if data != nil {
+				if err = o.repository.SetNextData(ctx, event, data); err != nil {
+					return err
+				}
+			}
+
+			if err = o.repository.Delete(ctx, event.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		attempts := event.Attempts + 1
+		if attempts >= outboxMaxAttempts {
+			logrus.Errorf("Event %s %s is dead after %d attempts: %s", event.Event.Name, event.ID.Hex(), attempts, err.Error())
+			if err = o.repository.Kill(ctx, event.ID, attempts, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = o.repository.Retry(ctx, event.ID, attempts, now.Add(outboxDelay(attempts)), err.Error()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func outboxDelay(attempts int) time.Duration {
+	delay := outboxBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+
+	return delay
+}
+
+func (o *outbox) GetDeadLetters(ctx context.Context, user auth.User) ([]entities.OutboxEvent, error) {
+	return o.repository.GetDead(ctx, user.StudyPlaceInfo.ID)
+}
+
+// Replay makes the dead event pending again, the following events of its aggregate are delivered after it
+func (o *outbox) Replay(ctx context.Context, user auth.User, idHex string) error {
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return errors.Wrap(NotValidParams, "id")
+	}
+
+	return o.repository.Replay(ctx, user.StudyPlaceInfo.ID, id, time.Now())
+}
+
+// Discard deletes the dead event, so the following events of its aggregate are delivered without it
+func (o *outbox) Discard(ctx context.Context, user auth.User, idHex string) error {
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return errors.Wrap(NotValidParams, "id")
+	}
+
+	return o.repository.Discard(ctx, user.StudyPlaceInfo.ID, id)
+}
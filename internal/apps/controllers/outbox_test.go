@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/repositories"
+	"studyum/internal/apps/shared"
+	journal "studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestDeliver(t *testing.T) {
+	app := &testApp{studyPlaceID: primitive.NewObjectID(), down: true}
+	c := &controller{apps: repositories.NewApps([]entities.App{app}), data: testData{}}
+	ctx := context.Background()
+
+	mark := journal.Mark{ID: primitive.NewObjectID(), Mark: "5"}
+	event := entities.OutboxEvent{Event: entities.Event{StudyPlaceID: app.studyPlaceID, Name: "AddMark", Mark: &mark}}
+
+	_, err := c.Deliver(ctx, event)
+	assert.Equal(t, errors.Is(err, ErrDeliveryFailed), true)
+
+	app.down = false
+	data, err := c.Deliver(ctx, event)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, data, shared.Data{"markID": "5"})
+
+	// the app data kept with the event is used once the record is deleted
+	event.Event.Name = "RemoveMark"
+	event.Data = data
+	data, err = c.Deliver(ctx, event)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, data, shared.Data(nil))
+	assert.Equal(t, app.removed, shared.Data{"markID": "5"})
+
+	// apps which do not handle the event just skip it
+	event.Event.Name = "AddAbsence"
+	_, err = c.Deliver(ctx, event)
+	assert.Equal(t, err, nil)
+}
+
+func TestOutboxDelay(t *testing.T) {
+	assert.Equal(t, outboxDelay(1), time.Second*5)
+	assert.Equal(t, outboxDelay(2), time.Second*10)
+	assert.Equal(t, outboxDelay(4), time.Second*40)
+	assert.Equal(t, outboxDelay(outboxMaxAttempts*2), time.Hour)
+}
+
+func TestNestedTransaction(t *testing.T) {
+	outbox, events := &testOutbox{}, &testEvents{}
+	c := &controller{apps: repositories.NewApps(nil), outbox: outbox, events: events}
+	studyPlaceID := primitive.NewObjectID()
+
+	err := c.Transaction(context.Background(), func(ctx context.Context) error {
+		for _, mark := range []string{"5", "4"} {
+			err := c.Transaction(ctx, func(ctx context.Context) error {
+				return c.Event(ctx, studyPlaceID, "AddMark", journal.Mark{Mark: mark})
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		assert.Equal(t, len(events.published), 0)
+		return nil
+	})
+	assert.Equal(t, err, nil)
+
+	// inner transactions join the outer one, events are published once it is committed
+	assert.Equal(t, outbox.transactions, 1)
+	assert.Equal(t, len(events.published), 2)
+
+	err = c.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := c.Event(ctx, studyPlaceID, "AddMark", journal.Mark{Mark: "3"}); err != nil {
+			return err
+		}
+		return errors.New("write failed")
+	})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, len(events.published), 2)
+}
+
+func TestAfterCommit(t *testing.T) {
+	c := &controller{apps: repositories.NewApps(nil), outbox: &testOutbox{}, events: &testEvents{}}
+
+	var committed []string
+	err := c.Transaction(context.Background(), func(ctx context.Context) error {
+		err := c.Transaction(ctx, func(ctx context.Context) error {
+			c.AfterCommit(ctx, func(context.Context) { committed = append(committed, "inner") })
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		c.AfterCommit(ctx, func(context.Context) { committed = append(committed, "outer") })
+		assert.Equal(t, len(committed), 0)
+		return nil
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, committed, []string{"inner", "outer"})
+
+	// functions of a failed transaction never run
+	err = c.Transaction(context.Background(), func(ctx context.Context) error {
+		c.AfterCommit(ctx, func(context.Context) { committed = append(committed, "failed") })
+		return errors.New("write failed")
+	})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, len(committed), 2)
+
+	c.AfterCommit(context.Background(), func(context.Context) { committed = append(committed, "now") })
+	assert.Equal(t, committed[2], "now")
+}
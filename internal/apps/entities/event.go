@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	journal "studyum/internal/journal/entities"
+	schedule "studyum/internal/schedule/entities"
+	"time"
+)
+
+// Event is a change passed to apps, ID is the resume token of the event in its study place stream.
+// Teacher and Group are taken from the lesson of the change to check the scope of its subscribers
+type Event struct {
+	ID           string             `json:"-" bson:"-"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	Name         string             `json:"name" bson:"name"`
+	Time         time.Time          `json:"time" bson:"time"`
+	Teacher      string             `json:"teacher,omitempty" bson:"teacher,omitempty"`
+	Group        string             `json:"group,omitempty" bson:"group,omitempty"`
+	Lesson       *schedule.Lesson   `json:"lesson,omitempty" bson:"lesson,omitempty"`
+	Mark         *journal.Mark      `json:"mark,omitempty" bson:"mark,omitempty"`
+	Absence      *journal.Absence   `json:"absence,omitempty" bson:"absence,omitempty"`
+}
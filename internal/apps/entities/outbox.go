@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/apps/shared"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending    OutboxStatus = "pending"
+	OutboxProcessing OutboxStatus = "processing"
+	OutboxDead       OutboxStatus = "dead"
+)
+
+// OutboxEvent is an event waiting to be delivered to the app of its study place.
+// Events of an aggregate, e.g. a mark, are delivered one by one in the order they were written,
+// a dead event stops the following ones until it is replayed or discarded.
+// Workers claim events as processing until LockedUntil, so an event is delivered by one instance at a time
+type OutboxEvent struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	AggregateID primitive.ObjectID `json:"aggregateID" bson:"aggregateID"`
+	Event       Event              `json:"event" bson:"event"`
+	Data        shared.Data        `json:"data,omitempty" bson:"data,omitempty"`
+	Status      OutboxStatus       `json:"status" bson:"status"`
+	Attempts    int                `json:"attempts" bson:"attempts"`
+	NextAttempt time.Time          `json:"nextAttempt" bson:"nextAttempt"`
+	LockedUntil time.Time          `json:"lockedUntil,omitempty" bson:"lockedUntil,omitempty"`
+	LastError   string             `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"studyum/grpc/events/protoevents"
+	"studyum/internal/apps/controllers"
+	"studyum/internal/apps/entities"
+	authEntities "studyum/internal/auth/entities"
+	auth "studyum/internal/auth/handlers"
+	journal "studyum/internal/journal/entities"
+	schedule "studyum/internal/schedule/entities"
+)
+
+// Grpc streams events of the study place of the api token to applications
+type Grpc struct {
+	auth.Middleware
+
+	events controllers.Events
+}
+
+func NewGrpc(middleware auth.Middleware, events controllers.Events, grpcServer *grpc.Server) *Grpc {
+	h := &Grpc{Middleware: middleware, events: events}
+
+	protoevents.RegisterEventsServer(grpcServer, h)
+
+	h.GrpcApiTokenAuth("/Events/Subscribe", authEntities.PermissionViewJournals)
+
+	return h
+}
+
+func (h *Grpc) Subscribe(request *protoevents.SubscribeRequest, stream protoevents.Events_SubscribeServer) error {
+	user := h.GetGrpcUser(stream.Context())
+
+	err := h.events.Subscribe(stream.Context(), user.StudyPlaceInfo.ID, request.ResumeToken, func(event entities.Event) error {
+		event, ok := controllers.VisibleEvent(user, event)
+		if !ok {
+			return nil
+		}
+
+		return stream.Send(eventToProto(event))
+	})
+	switch {
+	case errors.Is(err, controllers.ErrNotValidResumeToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, controllers.ErrResumeTokenExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+
+	return err
+}
+
+func eventToProto(event entities.Event) *protoevents.Event {
+	response := &protoevents.Event{
+		ResumeToken:  event.ID,
+		StudyPlaceID: event.StudyPlaceID.Hex(),
+		Name:         event.Name,
+		Time:         timestamppb.New(event.Time),
+	}
+
+	switch {
+	case event.Lesson != nil:
+		response.Payload = &protoevents.Event_Lesson{Lesson: lessonToProto(*event.Lesson)}
+	case event.Mark != nil:
+		response.Payload = &protoevents.Event_Mark{Mark: markToProto(*event.Mark)}
+	case event.Absence != nil:
+		response.Payload = &protoevents.Event_Absence{Absence: absenceToProto(*event.Absence)}
+	}
+
+	return response
+}
+
+func lessonToProto(lesson schedule.Lesson) *protoevents.EventLesson {
+	return &protoevents.EventLesson{
+		Id:          lesson.Id.Hex(),
+		Version:     int32(lesson.Version),
+		Type:        lesson.Type,
+		StartDate:   timestamppb.New(lesson.StartDate),
+		EndDate:     timestamppb.New(lesson.EndDate),
+		LessonIndex: int32(lesson.LessonIndex),
+		Subject:     lesson.Subject,
+		Group:       lesson.Group,
+		Teacher:     lesson.Teacher,
+		Room:        lesson.Room,
+		Title:       lesson.Title,
+		Homework:    lesson.Homework,
+		Description: lesson.Description,
+	}
+}
+
+func markToProto(mark journal.Mark) *protoevents.EventMark {
+	return &protoevents.EventMark{
+		Id:                mark.ID.Hex(),
+		Mark:              mark.Mark,
+		Comment:           mark.Comment,
+		CommentVisibility: string(mark.CommentVisibility),
+		Version:           int32(mark.Version),
+		StudentID:         mark.StudentID.Hex(),
+		LessonID:          mark.LessonID.Hex(),
+	}
+}
+
+func absenceToProto(absence journal.Absence) *protoevents.EventAbsence {
+	response := &protoevents.EventAbsence{
+		Id:                absence.ID.Hex(),
+		Comment:           absence.Comment,
+		CommentVisibility: string(absence.CommentVisibility),
+		Version:           int32(absence.Version),
+		StudentID:         absence.StudentID.Hex(),
+		LessonID:          absence.LessonID.Hex(),
+	}
+
+	if absence.Time != nil {
+		t := int32(*absence.Time)
+		response.Time = &t
+	}
+
+	return response
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/apps/controllers"
+	"studyum/internal/auth/entities"
+	auth "studyum/internal/auth/handlers"
+)
+
+type Outbox struct {
+	auth.Middleware
+
+	controller controllers.Outbox
+
+	Group *gin.RouterGroup
+}
+
+func NewOutbox(middleware auth.Middleware, controller controllers.Outbox, group *gin.RouterGroup) *Outbox {
+	h := &Outbox{Middleware: middleware, controller: controller, Group: group}
+
+	dead := group.Group("/dead", h.MemberAuth(entities.PermissionManageApps), h.NotImpersonated())
+	{
+		dead.GET("", h.GetDeadLetters)
+		dead.POST("/:id", h.Replay)
+		dead.DELETE("/:id", h.Discard)
+	}
+
+	return h
+}
+
+// GetDeadLetters godoc
+// @Router /outbox/dead [get]
+func (h *Outbox) GetDeadLetters(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	events, err := h.controller.GetDeadLetters(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// Replay godoc
+// @Param id path string true "Event ID"
+// @Router /outbox/dead/{id} [post]
+func (h *Outbox) Replay(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.Replay(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Discard godoc
+// @Param id path string true "Event ID"
+// @Router /outbox/dead/{id} [delete]
+func (h *Outbox) Discard(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.Discard(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,66 @@
+// Code generated by swaggo/swag. DO NOT EDIT
+package swagger
+
+import "github.com/swaggo/swag"
+
+const docTemplateapps = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/outbox/dead": {
+            "get": {
+                "responses": {}
+            }
+        },
+        "/outbox/dead/{id}": {
+            "post": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        }
+    }
+}`
+
+// SwaggerInfoapps holds exported Swagger Info so clients can modify it
+var SwaggerInfoapps = &swag.Spec{
+	Version:          "",
+	Host:             "",
+	BasePath:         "/api/apps",
+	Schemes:          []string{},
+	Title:            "",
+	Description:      "",
+	InfoInstanceName: "apps",
+	SwaggerTemplate:  docTemplateapps,
+}
+
+func init() {
+	swag.Register(SwaggerInfoapps.InstanceName(), SwaggerInfoapps)
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	r "github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/apps/entities"
+	"time"
+)
+
+// Events keeps events of every study place in a redis stream, stream entry ids are used as resume tokens
+type Events interface {
+	Add(ctx context.Context, event entities.Event) (string, error)
+	Read(ctx context.Context, studyPlaceID primitive.ObjectID, after string, block time.Duration) ([]entities.Event, error)
+	First(ctx context.Context, studyPlaceID primitive.ObjectID) (string, error)
+	Last(ctx context.Context, studyPlaceID primitive.ObjectID) (string, error)
+}
+
+type events struct {
+	client    *r.Client
+	retention int64
+}
+
+func NewEvents(client *r.Client, retention int64) Events {
+	return &events{client: client, retention: retention}
+}
+
+func (e *events) key(studyPlaceID primitive.ObjectID) string {
+	return "events:" + studyPlaceID.Hex()
+}
+
+func (e *events) Add(ctx context.Context, event entities.Event) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	return e.client.XAdd(ctx, &r.XAddArgs{
+		Stream: e.key(event.StudyPlaceID),
+		MaxLen: e.retention,
+		Approx: true,
+		Values: map[string]any{"event": data},
+	}).Result()
+}
+
+func (e *events) Read(ctx context.Context, studyPlaceID primitive.ObjectID, after string, block time.Duration) ([]entities.Event, error) {
+	streams, err := e.client.XRead(ctx, &r.XReadArgs{
+		Streams: []string{e.key(studyPlaceID), after},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, r.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var events []entities.Event
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			data, _ := message.Values["event"].(string)
+
+			var event entities.Event
+			if err = json.Unmarshal([]byte(data), &event); err != nil {
+				return nil, err
+			}
+
+			event.ID = message.ID
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (e *events) First(ctx context.Context, studyPlaceID primitive.ObjectID) (string, error) {
+	messages, err := e.client.XRangeN(ctx, e.key(studyPlaceID), "-", "+", 1).Result()
+	if err != nil || len(messages) == 0 {
+		return "", err
+	}
+
+	return messages[0].ID, nil
+}
+
+func (e *events) Last(ctx context.Context, studyPlaceID primitive.ObjectID) (string, error) {
+	messages, err := e.client.XRevRangeN(ctx, e.key(studyPlaceID), "+", "-", 1).Result()
+	if err != nil || len(messages) == 0 {
+		return "", err
+	}
+
+	return messages[0].ID, nil
+}
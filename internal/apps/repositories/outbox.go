@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/apps/entities"
+	"studyum/internal/apps/shared"
+	"time"
+)
+
+var ErrTransactionsNotSupported = errors.New("transactions need a mongo replica set or a sharded cluster")
+
+type Outbox interface {
+	CheckTransactions(ctx context.Context) error
+	Transaction(ctx context.Context, write func(ctx context.Context) error) error
+
+	Add(ctx context.Context, event entities.OutboxEvent) error
+	GetDue(ctx context.Context, now time.Time, limit int64) ([]entities.OutboxEvent, error)
+	Claim(ctx context.Context, id primitive.ObjectID, now time.Time, lockedUntil time.Time) (entities.OutboxEvent, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	Retry(ctx context.Context, id primitive.ObjectID, attempts int, nextAttempt time.Time, lastError string) error
+	Kill(ctx context.Context, id primitive.ObjectID, attempts int, lastError string) error
+	SetNextData(ctx context.Context, event entities.OutboxEvent, data shared.Data) error
+
+	GetDead(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.OutboxEvent, error)
+	Replay(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID, now time.Time) error
+	Discard(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error
+}
+
+type outbox struct {
+	client *mongo.Client
+	outbox *mongo.Collection
+}
+
+func NewOutbox(client *mongo.Client, outboxCollection *mongo.Collection) Outbox {
+	r := &outbox{client: client, outbox: outboxCollection}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *outbox) createIndexes(ctx context.Context) {
+	_, err := r.outbox.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "aggregateID", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "event.studyPlaceID", Value: 1}, {Key: "status", Value: 1}}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating outbox indexes: " + err.Error())
+	}
+}
+
+// CheckTransactions fails on standalone servers, which can not run Transaction
+func (r *outbox) CheckTransactions(ctx context.Context) error {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := r.client.Database("admin").RunCommand(ctx, bson.M{"isMaster": 1}).Decode(&hello); err != nil {
+		return err
+	}
+
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return ErrTransactionsNotSupported
+	}
+
+	return nil
+}
+
+// Transaction runs write in a transaction, repositories join it by using the passed context
+func (r *outbox) Transaction(ctx context.Context, write func(ctx context.Context) error) error {
+	return r.client.UseSession(ctx, func(sessionCtx mongo.SessionContext) error {
+		_, err := sessionCtx.WithTransaction(sessionCtx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+			return nil, write(sessionCtx)
+		})
+		return err
+	})
+}
+
+func (r *outbox) Add(ctx context.Context, event entities.OutboxEvent) error {
+	_, err := r.outbox.InsertOne(ctx, event)
+	return err
+}
+
+func (r *outbox) claimable(now time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"status": entities.OutboxPending, "nextAttempt": bson.M{"$lte": now}},
+		bson.M{"status": entities.OutboxProcessing, "lockedUntil": bson.M{"$lte": now}},
+	}}
+}
+
+// GetDue returns the first event of every aggregate if its attempt is due or the worker which claimed it is gone
+func (r *outbox) GetDue(ctx context.Context, now time.Time, limit int64) ([]entities.OutboxEvent, error) {
+	cursor, err := r.outbox.Aggregate(ctx, bson.A{
+		bson.M{"$sort": bson.M{"_id": 1}},
+		bson.M{"$group": bson.M{"_id": "$aggregateID", "event": bson.M{"$first": "$$ROOT"}}},
+		bson.M{"$replaceRoot": bson.M{"newRoot": "$event"}},
+		bson.M{"$match": r.claimable(now)},
+		bson.M{"$sort": bson.M{"_id": 1}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []entities.OutboxEvent
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Claim marks the event as processing until lockedUntil, it returns mongo.ErrNoDocuments if another worker has claimed it
+func (r *outbox) Claim(ctx context.Context, id primitive.ObjectID, now time.Time, lockedUntil time.Time) (event entities.OutboxEvent, err error) {
+	filter := r.claimable(now)
+	filter["_id"] = id
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.outbox.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{
+		"status":      entities.OutboxProcessing,
+		"lockedUntil": lockedUntil,
+	}}, opts).Decode(&event)
+	return
+}
+
+func (r *outbox) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.outbox.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *outbox) Retry(ctx context.Context, id primitive.ObjectID, attempts int, nextAttempt time.Time, lastError string) error {
+	_, err := r.outbox.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":      entities.OutboxPending,
+		"attempts":    attempts,
+		"nextAttempt": nextAttempt,
+		"lastError":   lastError,
+	}})
+	return err
+}
+
+func (r *outbox) Kill(ctx context.Context, id primitive.ObjectID, attempts int, lastError string) error {
+	_, err := r.outbox.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":    entities.OutboxDead,
+		"attempts":  attempts,
+		"lastError": lastError,
+	}})
+	return err
+}
+
+// SetNextData passes the data returned by the app for the event to the following events of the aggregate
+func (r *outbox) SetNextData(ctx context.Context, event entities.OutboxEvent, data shared.Data) error {
+	_, err := r.outbox.UpdateMany(ctx, bson.M{"aggregateID": event.AggregateID, "_id": bson.M{"$gt": event.ID}}, bson.M{"$set": bson.M{"data": data}})
+	return err
+}
+
+func (r *outbox) GetDead(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.OutboxEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.outbox.Find(ctx, bson.M{"event.studyPlaceID": studyPlaceID, "status": entities.OutboxDead}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]entities.OutboxEvent, 0)
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *outbox) Replay(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID, now time.Time) error {
+	result, err := r.outbox.UpdateOne(ctx, bson.M{"_id": id, "event.studyPlaceID": studyPlaceID, "status": entities.OutboxDead}, bson.M{"$set": bson.M{
+		"status":      entities.OutboxPending,
+		"attempts":    0,
+		"nextAttempt": now,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *outbox) Discard(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error {
+	result, err := r.outbox.DeleteOne(ctx, bson.M{"_id": id, "event.studyPlaceID": studyPlaceID, "status": entities.OutboxDead})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -2,39 +2,82 @@ package auth
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
-	"google.golang.org/grpc"
 	"studyum/internal/auth/controllers"
 	"studyum/internal/auth/handlers"
 	"studyum/internal/auth/handlers/swagger"
 	"studyum/internal/auth/repositories"
 	codes "studyum/internal/codes/controllers"
 	"studyum/internal/utils/jwt"
+	"studyum/internal/utils/middlewares"
 	"studyum/pkg/encryption"
+	"studyum/pkg/ratelimit"
+	"studyum/pkg/webauthn"
+	"time"
 )
 
 // @BasePath /api/user
 
 //go:generate swag init --instanceName auth -o handlers/swagger -g auth.go -ot go,yaml
-func New(core *gin.RouterGroup, grpcServer *grpc.Server, codes codes.Controller, encryption encryption.Encryption, jwtController jwt.JWT, db *mongo.Database) (handlers.Middleware, *handlers.Auth, *handlers.OAuth2) {
+func New(core *gin.RouterGroup, codes codes.Controller, encryption encryption.Encryption, jwtController jwt.JWT, db *mongo.Database, redisClient *redis.Client, oidcOptions controllers.OIDCOptions, webauthnConfig webauthn.Config) (handlers.Middleware, *handlers.Auth, *handlers.OAuth2, *handlers.OIDC) {
 	swagger.SwaggerInfoauth.BasePath = "/api/user"
 
 	usersCollection := db.Collection("Users")
 	codesCollection := db.Collection("SignUpCodes")
 	oauth2Collection := db.Collection("OAuth2Services")
+	oauth2LinksCollection := db.Collection("OAuth2Links")
+	challengesCollection := db.Collection("TwoFactorChallenges")
 	studyPlacesCollection := db.Collection("StudyPlaces")
+	oidcClientsCollection := db.Collection("OIDCClients")
+	oidcConsentsCollection := db.Collection("OIDCConsents")
+	oidcCodesCollection := db.Collection("OIDCCodes")
+	oidcTokensCollection := db.Collection("OIDCTokens")
+	apiTokensCollection := db.Collection("APITokens")
+	rolesCollection := db.Collection("Roles")
+	impersonationsCollection := db.Collection("Impersonations")
+	passkeyChallengesCollection := db.Collection("PasskeyChallenges")
 
 	authRepository := repositories.NewAuth(usersCollection)
 	codesRepository := repositories.NewCode(codesCollection)
 	middlewareRepository := repositories.NewMiddleware(usersCollection, studyPlacesCollection)
-	oauth2Repository := repositories.NewOAuth2(oauth2Collection, usersCollection)
+	oauth2Repository := repositories.NewOAuth2(oauth2Collection, usersCollection, oauth2LinksCollection)
+	twoFactorRepository := repositories.NewTwoFactor(usersCollection, challengesCollection)
+	apiTokenRepository := repositories.NewAPIToken(apiTokensCollection)
+	roleRepository := repositories.NewRole(usersCollection, rolesCollection)
+	impersonationRepository := repositories.NewImpersonation(usersCollection, impersonationsCollection)
+	webAuthnRepository := repositories.NewWebAuthn(usersCollection, passkeyChallengesCollection)
+	oidcRepository := repositories.NewOIDC(usersCollection, oidcClientsCollection, oidcConsentsCollection, oidcCodesCollection, oidcTokensCollection)
 
-	authController := controllers.NewAuth(jwtController, codes, encryption, authRepository, codesRepository)
-	middlewareController := controllers.NewMiddleware(jwtController, middlewareRepository)
+	accountLimiter := ratelimit.NewRedis(redisClient, "login", ratelimit.Options{Attempts: 5, Window: time.Minute * 15, Lockout: time.Minute, MaxLockout: time.Hour})
+	ipLimiter := ratelimit.NewRedis(redisClient, "auth-ip", ratelimit.Options{Attempts: 20, Window: time.Minute * 15, Lockout: time.Minute, MaxLockout: time.Hour})
+	limit := middlewares.RateLimitMiddleware(ipLimiter, true)
+
+	authController := controllers.NewAuth(jwtController, codes, encryption, accountLimiter, authRepository, codesRepository)
+	middlewareController := controllers.NewMiddleware(jwtController, middlewareRepository, apiTokenRepository, roleRepository)
 	oauth2Controller := controllers.NewOAuth2(oauth2Repository, encryption, jwtController)
+	twoFactorController := controllers.NewTwoFactor(jwtController, encryption, twoFactorRepository)
+	oidcController := controllers.NewOIDC(oidcOptions, jwtController, encryption, oidcRepository)
+	apiTokenController := controllers.NewAPIToken(apiTokenRepository)
+	roleController := controllers.NewRole(roleRepository)
+	impersonationController := controllers.NewImpersonation(jwtController, impersonationRepository, roleRepository)
+	webAuthnController := controllers.NewWebAuthn(webauthnConfig, jwtController, encryption, webAuthnRepository)
 
 	authMiddleware := handlers.NewMiddleware(middlewareController)
-	authHandler := handlers.NewAuth(authMiddleware, authController, core, grpcServer)
-	oauthHandler := handlers.NewOAuth2(authMiddleware, oauth2Controller, core.Group("/oauth2"))
-	return authMiddleware, authHandler, oauthHandler
+	authHandler := handlers.NewAuth(authMiddleware, authController, twoFactorController, limit, core)
+	oauthHandler := handlers.NewOAuth2(authMiddleware, oauth2Controller, twoFactorController, core.Group("/oauth2"))
+	handlers.NewTwoFactor(authMiddleware, twoFactorController, limit, core.Group("/2fa"))
+	// id tokens signed with JWT_SECRET could not be verified by clients without the secret which signs access tokens
+	var oidcHandler *handlers.OIDC
+	if len(jwtController.JWKS().Keys) != 0 {
+		oidcHandler = handlers.NewOIDC(authMiddleware, oidcController, core.Group("/oidc"))
+	} else {
+		logrus.Warningln("OpenID Connect is disabled, set JWT_ALGORITHM to RS256 or EdDSA to enable it")
+	}
+	handlers.NewAPIToken(authMiddleware, apiTokenController, core.Group("/api-tokens"))
+	handlers.NewRole(authMiddleware, roleController, core.Group("/roles"))
+	handlers.NewImpersonation(authMiddleware, impersonationController, core.Group("/impersonation"))
+	handlers.NewWebAuthn(authMiddleware, webAuthnController, limit, core.Group("/webauthn"))
+	return authMiddleware, authHandler, oauthHandler, oidcHandler
 }
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"net"
+	"strings"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	"studyum/pkg/hash"
+	"time"
+)
+
+var ErrNotValidAPIToken = errors.New("not valid api token")
+
+type APIToken interface {
+	CreateToken(ctx context.Context, user entities.User, data dto.APIToken) (entities.APITokenCredentials, error)
+	GetTokens(ctx context.Context, user entities.User) ([]entities.APIToken, error)
+	DeleteToken(ctx context.Context, user entities.User, id string) error
+}
+
+type apiToken struct {
+	repository repositories.APIToken
+}
+
+func NewAPIToken(repository repositories.APIToken) APIToken {
+	return &apiToken{repository: repository}
+}
+
+func (c *apiToken) CreateToken(ctx context.Context, user entities.User, data dto.APIToken) (entities.APITokenCredentials, error) {
+	if err := validatePermissions(user, data.Permissions); err != nil {
+		return entities.APITokenCredentials{}, err
+	}
+
+	for _, ip := range data.AllowedIPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return entities.APITokenCredentials{}, errors.Wrap(ValidationError, "allowed ip "+ip)
+			}
+		}
+	}
+
+	if data.ExpiresAt != nil && data.ExpiresAt.Before(time.Now()) {
+		return entities.APITokenCredentials{}, errors.Wrap(ValidationError, "expiration is in the past")
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return entities.APITokenCredentials{}, err
+	}
+
+	secretHash, err := hash.Hash(secret)
+	if err != nil {
+		return entities.APITokenCredentials{}, err
+	}
+
+	if data.Permissions == nil {
+		data.Permissions = []string{}
+	}
+	if data.AllowedIPs == nil {
+		data.AllowedIPs = []string{}
+	}
+
+	credentials := entities.APITokenCredentials{
+		APIToken: entities.APIToken{
+			ID:           primitive.NewObjectID(),
+			StudyPlaceID: user.StudyPlaceInfo.ID,
+			Name:         data.Name,
+			SecretHash:   secretHash,
+			Permissions:  data.Permissions,
+			AllowedIPs:   data.AllowedIPs,
+			ExpiresAt:    data.ExpiresAt,
+			CreatedBy:    user.Id,
+			CreatedAt:    time.Now(),
+		},
+	}
+	credentials.Token = credentials.ID.Hex() + "." + secret
+
+	if err = c.repository.AddToken(ctx, credentials.APIToken); err != nil {
+		return entities.APITokenCredentials{}, err
+	}
+
+	return credentials, nil
+}
+
+func (c *apiToken) GetTokens(ctx context.Context, user entities.User) ([]entities.APIToken, error) {
+	return c.repository.GetTokens(ctx, user.StudyPlaceInfo.ID)
+}
+
+func (c *apiToken) DeleteToken(ctx context.Context, user entities.User, id string) error {
+	tokenID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ValidationError
+	}
+
+	return c.repository.DeleteToken(ctx, user.StudyPlaceInfo.ID, tokenID)
+}
+
+// parseAPIToken splits the token into the id and the secret, tokens are formatted as <id>.<secret>
+func parseAPIToken(token string) (primitive.ObjectID, string, error) {
+	id, secret, found := strings.Cut(token, ".")
+	if !found || secret == "" {
+		return primitive.NilObjectID, "", ErrNotValidAPIToken
+	}
+
+	tokenID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, "", ErrNotValidAPIToken
+	}
+
+	return tokenID, secret, nil
+}
+
+// ipAllowed reports whether the ip matches any of the addresses or CIDR ranges, an empty list allows any ip
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	address := net.ParseIP(ip)
+	if address == nil {
+		return false
+	}
+
+	for _, value := range allowed {
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			if network.Contains(address) {
+				return true
+			}
+			continue
+		}
+
+		if allowedAddress := net.ParseIP(value); allowedAddress != nil && allowedAddress.Equal(address) {
+			return true
+		}
+	}
+
+	return false
+}
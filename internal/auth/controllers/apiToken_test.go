@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestParseAPIToken(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tokenID, secret, err := parseAPIToken(id.Hex() + ".secret")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, tokenID, id)
+	assert.Equal(t, secret, "secret")
+
+	for _, token := range []string{"", id.Hex(), id.Hex() + ".", "id.secret"} {
+		_, _, err = parseAPIToken(token)
+		assert.Equal(t, err, ErrNotValidAPIToken)
+	}
+}
+
+func TestIPAllowed(t *testing.T) {
+	assert.Equal(t, ipAllowed(nil, "10.0.0.1"), true)
+
+	allowed := []string{"192.168.1.0/24", "10.0.0.1", "2001:db8::/32"}
+	assert.Equal(t, ipAllowed(allowed, "192.168.1.42"), true)
+	assert.Equal(t, ipAllowed(allowed, "10.0.0.1"), true)
+	assert.Equal(t, ipAllowed(allowed, "2001:db8::1"), true)
+	assert.Equal(t, ipAllowed(allowed, "10.0.0.2"), false)
+	assert.Equal(t, ipAllowed(allowed, "192.168.2.1"), false)
+	assert.Equal(t, ipAllowed(allowed, ""), false)
+}
@@ -3,7 +3,10 @@ package controllers
 import (
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
 	"golang.org/x/net/context"
+	"strings"
 	"studyum/internal/auth/dto"
 	"studyum/internal/auth/entities"
 	"studyum/internal/auth/repositories"
@@ -13,6 +16,7 @@ import (
 	"studyum/pkg/encryption"
 	"studyum/pkg/hash"
 	entities2 "studyum/pkg/jwt/entities"
+	"studyum/pkg/ratelimit"
 )
 
 var (
@@ -24,6 +28,8 @@ type Auth interface {
 	UpdateByRefreshToken(ctx context.Context, token string, ip string) (entities2.TokenPair, error)
 
 	Login(ctx context.Context, ip string, data dto.Login) (entities.User, entities2.TokenPair, error)
+	SendMagicLink(ctx context.Context, data dto.MagicLink) error
+	LoginViaMagicLink(ctx context.Context, ip string, data dto.MagicLinkLogin) (entities.User, entities2.TokenPair, error)
 
 	SignUp(ctx context.Context, ip string, data dto.SignUp) (entities.User, entities2.TokenPair, error)
 	SignUpStage1(ctx context.Context, user entities.User, data dto.SignUpStage1) (entities.User, error)
@@ -33,7 +39,11 @@ type Auth interface {
 	ConfirmEmail(ctx context.Context, user entities.User, code dto.VerificationCode) error
 	ResendEmailCode(ctx context.Context, user entities.User) error
 
-	TerminateAll(ctx context.Context, user entities.User) error
+	GetSessions(ctx context.Context, user entities.User, pair entities2.TokenPair) ([]entities.Session, error)
+	TerminateSession(ctx context.Context, user entities.User, id string) error
+	TerminateAll(ctx context.Context, user entities.User, pair entities2.TokenPair) error
+
+	JWKS(ctx context.Context) entities2.JWKS
 }
 
 type auth struct {
@@ -41,13 +51,14 @@ type auth struct {
 
 	codes      codes.Controller
 	encryption encryption.Encryption
+	limiter    ratelimit.Limiter
 
 	repository     repositories.Auth
 	codeRepository repositories.Code
 }
 
-func NewAuth(sessions jwt.JWT, codes codes.Controller, encryption encryption.Encryption, repository repositories.Auth, codeRepository repositories.Code) Auth {
-	return &auth{sessions: sessions, codes: codes, encryption: encryption, repository: repository, codeRepository: codeRepository}
+func NewAuth(sessions jwt.JWT, codes codes.Controller, encryption encryption.Encryption, limiter ratelimit.Limiter, repository repositories.Auth, codeRepository repositories.Code) Auth {
+	return &auth{sessions: sessions, codes: codes, encryption: encryption, limiter: limiter, repository: repository, codeRepository: codeRepository}
 }
 
 func (c *auth) UpdateByRefreshToken(ctx context.Context, token string, ip string) (entities2.TokenPair, error) {
@@ -59,15 +70,92 @@ func (c *auth) Login(ctx context.Context, ip string, data dto.Login) (entities.U
 		return entities.User{}, entities2.TokenPair{}, errors.Wrap(ValidationError, "password")
 	}
 
+	account := "account:" + strings.ToLower(data.Login)
+	if err := c.limiter.Check(ctx, account); err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
 	user, err := c.repository.GetUserByLogin(ctx, data.Login)
 	if err != nil {
 		return entities.User{}, entities2.TokenPair{}, err
 	}
 
 	if !hash.CompareHashAndPassword(user.Password, data.Password) {
+		if err = c.limiter.Hit(ctx, account); err != nil {
+			return entities.User{}, entities2.TokenPair{}, err
+		}
+
 		return entities.User{}, entities2.TokenPair{}, ForbiddenErr
 	}
 
+	if err = c.limiter.Reset(ctx, account); err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if user.TOTP.Enabled {
+		return user, entities2.TokenPair{}, ErrTwoFactorRequired
+	}
+
+	pair, err := c.sessions.Create(ctx, ip, user.Id.Hex())
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	c.encryption.Decrypt(&user)
+	return user, pair, nil
+}
+
+// SendMagicLink emails a single-use login link to a verified address,
+// unknown addresses are ignored so the response does not reveal registered users
+func (c *auth) SendMagicLink(ctx context.Context, data dto.MagicLink) error {
+	if err := c.limiter.Hit(ctx, "email:"+strings.ToLower(data.Email)); err != nil {
+		return err
+	}
+
+	user, err := c.repository.GetUserByEmail(ctx, data.Email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !user.VerifiedEmail {
+		return nil
+	}
+
+	code := codesEntities.Code{
+		Type:     codesEntities.MagicLink,
+		Email:    user.Email,
+		UserID:   user.Id,
+		Subject:  "Login link",
+		To:       user.Login,
+		Filename: "magic-link.html",
+	}
+
+	return c.codes.SendLink(ctx, code)
+}
+
+// LoginViaMagicLink consumes the link and creates a session, the second factor is still required if it is enabled
+func (c *auth) LoginViaMagicLink(ctx context.Context, ip string, data dto.MagicLinkLogin) (entities.User, entities2.TokenPair, error) {
+	code, err := c.codes.ReceiveLink(ctx, codesEntities.MagicLink, data.Token)
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	user, err := c.repository.GetUserByEmail(ctx, code.Email)
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if user.Id != code.UserID {
+		return entities.User{}, entities2.TokenPair{}, ForbiddenErr
+	}
+
+	if user.TOTP.Enabled {
+		return user, entities2.TokenPair{}, ErrTwoFactorRequired
+	}
+
 	pair, err := c.sessions.Create(ctx, ip, user.Id.Hex())
 	if err != nil {
 		return entities.User{}, entities2.TokenPair{}, err
@@ -111,8 +199,10 @@ func (c *auth) SignUp(ctx context.Context, ip string, data dto.SignUp) (entities
 				Role:         code.Role,
 				RoleName:     code.RoleName,
 				TuitionGroup: "", //todo
+				Roles:        code.Roles,
 				Accepted:     false,
 			},
+			Children: code.Children,
 		}
 	} else {
 		password, err := hash.Hash(data.Password)
@@ -183,8 +273,10 @@ func (c *auth) SignUpStage1ViaCode(ctx context.Context, user entities.User, code
 		Role:         data.Role,
 		RoleName:     data.RoleName,
 		TuitionGroup: "", //todo
+		Roles:        data.Roles,
 		Accepted:     true,
 	}
+	user.Children = data.Children
 
 	if err = c.repository.UpdateUser(ctx, user); err != nil {
 		return entities.User{}, err
@@ -201,7 +293,7 @@ func (c *auth) SignOut(ctx context.Context, token string) error {
 }
 
 func (c *auth) ConfirmEmail(ctx context.Context, user entities.User, dto dto.VerificationCode) error {
-	code, err := c.codes.Receive(ctx, codesEntities.Verification, dto.Code)
+	code, err := c.codes.Receive(ctx, codesEntities.Verification, user.Email, dto.Code)
 	if err != nil {
 		return err
 	}
@@ -218,6 +310,43 @@ func (c *auth) ResendEmailCode(ctx context.Context, user entities.User) error {
 	return c.codes.Send(ctx, code)
 }
 
-func (c *auth) TerminateAll(context.Context, entities.User) error {
-	return nil
+func (c *auth) GetSessions(ctx context.Context, user entities.User, pair entities2.TokenPair) ([]entities.Session, error) {
+	sessions, err := c.sessions.GetSessions(ctx, user.Id.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := c.sessions.GetSessionID(pair)
+
+	result := make([]entities.Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = entities.Session{
+			ID:      session.ID,
+			IP:      session.IP,
+			Expire:  session.Expire,
+			Current: session.ID == current,
+		}
+	}
+
+	slices.SortFunc(result, func(el1, el2 entities.Session) bool {
+		return el1.Expire.After(el2.Expire)
+	})
+
+	return result, nil
+}
+
+// TerminateSession revokes the session with every session of its refresh family,
+// so tokens of the sessions it was rotated from can't bring it back
+func (c *auth) TerminateSession(ctx context.Context, user entities.User, id string) error {
+	_, err := c.sessions.RemoveFamily(ctx, user.Id.Hex(), id)
+	return err
+}
+
+// TerminateAll revokes every session of the user except the current one
+func (c *auth) TerminateAll(ctx context.Context, user entities.User, pair entities2.TokenPair) error {
+	return c.sessions.RemoveOtherSessions(ctx, user.Id.Hex(), pair)
+}
+
+func (c *auth) JWKS(_ context.Context) entities2.JWKS {
+	return c.sessions.JWKS()
 }
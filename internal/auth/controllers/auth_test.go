@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/entities"
+	"testing"
+)
+
+func TestTerminateSessionRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	c := &auth{sessions: newTestJWT(t)}
+	user := entities.User{Id: primitive.NewObjectID()}
+
+	root, err := c.sessions.Create(ctx, "ip", user.Id.Hex())
+	assert.Equal(t, err, nil)
+
+	child, err := c.sessions.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	id, err := c.sessions.GetSessionID(child)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, c.TerminateSession(ctx, user, id), nil)
+
+	// the rotated parent is still in its grace window, but it belongs to the revoked family
+	_, err = c.sessions.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.NotEqual(t, err, nil)
+
+	sessions, err := c.sessions.GetSessions(ctx, user.Id.Hex())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sessions), 0)
+}
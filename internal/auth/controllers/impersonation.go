@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	"studyum/internal/utils/jwt"
+	entities2 "studyum/pkg/jwt/entities"
+	"time"
+)
+
+// ImpersonationTime is the lifetime of impersonation sessions, they can not be prolonged by refreshing
+const ImpersonationTime = time.Minute * 30
+
+var (
+	ErrImpersonationForbidden = errors.New("user can not be impersonated")
+	ErrImpersonating          = errors.New("action is not allowed while impersonating")
+)
+
+type Impersonation interface {
+	Start(ctx context.Context, actor entities.User, ip string, data dto.Impersonation) (entities2.TokenPair, error)
+	Stop(ctx context.Context, user entities.User, pair entities2.TokenPair) error
+	GetLog(ctx context.Context, user entities.User) ([]entities.Impersonation, error)
+}
+
+type impersonation struct {
+	jwt        jwt.JWT
+	repository repositories.Impersonation
+	roles      repositories.Role
+}
+
+func NewImpersonation(jwt jwt.JWT, repository repositories.Impersonation, roles repositories.Role) Impersonation {
+	return &impersonation{jwt: jwt, repository: repository, roles: roles}
+}
+
+func (c *impersonation) Start(ctx context.Context, actor entities.User, ip string, data dto.Impersonation) (entities2.TokenPair, error) {
+	if actor.Impersonated() {
+		return entities2.TokenPair{}, ErrImpersonating
+	}
+
+	if data.UserID == actor.Id {
+		return entities2.TokenPair{}, errors.Wrap(ValidationError, "can not impersonate yourself")
+	}
+
+	user, err := c.repository.GetUserByID(ctx, data.UserID)
+	if err != nil {
+		return entities2.TokenPair{}, err
+	}
+
+	if user.StudyPlaceInfo.ID != actor.StudyPlaceInfo.ID {
+		return entities2.TokenPair{}, ErrImpersonationForbidden
+	}
+
+	if err = c.covers(ctx, actor, user); err != nil {
+		return entities2.TokenPair{}, err
+	}
+
+	pair, err := c.jwt.CreateImpersonation(ctx, ip, user.Id.Hex(), actor.Id.Hex(), ImpersonationTime)
+	if err != nil {
+		return entities2.TokenPair{}, err
+	}
+
+	sessionID, err := c.jwt.GetSessionID(pair)
+	if err != nil {
+		return entities2.TokenPair{}, err
+	}
+
+	now := time.Now()
+	record := entities.Impersonation{
+		ID:           primitive.NewObjectID(),
+		StudyPlaceID: actor.StudyPlaceInfo.ID,
+		ActorID:      actor.Id,
+		UserID:       user.Id,
+		SessionID:    sessionID,
+		Reason:       data.Reason,
+		IP:           ip,
+		StartedAt:    now,
+		ExpiresAt:    now.Add(ImpersonationTime),
+	}
+	if err = c.repository.AddImpersonation(ctx, record); err != nil {
+		_ = c.jwt.RemoveByToken(ctx, pair.Refresh)
+		return entities2.TokenPair{}, err
+	}
+
+	logrus.Infof("User %s started impersonating %s of study place %s: %s", actor.Id.Hex(), user.Id.Hex(), actor.StudyPlaceInfo.ID.Hex(), data.Reason)
+	return pair, nil
+}
+
+// covers denies impersonating users which hold permissions the actor does not have
+func (c *impersonation) covers(ctx context.Context, actor entities.User, user entities.User) error {
+	permissions := user.StudyPlaceInfo.Permissions
+	if len(user.StudyPlaceInfo.Roles) != 0 {
+		roles, err := c.roles.GetRoles(ctx, user.StudyPlaceInfo.ID)
+		if err != nil {
+			return err
+		}
+
+		permissions = union(permissions, resolvePermissions(indexRoles(roles), user.StudyPlaceInfo.Roles))
+	}
+
+	for _, permission := range permissions {
+		if !actor.Covers(permission) {
+			return ErrImpersonationForbidden
+		}
+	}
+
+	return nil
+}
+
+func (c *impersonation) Stop(ctx context.Context, user entities.User, pair entities2.TokenPair) error {
+	if !user.Impersonated() {
+		return errors.Wrap(ValidationError, "not impersonating")
+	}
+
+	id, err := c.jwt.GetSessionID(pair)
+	if err != nil {
+		return err
+	}
+
+	session, err := c.jwt.RemoveFamily(ctx, user.Id.Hex(), id)
+	if err != nil {
+		return err
+	}
+
+	if err = c.repository.EndImpersonation(ctx, session.GetFamily(), time.Now()); err != nil {
+		return err
+	}
+
+	logrus.Infof("User %s stopped impersonating %s", user.ImpersonatedBy.Hex(), user.Id.Hex())
+	return nil
+}
+
+func (c *impersonation) GetLog(ctx context.Context, user entities.User) ([]entities.Impersonation, error) {
+	return c.repository.GetImpersonations(ctx, user.StudyPlaceInfo.ID)
+}
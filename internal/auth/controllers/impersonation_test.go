@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/entities"
+	"testing"
+)
+
+func TestImpersonationCovers(t *testing.T) {
+	c := &impersonation{}
+	admin := entities.User{StudyPlaceInfo: entities.UserStudyPlaceInfo{Permissions: []string{entities.PermissionImpersonateUsers, "editJournal:group=A"}}}
+	student := entities.User{StudyPlaceInfo: entities.UserStudyPlaceInfo{Permissions: []string{}}}
+	teacher := entities.User{StudyPlaceInfo: entities.UserStudyPlaceInfo{Permissions: []string{"editJournal:group=A"}}}
+	headTeacher := entities.User{StudyPlaceInfo: entities.UserStudyPlaceInfo{Permissions: []string{entities.PermissionEditJournal}}}
+
+	assert.Equal(t, c.covers(context.Background(), admin, student), nil)
+	assert.Equal(t, c.covers(context.Background(), admin, teacher), nil)
+	assert.Equal(t, c.covers(context.Background(), admin, headTeacher), ErrImpersonationForbidden)
+}
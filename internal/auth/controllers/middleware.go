@@ -2,12 +2,16 @@ package controllers
 
 import (
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/net/context"
 	"studyum/internal/auth/entities"
 	"studyum/internal/auth/repositories"
 	"studyum/internal/utils/jwt"
+	"studyum/pkg/hash"
 	entities2 "studyum/pkg/jwt/entities"
+	"time"
 )
 
 var (
@@ -18,25 +22,38 @@ type Middleware interface {
 	Auth(ctx context.Context, pair entities2.TokenPair, ip string, permissions ...string) (entities2.TokenPair, bool, entities.User, error)
 	MemberAuth(ctx context.Context, pair entities2.TokenPair, ip string, permissions ...string) (entities2.TokenPair, bool, entities.User, error)
 
-	AuthViaApiToken(ctx context.Context, token string) (entities.User, error)
+	AuthViaApiToken(ctx context.Context, token string, ip string, permissions ...string) (entities.User, error)
+	ResolvePermissions(ctx context.Context, user entities.User) (entities.User, error)
 }
 
 type middleware struct {
 	jwt        jwt.JWT
 	repository repositories.Middleware
+	apiTokens  repositories.APIToken
+	roles      repositories.Role
 }
 
-func NewMiddleware(jwt jwt.JWT, repository repositories.Middleware) Middleware {
-	return &middleware{jwt: jwt, repository: repository}
+func NewMiddleware(jwt jwt.JWT, repository repositories.Middleware, apiTokens repositories.APIToken, roles repositories.Role) Middleware {
+	return &middleware{jwt: jwt, repository: repository, apiTokens: apiTokens, roles: roles}
 }
 
 func (c *middleware) Auth(ctx context.Context, pair entities2.TokenPair, ip string, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
-	userID, update, err := c.jwt.Auth(ctx, pair)
+	return c.auth(ctx, pair, ip, false, permissions...)
+}
+
+func (c *middleware) MemberAuth(ctx context.Context, pair entities2.TokenPair, ip string, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
+	return c.auth(ctx, pair, ip, true, permissions...)
+}
+
+// auth runs every check before rotating the tokens, as the old refresh token of a rejected request
+// would be taken for a reused one after the rotation timeout and its whole family revoked
+func (c *middleware) auth(ctx context.Context, pair entities2.TokenPair, ip string, member bool, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
+	session, update, err := c.jwt.AuthSession(ctx, pair)
 	if err != nil {
 		return entities2.TokenPair{}, false, entities.User{}, err
 	}
 
-	id, err := primitive.ObjectIDFromHex(userID)
+	id, err := primitive.ObjectIDFromHex(session.UserID)
 	if err != nil {
 		return entities2.TokenPair{}, false, entities.User{}, err
 	}
@@ -45,9 +62,13 @@ func (c *middleware) Auth(ctx context.Context, pair entities2.TokenPair, ip stri
 		return entities2.TokenPair{}, false, entities.User{}, err
 	}
 
-	if update {
-		pair, err = c.jwt.Create(ctx, ip, user.Id.Hex())
-		if err != nil {
+	user, err = c.ResolvePermissions(ctx, user)
+	if err != nil {
+		return entities2.TokenPair{}, false, entities.User{}, err
+	}
+
+	if session.ActorID != "" {
+		if user.ImpersonatedBy, err = primitive.ObjectIDFromHex(session.ActorID); err != nil {
 			return entities2.TokenPair{}, false, entities.User{}, err
 		}
 	}
@@ -56,46 +77,101 @@ func (c *middleware) Auth(ctx context.Context, pair entities2.TokenPair, ip stri
 		return entities2.TokenPair{}, false, entities.User{}, ForbiddenErr
 	}
 
+	if len(permissions) != 0 && !user.TOTP.Enabled {
+		studyPlace, err := c.repository.GetStudyPlaceByID(ctx, user.StudyPlaceInfo.ID)
+		if err == nil && twoFactorRequired(studyPlace.TwoFactorPermissions, user, permissions) {
+			return entities2.TokenPair{}, false, entities.User{}, ErrTwoFactorRequired
+		}
+	}
+
+	if member && !user.StudyPlaceInfo.Accepted {
+		return entities2.TokenPair{}, false, entities.User{}, ForbiddenErr
+	}
+
+	if update {
+		pair, err = c.jwt.UpdateTokensByRefresh(ctx, pair.Refresh, ip)
+		if err != nil {
+			return entities2.TokenPair{}, false, entities.User{}, err
+		}
+	}
+
 	return pair, update, user, nil
 }
 
-func (c *middleware) MemberAuth(ctx context.Context, pair entities2.TokenPair, ip string, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
-	tokenPair, shouldUpdate, user, err := c.Auth(ctx, pair, ip, permissions...)
+// AuthViaApiToken resolves the token to a user of the study place which holds the token permissions only
+func (c *middleware) AuthViaApiToken(ctx context.Context, token string, ip string, permissions ...string) (entities.User, error) {
+	id, secret, err := parseAPIToken(token)
 	if err != nil {
-		return entities2.TokenPair{}, false, entities.User{}, err
+		return entities.User{}, err
 	}
 
-	if !user.StudyPlaceInfo.Accepted {
-		return entities2.TokenPair{}, false, entities.User{}, ForbiddenErr
+	apiToken, err := c.apiTokens.GetTokenByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, ErrNotValidAPIToken
+		}
+		return entities.User{}, err
+	}
+
+	if !hash.CompareHashAndPassword(apiToken.SecretHash, secret) {
+		return entities.User{}, ErrNotValidAPIToken
+	}
+
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(time.Now()) {
+		return entities.User{}, ErrNotValidAPIToken
+	}
+
+	if !ipAllowed(apiToken.AllowedIPs, ip) {
+		return entities.User{}, ForbiddenErr
+	}
+
+	if err = c.apiTokens.UpdateLastUsed(ctx, apiToken.ID, time.Now(), ip); err != nil {
+		logrus.Warningln("Error updating api token usage: " + err.Error())
+	}
+
+	user := entities.User{
+		Id:            apiToken.ID,
+		Login:         apiToken.Name,
+		VerifiedEmail: true,
+		StudyPlaceInfo: entities.UserStudyPlaceInfo{
+			ID:          apiToken.StudyPlaceID,
+			Role:        entities.APITokenRole,
+			RoleName:    apiToken.Name,
+			Permissions: apiToken.Permissions,
+			Accepted:    true,
+		},
+	}
+
+	if !c.hasPermission(user, permissions) {
+		return entities.User{}, ForbiddenErr
 	}
 
-	return tokenPair, shouldUpdate, user, err
+	return user, nil
 }
 
-func (c *middleware) AuthViaApiToken(ctx context.Context, token string) (entities.User, error) {
-	studyPlace, err := c.repository.GetStudyPlaceByApiToken(ctx, token)
-	if err != nil {
-		return entities.User{}, err
+// ResolvePermissions adds permissions of the user roles to the ones granted directly
+func (c *middleware) ResolvePermissions(ctx context.Context, user entities.User) (entities.User, error) {
+	if len(user.StudyPlaceInfo.Roles) == 0 {
+		return user, nil
 	}
 
-	user, err := c.repository.GetUserByID(ctx, studyPlace.AdminID)
+	roles, err := c.roles.GetRoles(ctx, user.StudyPlaceInfo.ID)
 	if err != nil {
 		return entities.User{}, err
 	}
 
+	permissions := resolvePermissions(indexRoles(roles), user.StudyPlaceInfo.Roles)
+	user.StudyPlaceInfo.Permissions = union(user.StudyPlaceInfo.Permissions, permissions)
 	return user, nil
 }
 
 func (c *middleware) hasPermission(user entities.User, permissions []string) bool {
+	if user.StudyPlaceInfo.Role == entities.GuardianRole && len(permissions) != 0 {
+		return false
+	}
+
 	for _, permission := range permissions {
-		found := false
-		for _, uPermission := range user.StudyPlaceInfo.Permissions {
-			if uPermission == permission {
-				found = true
-				continue
-			}
-		}
-		if !found {
+		if !user.HasPermission(permission) {
 			return false
 		}
 	}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/auth/entities"
+	general "studyum/internal/general/entities"
+	jUtils "studyum/internal/utils/jwt"
+	jwt "studyum/pkg/jwt/controllers"
+	jwtEntities "studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/repositories"
+	"testing"
+	"time"
+)
+
+type testMiddlewareRepository struct {
+	user entities.User
+}
+
+func (r testMiddlewareRepository) GetUserByID(context.Context, primitive.ObjectID) (entities.User, error) {
+	return r.user, nil
+}
+
+func (r testMiddlewareRepository) GetStudyPlaceByID(context.Context, primitive.ObjectID) (general.StudyPlace, error) {
+	return general.StudyPlace{}, nil
+}
+
+func TestRejectedRefreshKeepsToken(t *testing.T) {
+	ctx := context.Background()
+	timeout := time.Millisecond * 50
+
+	user := entities.User{Id: primitive.NewObjectID(), StudyPlaceInfo: entities.UserStudyPlaceInfo{Accepted: true}}
+	server := miniredis.RunT(t)
+	j := jwt.NewController[jUtils.Claims]("@daily", time.Minute, time.Hour, timeout, "secret", repositories.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()})))
+	j.SetCreateClaimsFunc(func(ctx context.Context, id, userID string) (jUtils.Claims, error) {
+		return jUtils.NewClaims(id, user), nil
+	})
+	c := &middleware{jwt: j, repository: testMiddlewareRepository{user: user}}
+
+	pair, err := j.Create(ctx, "ip", user.Id.Hex())
+	assert.Equal(t, err, nil)
+	expired := jwtEntities.TokenPair{Refresh: pair.Refresh}
+
+	_, _, _, err = c.MemberAuth(ctx, expired, "ip", entities.PermissionEditJournal)
+	assert.Equal(t, errors.Is(err, ForbiddenErr), true)
+
+	// the refresh token was not rotated, so it is not taken for a reused one after the timeout
+	time.Sleep(timeout * 2)
+
+	newPair, update, _, err := c.MemberAuth(ctx, expired, "ip")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, update, true)
+	assert.NotEqual(t, newPair.Refresh, pair.Refresh)
+
+	_, _, _, err = c.MemberAuth(ctx, jwtEntities.TokenPair{Refresh: newPair.Refresh}, "ip")
+	assert.Equal(t, err, nil)
+}
@@ -5,16 +5,50 @@ import (
 	"errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
 	"studyum/internal/auth/entities"
 	"studyum/internal/auth/repositories"
 	"studyum/internal/utils/jwt"
 	"studyum/pkg/encryption"
 	entities2 "studyum/pkg/jwt/entities"
+	"time"
 )
 
+// OAuth2LinkStatePrefix marks the state of provider callbacks which link an identity instead of signing in
+const OAuth2LinkStatePrefix = "link:"
+
+const oauth2LinkTimeout = time.Minute * 15
+
+var (
+	ErrIdentityTaken            = errors.New("identity is linked to another account")
+	ErrIdentityEmailTaken       = errors.New("account with this email exists, sign in and link the provider")
+	ErrLinkConfirmationRequired = errors.New("identity link confirmation required")
+	ErrLastIdentity             = errors.New("set a password before unlinking the last provider")
+)
+
+// ConfirmLinkError is returned when the identity matches an account by email,
+// the account owner has to sign in and confirm the link with the Token
+type ConfirmLinkError struct {
+	Token string
+}
+
+func (e ConfirmLinkError) Error() string {
+	return ErrLinkConfirmationRequired.Error()
+}
+
+func (e ConfirmLinkError) Is(target error) bool {
+	return target == ErrLinkConfirmationRequired
+}
+
 type OAuth2 interface {
 	GetServiceURL(ctx context.Context, service string, redirect string) (string, error)
-	ReceiveUser(ctx context.Context, serviceName string, code string) (entities2.TokenPair, error)
+	ReceiveUser(ctx context.Context, serviceName string, code string) (entities.User, entities2.TokenPair, error)
+
+	GetLinkURL(ctx context.Context, user entities.User, serviceName string, redirect string) (string, error)
+	LinkIdentity(ctx context.Context, serviceName string, code string, state string) (string, error)
+	ConfirmLink(ctx context.Context, user entities.User, token string) (entities.User, error)
+	GetIdentities(ctx context.Context, user entities.User) []entities.OAuth2Identity
+	UnlinkIdentity(ctx context.Context, user entities.User, provider string) error
 
 	DecryptUser(ctx context.Context, user entities.User) entities.User
 }
@@ -40,46 +74,264 @@ func (c *oauth2) GetServiceURL(ctx context.Context, serviceName string, redirect
 	return service.AuthCodeURL(redirect), nil
 }
 
-func (c *oauth2) ReceiveUser(ctx context.Context, serviceName string, code string) (entities2.TokenPair, error) {
+func (c *oauth2) ReceiveUser(ctx context.Context, serviceName string, code string) (entities.User, entities2.TokenPair, error) {
+	callbackUser, err := c.exchange(ctx, serviceName, code)
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	identity := entities.OAuth2Identity{
+		Provider: serviceName,
+		Subject:  callbackUser.Id,
+		Email:    callbackUser.Email,
+		LinkedAt: time.Now(),
+	}
+
+	user, err := c.repository.GetUserByIdentity(ctx, serviceName, callbackUser.Id)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, entities2.TokenPair{}, err
+		}
+
+		if user, err = c.signUp(ctx, callbackUser, identity); err != nil {
+			return entities.User{}, entities2.TokenPair{}, err
+		}
+	}
+
+	if user.TOTP.Enabled {
+		return user, entities2.TokenPair{}, ErrTwoFactorRequired
+	}
+
+	pair, err := c.jwt.Create(ctx, "0.0.0.0", user.Id.Hex())
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	return user, pair, nil
+}
+
+// signUp creates a user with the identity linked, if the email belongs to an account already,
+// the merge has to be confirmed by its owner and ConfirmLinkError is returned.
+// Accounts without a password were created by a provider sign in before identities were stored,
+// they are linked right away when both sides verified the email, as their owner can't confirm otherwise
+func (c *oauth2) signUp(ctx context.Context, callbackUser entities.OAuth2CallbackUser, identity entities.OAuth2Identity) (entities.User, error) {
+	existing, err := c.repository.GetUserByEmail(ctx, callbackUser.Email)
+	if err == nil {
+		if !callbackUser.VerifiedEmail {
+			return entities.User{}, ErrIdentityEmailTaken
+		}
+
+		if existing.Password == "" && existing.VerifiedEmail {
+			if err = c.addIdentity(ctx, existing.Id, identity); err != nil {
+				return entities.User{}, err
+			}
+
+			return c.repository.GetUserByID(ctx, existing.Id)
+		}
+
+		token, err := randomToken(32)
+		if err != nil {
+			return entities.User{}, err
+		}
+
+		link := entities.OAuth2Link{
+			Token:     token,
+			Type:      entities.OAuth2LinkMerge,
+			UserID:    existing.Id,
+			Identity:  identity,
+			ExpiresAt: time.Now().Add(oauth2LinkTimeout),
+		}
+		if err = c.repository.AddLink(ctx, link); err != nil {
+			return entities.User{}, err
+		}
+
+		return entities.User{}, ConfirmLinkError{Token: token}
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.User{}, err
+	}
+
+	user := entities.User{
+		Id:            primitive.NewObjectID(),
+		Email:         callbackUser.Email,
+		VerifiedEmail: callbackUser.VerifiedEmail,
+		Login:         callbackUser.Name,
+		PictureUrl:    callbackUser.PictureUrl,
+		Identities:    []entities.OAuth2Identity{identity},
+	}
+
+	c.encryption.Encrypt(&user)
+	if err = c.repository.SignUp(ctx, user); err != nil {
+		return entities.User{}, err
+	}
+
+	return user, nil
+}
+
+func (c *oauth2) GetLinkURL(ctx context.Context, user entities.User, serviceName string, redirect string) (string, error) {
 	serviceRaw, err := c.repository.GetService(ctx, serviceName)
 	if err != nil {
-		return entities2.TokenPair{}, err
+		return "", err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
 	}
+
+	link := entities.OAuth2Link{
+		Token:     token,
+		Type:      entities.OAuth2LinkRequest,
+		UserID:    user.Id,
+		Identity:  entities.OAuth2Identity{Provider: serviceName},
+		Redirect:  redirect,
+		ExpiresAt: time.Now().Add(oauth2LinkTimeout),
+	}
+	if err = c.repository.AddLink(ctx, link); err != nil {
+		return "", err
+	}
+
 	service := serviceRaw.Get()
+	return service.AuthCodeURL(OAuth2LinkStatePrefix + token), nil
+}
 
-	token, err := service.Exchange(ctx, code)
+func (c *oauth2) LinkIdentity(ctx context.Context, serviceName string, code string, state string) (string, error) {
+	link, err := c.repository.GetLinkAndDelete(ctx, strings.TrimPrefix(state, OAuth2LinkStatePrefix))
 	if err != nil {
-		return entities2.TokenPair{}, err
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", ErrExpired
+		}
+		return "", err
 	}
 
-	callbackUser, err := c.repository.GetCallbackUser(ctx, service.DataUrl+token.AccessToken)
+	if link.Type != entities.OAuth2LinkRequest || link.Identity.Provider != serviceName {
+		return "", ValidationError
+	}
+
+	callbackUser, err := c.exchange(ctx, serviceName, code)
 	if err != nil {
-		return entities2.TokenPair{}, err
+		return "", err
 	}
 
-	user, err := c.repository.GetUserByEmail(ctx, callbackUser.Email)
+	identity := entities.OAuth2Identity{
+		Provider: serviceName,
+		Subject:  callbackUser.Id,
+		Email:    callbackUser.Email,
+		LinkedAt: time.Now(),
+	}
+	if err = c.addIdentity(ctx, link.UserID, identity); err != nil {
+		return "", err
+	}
+
+	return link.Redirect, nil
+}
+
+func (c *oauth2) ConfirmLink(ctx context.Context, user entities.User, token string) (entities.User, error) {
+	link, err := c.repository.GetLinkAndDelete(ctx, token)
 	if err != nil {
-		if !errors.Is(mongo.ErrNoDocuments, err) {
-			return entities2.TokenPair{}, err
-		}
-		user = entities.User{
-			Id:            primitive.NewObjectID(),
-			Email:         callbackUser.Email,
-			VerifiedEmail: callbackUser.VerifiedEmail,
-			Login:         callbackUser.Name,
-			PictureUrl:    callbackUser.PictureUrl,
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, ErrExpired
 		}
+		return entities.User{}, err
+	}
+
+	if link.Type != entities.OAuth2LinkMerge {
+		return entities.User{}, ValidationError
+	}
+
+	if link.UserID != user.Id {
+		return entities.User{}, ForbiddenErr
+	}
+
+	link.Identity.LinkedAt = time.Now()
+	if err = c.addIdentity(ctx, user.Id, link.Identity); err != nil {
+		return entities.User{}, err
+	}
+
+	user, err = c.repository.GetUserByID(ctx, user.Id)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	c.encryption.Decrypt(&user)
+	return user, nil
+}
+
+func (c *oauth2) GetIdentities(_ context.Context, user entities.User) []entities.OAuth2Identity {
+	if user.Identities == nil {
+		return []entities.OAuth2Identity{}
+	}
+
+	return user.Identities
+}
 
-		c.encryption.Encrypt(&user)
-		if err = c.repository.SignUp(ctx, user); err != nil {
-			return entities2.TokenPair{}, err
+func (c *oauth2) UnlinkIdentity(ctx context.Context, user entities.User, provider string) error {
+	linked := false
+	for _, identity := range user.Identities {
+		if identity.Provider == provider {
+			linked = true
+			break
 		}
 	}
 
-	return c.jwt.Create(ctx, "0.0.0.0", user.Id.Hex())
+	if !linked {
+		return mongo.ErrNoDocuments
+	}
+
+	if user.Password == "" && len(user.Identities) == 1 {
+		return ErrLastIdentity
+	}
+
+	return c.repository.RemoveIdentity(ctx, user.Id, provider)
 }
 
 func (c *oauth2) DecryptUser(_ context.Context, user entities.User) entities.User {
 	c.encryption.Decrypt(&user)
 	return user
 }
+
+func (c *oauth2) exchange(ctx context.Context, serviceName string, code string) (entities.OAuth2CallbackUser, error) {
+	serviceRaw, err := c.repository.GetService(ctx, serviceName)
+	if err != nil {
+		return entities.OAuth2CallbackUser{}, err
+	}
+	service := serviceRaw.Get()
+
+	token, err := service.Exchange(ctx, code)
+	if err != nil {
+		return entities.OAuth2CallbackUser{}, err
+	}
+
+	callbackUser, err := c.repository.GetCallbackUser(ctx, service.DataUrl+token.AccessToken)
+	if err != nil {
+		return entities.OAuth2CallbackUser{}, err
+	}
+
+	if callbackUser.Id == "" {
+		return entities.OAuth2CallbackUser{}, ValidationError
+	}
+
+	return callbackUser, nil
+}
+
+func (c *oauth2) addIdentity(ctx context.Context, userID primitive.ObjectID, identity entities.OAuth2Identity) error {
+	owner, err := c.repository.GetUserByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if owner.Id == userID {
+			return nil
+		}
+		return ErrIdentityTaken
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	if err = c.repository.AddIdentity(ctx, userID, identity); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+			return ErrIdentityTaken
+		}
+		return err
+	}
+
+	return nil
+}
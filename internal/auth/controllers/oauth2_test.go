@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	"testing"
+)
+
+func TestConfirmLinkErrorIs(t *testing.T) {
+	var err error = ConfirmLinkError{Token: "token"}
+
+	assert.Equal(t, errors.Is(err, ErrLinkConfirmationRequired), true)
+	assert.Equal(t, errors.Is(err, ErrIdentityTaken), false)
+
+	var confirmErr ConfirmLinkError
+	assert.Equal(t, errors.As(errors.Wrap(err, "oauth2"), &confirmErr), true)
+	assert.Equal(t, confirmErr.Token, "token")
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	c := &oauth2{}
+	user := entities.User{Identities: []entities.OAuth2Identity{{Provider: "google", Subject: "1"}}}
+
+	assert.Equal(t, c.UnlinkIdentity(context.Background(), user, "github"), mongo.ErrNoDocuments)
+	assert.Equal(t, c.UnlinkIdentity(context.Background(), user, "google"), ErrLastIdentity)
+}
+
+type testOAuth2Repository struct {
+	repositories.OAuth2
+
+	users []entities.User
+	links []entities.OAuth2Link
+}
+
+func (r *testOAuth2Repository) GetUserByEmail(_ context.Context, email string) (entities.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *testOAuth2Repository) GetUserByID(_ context.Context, id primitive.ObjectID) (entities.User, error) {
+	for _, user := range r.users {
+		if user.Id == id {
+			return user, nil
+		}
+	}
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *testOAuth2Repository) GetUserByIdentity(_ context.Context, provider string, subject string) (entities.User, error) {
+	for _, user := range r.users {
+		for _, identity := range user.Identities {
+			if identity.Provider == provider && identity.Subject == subject {
+				return user, nil
+			}
+		}
+	}
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *testOAuth2Repository) AddIdentity(_ context.Context, userID primitive.ObjectID, identity entities.OAuth2Identity) error {
+	for i := range r.users {
+		if r.users[i].Id == userID {
+			r.users[i].Identities = append(r.users[i].Identities, identity)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *testOAuth2Repository) AddLink(_ context.Context, link entities.OAuth2Link) error {
+	r.links = append(r.links, link)
+	return nil
+}
+
+func TestSignUpLinksAccountWithoutPassword(t *testing.T) {
+	id := primitive.NewObjectID()
+	repository := &testOAuth2Repository{users: []entities.User{{Id: id, Email: "user@example.com", VerifiedEmail: true}}}
+	c := &oauth2{repository: repository}
+
+	identity := entities.OAuth2Identity{Provider: "google", Subject: "1", Email: "user@example.com"}
+	callbackUser := entities.OAuth2CallbackUser{Id: "1", Email: "user@example.com", VerifiedEmail: true}
+
+	user, err := c.signUp(context.Background(), callbackUser, identity)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, user.Id, id)
+	assert.Equal(t, len(user.Identities), 1)
+	assert.Equal(t, len(repository.links), 0)
+
+	linked, err := repository.GetUserByIdentity(context.Background(), "google", "1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, linked.Id, id)
+}
+
+func TestSignUpRequiresConfirmation(t *testing.T) {
+	repository := &testOAuth2Repository{users: []entities.User{
+		{Id: primitive.NewObjectID(), Email: "password@example.com", Password: "hash", VerifiedEmail: true},
+		{Id: primitive.NewObjectID(), Email: "unverified@example.com"},
+	}}
+	c := &oauth2{repository: repository}
+
+	for _, email := range []string{"password@example.com", "unverified@example.com"} {
+		identity := entities.OAuth2Identity{Provider: "google", Subject: email, Email: email}
+		callbackUser := entities.OAuth2CallbackUser{Id: email, Email: email, VerifiedEmail: true}
+
+		_, err := c.signUp(context.Background(), callbackUser, identity)
+		assert.Equal(t, errors.Is(err, ErrLinkConfirmationRequired), true)
+	}
+
+	identity := entities.OAuth2Identity{Provider: "google", Subject: "2", Email: "password@example.com"}
+	callbackUser := entities.OAuth2CallbackUser{Id: "2", Email: "password@example.com"}
+
+	_, err := c.signUp(context.Background(), callbackUser, identity)
+	assert.Equal(t, err, ErrIdentityEmailTaken)
+	assert.Equal(t, len(repository.links), 2)
+}
@@ -0,0 +1,483 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/context"
+	"net/url"
+	"strings"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	jUtils "studyum/internal/utils/jwt"
+	"studyum/pkg/encryption"
+	"time"
+)
+
+// OIDCError is an error of the OAuth 2.0 protocol, errors with the same code are equal
+type OIDCError struct {
+	Code        string
+	Description string
+}
+
+func (e OIDCError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Description
+}
+
+func (e OIDCError) Is(target error) bool {
+	t, ok := target.(OIDCError)
+	return ok && t.Code == e.Code
+}
+
+func (e OIDCError) With(description string) OIDCError {
+	return OIDCError{Code: e.Code, Description: description}
+}
+
+var (
+	ErrOIDCInvalidRequest       = OIDCError{Code: "invalid_request"}
+	ErrOIDCInvalidClient        = OIDCError{Code: "invalid_client"}
+	ErrOIDCInvalidGrant         = OIDCError{Code: "invalid_grant"}
+	ErrOIDCInvalidScope         = OIDCError{Code: "invalid_scope"}
+	ErrOIDCInvalidToken         = OIDCError{Code: "invalid_token"}
+	ErrOIDCAccessDenied         = OIDCError{Code: "access_denied"}
+	ErrOIDCUnsupportedGrantType = OIDCError{Code: "unsupported_grant_type"}
+	ErrOIDCUnsupportedResponse  = OIDCError{Code: "unsupported_response_type"}
+)
+
+const (
+	OIDCScopeOpenID     = "openid"
+	OIDCScopeProfile    = "profile"
+	OIDCScopeEmail      = "email"
+	OIDCScopeStudyPlace = "study_place"
+
+	oidcCodeTTL        = time.Minute * 5
+	oidcAccessTokenTTL = time.Hour
+)
+
+var oidcScopes = []string{OIDCScopeOpenID, OIDCScopeProfile, OIDCScopeEmail, OIDCScopeStudyPlace}
+
+type OIDCOptions struct {
+	// Issuer is the public url of the api, token and userinfo endpoints are relative to it
+	Issuer string
+	// AuthorizationURL is the frontend page which shows the consent screen
+	AuthorizationURL string
+}
+
+type OIDC interface {
+	Configuration(ctx context.Context) entities.OIDCConfiguration
+
+	CreateClient(ctx context.Context, user entities.User, data dto.OIDCClient) (entities.OIDCClientCredentials, error)
+	GetClients(ctx context.Context, user entities.User) ([]entities.OIDCClient, error)
+	DeleteClient(ctx context.Context, user entities.User, id string) error
+
+	GetAuthorization(ctx context.Context, user entities.User, data dto.OIDCAuthorize) (entities.OIDCAuthorization, error)
+	Authorize(ctx context.Context, user entities.User, data dto.OIDCConsent) (entities.OIDCRedirect, error)
+
+	Token(ctx context.Context, data dto.OIDCToken) (entities.OIDCTokenResponse, error)
+	UserInfo(ctx context.Context, accessToken string) (entities.OIDCUserInfo, error)
+}
+
+type oidc struct {
+	options OIDCOptions
+
+	jwt        jUtils.JWT
+	encryption encryption.Encryption
+
+	repository repositories.OIDC
+}
+
+func NewOIDC(options OIDCOptions, jwt jUtils.JWT, encryption encryption.Encryption, repository repositories.OIDC) OIDC {
+	return &oidc{options: options, jwt: jwt, encryption: encryption, repository: repository}
+}
+
+func (c *oidc) Configuration(_ context.Context) entities.OIDCConfiguration {
+	algorithms := make([]string, 0, 1)
+	for _, key := range c.jwt.JWKS().Keys {
+		if !slices.Contains(algorithms, key.Alg) {
+			algorithms = append(algorithms, key.Alg)
+		}
+	}
+
+	return entities.OIDCConfiguration{
+		Issuer:                            c.options.Issuer,
+		AuthorizationEndpoint:             c.options.AuthorizationURL,
+		TokenEndpoint:                     c.options.Issuer + "/api/user/oidc/token",
+		UserInfoEndpoint:                  c.options.Issuer + "/api/user/oidc/userinfo",
+		JWKSURI:                           c.options.Issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algorithms,
+		ScopesSupported:                   oidcScopes,
+		ClaimsSupported:                   []string{"sub", "name", "picture", "email", "email_verified", "study_place"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+func (c *oidc) CreateClient(ctx context.Context, user entities.User, data dto.OIDCClient) (entities.OIDCClientCredentials, error) {
+	for _, redirect := range data.RedirectURIs {
+		if u, err := url.Parse(redirect); err != nil || !u.IsAbs() || u.Fragment != "" {
+			return entities.OIDCClientCredentials{}, errors.Wrap(ValidationError, "redirect uri "+redirect)
+		}
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return entities.OIDCClientCredentials{}, err
+	}
+
+	credentials := entities.OIDCClientCredentials{
+		OIDCClient: entities.OIDCClient{
+			ID:           id,
+			Name:         data.Name,
+			StudyPlaceID: user.StudyPlaceInfo.ID,
+			RedirectURIs: data.RedirectURIs,
+			Public:       data.Public,
+			CreatedAt:    time.Now(),
+		},
+	}
+
+	if !data.Public {
+		if credentials.Secret, err = randomToken(32); err != nil {
+			return entities.OIDCClientCredentials{}, err
+		}
+
+		credentials.SecretHash = hashToken(credentials.Secret)
+	}
+
+	if err = c.repository.AddClient(ctx, credentials.OIDCClient); err != nil {
+		return entities.OIDCClientCredentials{}, err
+	}
+
+	return credentials, nil
+}
+
+func (c *oidc) GetClients(ctx context.Context, user entities.User) ([]entities.OIDCClient, error) {
+	return c.repository.GetClients(ctx, user.StudyPlaceInfo.ID)
+}
+
+func (c *oidc) DeleteClient(ctx context.Context, user entities.User, id string) error {
+	return c.repository.DeleteClient(ctx, user.StudyPlaceInfo.ID, id)
+}
+
+// authorization validates the request, errors are not redirected as the redirect uri may be not trusted yet
+func (c *oidc) authorization(ctx context.Context, user entities.User, data dto.OIDCAuthorize) (entities.OIDCClient, []string, error) {
+	client, err := c.repository.GetClient(ctx, data.ClientID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.OIDCClient{}, nil, ErrOIDCInvalidRequest.With("unknown client_id")
+	}
+	if err != nil {
+		return entities.OIDCClient{}, nil, err
+	}
+
+	if !slices.Contains(client.RedirectURIs, data.RedirectURI) {
+		return entities.OIDCClient{}, nil, ErrOIDCInvalidRequest.With("redirect_uri is not registered")
+	}
+
+	if data.ResponseType != "code" {
+		return entities.OIDCClient{}, nil, ErrOIDCUnsupportedResponse
+	}
+
+	scopes := parseScopes(data.Scope)
+	if !slices.Contains(scopes, OIDCScopeOpenID) {
+		return entities.OIDCClient{}, nil, ErrOIDCInvalidScope.With("openid scope is required")
+	}
+
+	if data.CodeChallenge == "" || data.CodeChallengeMethod != "S256" {
+		return entities.OIDCClient{}, nil, ErrOIDCInvalidRequest.With("S256 code_challenge is required")
+	}
+
+	if user.StudyPlaceInfo.ID != client.StudyPlaceID || !user.StudyPlaceInfo.Accepted {
+		return entities.OIDCClient{}, nil, ErrOIDCAccessDenied.With("the client belongs to another study place")
+	}
+
+	return client, scopes, nil
+}
+
+func (c *oidc) GetAuthorization(ctx context.Context, user entities.User, data dto.OIDCAuthorize) (entities.OIDCAuthorization, error) {
+	client, scopes, err := c.authorization(ctx, user, data)
+	if err != nil {
+		return entities.OIDCAuthorization{}, err
+	}
+
+	consented := false
+	consent, err := c.repository.GetConsent(ctx, user.Id, client.ID)
+	if err == nil {
+		consented = containsAll(consent.Scopes, scopes)
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.OIDCAuthorization{}, err
+	}
+
+	return entities.OIDCAuthorization{Client: client, Scopes: scopes, Consented: consented}, nil
+}
+
+// Authorize records the user decision and returns where to redirect the user agent with the code or the error
+func (c *oidc) Authorize(ctx context.Context, user entities.User, data dto.OIDCConsent) (entities.OIDCRedirect, error) {
+	client, scopes, err := c.authorization(ctx, user, data.OIDCAuthorize)
+	if err != nil {
+		return entities.OIDCRedirect{}, err
+	}
+
+	if !data.Approve {
+		return redirect(data.RedirectURI, url.Values{"error": {ErrOIDCAccessDenied.Code}, "state": {data.State}})
+	}
+
+	consent := entities.OIDCConsent{ID: primitive.NewObjectID(), UserID: user.Id, ClientID: client.ID, Scopes: scopes}
+	if saved, err := c.repository.GetConsent(ctx, user.Id, client.ID); err == nil {
+		consent.Scopes = union(saved.Scopes, scopes)
+	}
+
+	if err = c.repository.SaveConsent(ctx, consent); err != nil {
+		return entities.OIDCRedirect{}, err
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return entities.OIDCRedirect{}, err
+	}
+
+	err = c.repository.AddCode(ctx, entities.OIDCAuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      client.ID,
+		UserID:        user.Id,
+		RedirectURI:   data.RedirectURI,
+		Scopes:        scopes,
+		Nonce:         data.Nonce,
+		CodeChallenge: data.CodeChallenge,
+		AuthTime:      time.Now(),
+		ExpiresAt:     time.Now().Add(oidcCodeTTL),
+	})
+	if err != nil {
+		return entities.OIDCRedirect{}, err
+	}
+
+	return redirect(data.RedirectURI, url.Values{"code": {code}, "state": {data.State}})
+}
+
+// Token exchanges the authorization code for an access and an ID token
+func (c *oidc) Token(ctx context.Context, data dto.OIDCToken) (entities.OIDCTokenResponse, error) {
+	if data.GrantType != "authorization_code" {
+		return entities.OIDCTokenResponse{}, ErrOIDCUnsupportedGrantType
+	}
+
+	client, err := c.authenticateClient(ctx, data.ClientID, data.ClientSecret)
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+
+	code, err := c.repository.GetCodeAndDelete(ctx, hashToken(data.Code))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.OIDCTokenResponse{}, ErrOIDCInvalidGrant.With("unknown code")
+	}
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != data.RedirectURI || time.Now().After(code.ExpiresAt) {
+		return entities.OIDCTokenResponse{}, ErrOIDCInvalidGrant
+	}
+
+	if !verifyCodeChallenge(code.CodeChallenge, data.CodeVerifier) {
+		return entities.OIDCTokenResponse{}, ErrOIDCInvalidGrant.With("code_verifier does not match")
+	}
+
+	user, err := c.repository.GetUserByID(ctx, code.UserID)
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+	c.encryption.Decrypt(&user)
+
+	accessToken, err := randomToken(32)
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+
+	now := time.Now()
+	err = c.repository.AddAccessToken(ctx, entities.OIDCAccessToken{
+		TokenHash: hashToken(accessToken),
+		ClientID:  client.ID,
+		UserID:    user.Id,
+		Scopes:    code.Scopes,
+		ExpiresAt: now.Add(oidcAccessTokenTTL),
+	})
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+
+	idToken, err := c.jwt.Sign(entities.OIDCIDToken{
+		OIDCUserInfo: buildUserInfo(user, code.Scopes),
+		Issuer:       c.options.Issuer,
+		Audience:     client.ID,
+		ExpiresAt:    now.Add(oidcAccessTokenTTL).Unix(),
+		IssuedAt:     now.Unix(),
+		AuthTime:     code.AuthTime.Unix(),
+		Nonce:        code.Nonce,
+	})
+	if err != nil {
+		return entities.OIDCTokenResponse{}, err
+	}
+
+	return entities.OIDCTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(oidcAccessTokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       strings.Join(code.Scopes, " "),
+	}, nil
+}
+
+func (c *oidc) authenticateClient(ctx context.Context, id, secret string) (entities.OIDCClient, error) {
+	client, err := c.repository.GetClient(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.OIDCClient{}, ErrOIDCInvalidClient
+	}
+	if err != nil {
+		return entities.OIDCClient{}, err
+	}
+
+	if client.Public {
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashToken(secret))) != 1 {
+		return entities.OIDCClient{}, ErrOIDCInvalidClient
+	}
+
+	return client, nil
+}
+
+func (c *oidc) UserInfo(ctx context.Context, accessToken string) (entities.OIDCUserInfo, error) {
+	token, err := c.repository.GetAccessToken(ctx, hashToken(accessToken))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.OIDCUserInfo{}, ErrOIDCInvalidToken
+	}
+	if err != nil {
+		return entities.OIDCUserInfo{}, err
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return entities.OIDCUserInfo{}, ErrOIDCInvalidToken
+	}
+
+	user, err := c.repository.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return entities.OIDCUserInfo{}, err
+	}
+	c.encryption.Decrypt(&user)
+
+	return buildUserInfo(user, token.Scopes), nil
+}
+
+// buildUserInfo releases claims of the user session for the granted scopes only
+func buildUserInfo(user entities.User, scopes []string) entities.OIDCUserInfo {
+	claims := jUtils.NewClaims("", user)
+	info := entities.OIDCUserInfo{Subject: claims.UserID}
+
+	if slices.Contains(scopes, OIDCScopeProfile) {
+		info.Name = claims.Login
+		info.Picture = claims.PictureURL
+	}
+
+	if slices.Contains(scopes, OIDCScopeEmail) {
+		info.Email = claims.Email
+		info.EmailVerified = &claims.VerifiedEmail
+	}
+
+	if slices.Contains(scopes, OIDCScopeStudyPlace) && claims.StudyPlaceInfo.Accepted {
+		info.StudyPlace = &entities.OIDCStudyPlace{
+			ID:       claims.StudyPlaceInfo.Id,
+			Name:     claims.StudyPlaceInfo.Name,
+			Role:     claims.StudyPlaceInfo.Role,
+			RoleName: claims.StudyPlaceInfo.RoleName,
+			Group:    claims.StudyPlaceInfo.TuitionGroup,
+		}
+	}
+
+	return info
+}
+
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// parseScopes returns known scopes of the space separated list, unknown ones are ignored
+func parseScopes(scope string) []string {
+	scopes := make([]string, 0, len(oidcScopes))
+	for _, s := range strings.Fields(scope) {
+		if slices.Contains(oidcScopes, s) && !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes
+}
+
+func containsAll(set []string, values []string) bool {
+	for _, value := range values {
+		if !slices.Contains(set, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func union(a []string, b []string) []string {
+	result := append([]string{}, a...)
+	for _, value := range b {
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func redirect(uri string, params url.Values) (entities.OIDCRedirect, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return entities.OIDCRedirect{}, err
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		if len(values) != 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return entities.OIDCRedirect{Redirect: u.String()}, nil
+}
+
+func randomToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/auth/entities"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.Equal(t, verifyCodeChallenge(challenge, verifier), true)
+	assert.Equal(t, verifyCodeChallenge(challenge, verifier+"a"), false)
+	assert.Equal(t, verifyCodeChallenge(challenge, ""), false)
+	assert.Equal(t, verifyCodeChallenge("", "short"), false)
+}
+
+func TestParseScopes(t *testing.T) {
+	assert.Equal(t, parseScopes("openid  email unknown openid"), []string{"openid", "email"})
+	assert.Equal(t, parseScopes(""), []string{})
+}
+
+func TestRedirect(t *testing.T) {
+	r, err := redirect("https://app.example/callback?tab=1", map[string][]string{"code": {"abc"}, "state": {""}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, r.Redirect, "https://app.example/callback?code=abc&tab=1")
+}
+
+func TestBuildUserInfo(t *testing.T) {
+	user := entities.User{
+		Id:            primitive.NewObjectID(),
+		Login:         "student",
+		Email:         "student@example.com",
+		VerifiedEmail: true,
+		StudyPlaceInfo: entities.UserStudyPlaceInfo{
+			ID:           primitive.NewObjectID(),
+			Name:         "College",
+			Role:         "student",
+			TuitionGroup: "95T",
+			Accepted:     true,
+		},
+	}
+
+	info := buildUserInfo(user, []string{OIDCScopeOpenID})
+	assert.Equal(t, info, entities.OIDCUserInfo{Subject: user.Id.Hex()})
+
+	info = buildUserInfo(user, []string{OIDCScopeOpenID, OIDCScopeEmail, OIDCScopeStudyPlace})
+	assert.Equal(t, info.Name, "")
+	assert.Equal(t, info.Email, "student@example.com")
+	assert.Equal(t, *info.EmailVerified, true)
+	assert.Equal(t, info.StudyPlace.ID, user.StudyPlaceInfo.ID.Hex())
+	assert.Equal(t, info.StudyPlace.Role, "student")
+	assert.Equal(t, info.StudyPlace.Group, "95T")
+
+	user.StudyPlaceInfo.Accepted = false
+	info = buildUserInfo(user, []string{OIDCScopeOpenID, OIDCScopeStudyPlace})
+	assert.Equal(t, info.StudyPlace == nil, true)
+}
+
+func TestOIDCErrorIs(t *testing.T) {
+	err := errors.Wrap(ErrOIDCInvalidGrant.With("unknown code"), "token")
+
+	assert.Equal(t, errors.Is(err, ErrOIDCInvalidGrant), true)
+	assert.Equal(t, errors.Is(err, ErrOIDCInvalidClient), false)
+	assert.Equal(t, ErrOIDCInvalidGrant.With("unknown code").Error(), "invalid_grant: unknown code")
+}
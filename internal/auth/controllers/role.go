@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+)
+
+var ErrRoleInUse = errors.New("role is inherited by other roles")
+
+type Role interface {
+	GetPermissions(ctx context.Context) []entities.Permission
+
+	GetRoles(ctx context.Context, user entities.User) ([]entities.Role, error)
+	CreateRole(ctx context.Context, user entities.User, data dto.Role) (entities.Role, error)
+	UpdateRole(ctx context.Context, user entities.User, id string, data dto.Role) (entities.Role, error)
+	DeleteRole(ctx context.Context, user entities.User, id string) error
+
+	SetUserRoles(ctx context.Context, user entities.User, userID string, data dto.UserRoles) error
+}
+
+type role struct {
+	repository repositories.Role
+}
+
+func NewRole(repository repositories.Role) Role {
+	return &role{repository: repository}
+}
+
+func (c *role) GetPermissions(_ context.Context) []entities.Permission {
+	return entities.PermissionCatalogue
+}
+
+func (c *role) GetRoles(ctx context.Context, user entities.User) ([]entities.Role, error) {
+	return c.repository.GetRoles(ctx, user.StudyPlaceInfo.ID)
+}
+
+func (c *role) CreateRole(ctx context.Context, user entities.User, data dto.Role) (entities.Role, error) {
+	role := entities.Role{
+		ID:           primitive.NewObjectID(),
+		StudyPlaceID: user.StudyPlaceInfo.ID,
+		Name:         data.Name,
+		Permissions:  data.Permissions,
+		Inherits:     data.Inherits,
+	}
+
+	if err := c.validate(ctx, user, role); err != nil {
+		return entities.Role{}, err
+	}
+
+	if err := c.repository.AddRole(ctx, role); err != nil {
+		return entities.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (c *role) UpdateRole(ctx context.Context, user entities.User, id string, data dto.Role) (entities.Role, error) {
+	roleID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Role{}, ValidationError
+	}
+
+	role := entities.Role{
+		ID:           roleID,
+		StudyPlaceID: user.StudyPlaceInfo.ID,
+		Name:         data.Name,
+		Permissions:  data.Permissions,
+		Inherits:     data.Inherits,
+	}
+
+	if err = c.validate(ctx, user, role); err != nil {
+		return entities.Role{}, err
+	}
+
+	if err = c.repository.UpdateRole(ctx, role); err != nil {
+		return entities.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (c *role) DeleteRole(ctx context.Context, user entities.User, id string) error {
+	roleID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ValidationError
+	}
+
+	roles, err := c.repository.GetRoles(ctx, user.StudyPlaceInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		if slices.Contains(role.Inherits, roleID) {
+			return errors.Wrap(ErrRoleInUse, role.Name)
+		}
+	}
+
+	return c.repository.DeleteRole(ctx, user.StudyPlaceInfo.ID, roleID)
+}
+
+// SetUserRoles replaces roles of the study place member, the roles can not grant more than the manager has
+func (c *role) SetUserRoles(ctx context.Context, user entities.User, userID string, data dto.UserRoles) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return ValidationError
+	}
+
+	roles, err := c.repository.GetRoles(ctx, user.StudyPlaceInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	index := indexRoles(roles)
+	for _, roleID := range data.Roles {
+		if _, ok := index[roleID]; !ok {
+			return errors.Wrap(ValidationError, "unknown role "+roleID.Hex())
+		}
+	}
+
+	if err = validatePermissions(user, resolvePermissions(index, data.Roles)); err != nil {
+		return err
+	}
+
+	if data.Roles == nil {
+		data.Roles = []primitive.ObjectID{}
+	}
+
+	return c.repository.SetUserRoles(ctx, user.StudyPlaceInfo.ID, id, data.Roles)
+}
+
+// validate checks inherited roles exist and do not form a cycle and the role permissions are valid
+func (c *role) validate(ctx context.Context, user entities.User, role entities.Role) error {
+	roles, err := c.repository.GetRoles(ctx, user.StudyPlaceInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	index := indexRoles(roles)
+	index[role.ID] = role
+
+	for _, roleID := range role.Inherits {
+		if _, ok := index[roleID]; !ok {
+			return errors.Wrap(ValidationError, "unknown role "+roleID.Hex())
+		}
+	}
+
+	if inheritanceCycle(index, role.ID) {
+		return errors.Wrap(ValidationError, "role inherits itself")
+	}
+
+	return validatePermissions(user, resolvePermissions(index, []primitive.ObjectID{role.ID}))
+}
+
+// validatePermissions checks the permissions are in the catalogue, have valid scopes and are covered by the user ones
+func validatePermissions(user entities.User, permissions []string) error {
+	for _, permission := range permissions {
+		grant, err := entities.ParsePermission(permission)
+		if err != nil {
+			return errors.Wrap(ValidationError, err.Error())
+		}
+
+		if slices.IndexFunc(entities.PermissionCatalogue, func(p entities.Permission) bool { return p.Name == grant.Name }) == -1 {
+			return errors.Wrap(ValidationError, "unknown permission "+permission)
+		}
+
+		if !user.Covers(permission) {
+			return errors.Wrap(ForbiddenErr, "permission "+permission+" exceeds yours")
+		}
+	}
+
+	return nil
+}
+
+func indexRoles(roles []entities.Role) map[primitive.ObjectID]entities.Role {
+	index := make(map[primitive.ObjectID]entities.Role, len(roles))
+	for _, role := range roles {
+		index[role.ID] = role
+	}
+
+	return index
+}
+
+// resolvePermissions collects permissions of the roles and all roles they inherit, unknown roles are skipped
+func resolvePermissions(roles map[primitive.ObjectID]entities.Role, ids []primitive.ObjectID) []string {
+	permissions := make([]string, 0)
+	visited := make(map[primitive.ObjectID]bool)
+
+	queue := append([]primitive.ObjectID{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		role, ok := roles[id]
+		if !ok {
+			continue
+		}
+
+		permissions = union(permissions, role.Permissions)
+		queue = append(queue, role.Inherits...)
+	}
+
+	return permissions
+}
+
+// inheritanceCycle reports whether the role inherits itself through any chain of roles
+func inheritanceCycle(roles map[primitive.ObjectID]entities.Role, id primitive.ObjectID) bool {
+	visited := make(map[primitive.ObjectID]bool)
+
+	queue := append([]primitive.ObjectID{}, roles[id].Inherits...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		if next == id {
+			return true
+		}
+
+		if visited[next] {
+			continue
+		}
+		visited[next] = true
+
+		queue = append(queue, roles[next].Inherits...)
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/auth/entities"
+	"testing"
+)
+
+func TestResolvePermissions(t *testing.T) {
+	teacher := entities.Role{ID: primitive.NewObjectID(), Permissions: []string{entities.PermissionEditJournal}}
+	curator := entities.Role{ID: primitive.NewObjectID(), Permissions: []string{entities.PermissionManageUsers}, Inherits: []primitive.ObjectID{teacher.ID}}
+	head := entities.Role{ID: primitive.NewObjectID(), Permissions: []string{entities.PermissionEditSchedule, entities.PermissionEditJournal}, Inherits: []primitive.ObjectID{curator.ID}}
+
+	roles := indexRoles([]entities.Role{teacher, curator, head})
+
+	assert.Equal(t, resolvePermissions(roles, nil), []string{})
+	assert.Equal(t, resolvePermissions(roles, []primitive.ObjectID{teacher.ID}), []string{entities.PermissionEditJournal})
+	assert.Equal(t, resolvePermissions(roles, []primitive.ObjectID{curator.ID}), []string{entities.PermissionManageUsers, entities.PermissionEditJournal})
+	assert.Equal(t, resolvePermissions(roles, []primitive.ObjectID{head.ID, primitive.NewObjectID()}), []string{entities.PermissionEditSchedule, entities.PermissionEditJournal, entities.PermissionManageUsers})
+}
+
+func TestInheritanceCycle(t *testing.T) {
+	a := entities.Role{ID: primitive.NewObjectID()}
+	b := entities.Role{ID: primitive.NewObjectID(), Inherits: []primitive.ObjectID{a.ID}}
+	c := entities.Role{ID: primitive.NewObjectID(), Inherits: []primitive.ObjectID{b.ID}}
+
+	roles := indexRoles([]entities.Role{a, b, c})
+	assert.Equal(t, inheritanceCycle(roles, c.ID), false)
+
+	a.Inherits = []primitive.ObjectID{c.ID}
+	roles[a.ID] = a
+	assert.Equal(t, inheritanceCycle(roles, a.ID), true)
+	assert.Equal(t, inheritanceCycle(roles, b.ID), true)
+
+	self := entities.Role{ID: primitive.NewObjectID()}
+	self.Inherits = []primitive.ObjectID{self.ID}
+	assert.Equal(t, inheritanceCycle(indexRoles([]entities.Role{self}), self.ID), true)
+}
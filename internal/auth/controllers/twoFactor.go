@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/context"
+	"math/big"
+	"strings"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	"studyum/internal/utils/jwt"
+	"studyum/pkg/encryption"
+	entities2 "studyum/pkg/jwt/entities"
+	"studyum/pkg/totp"
+	"time"
+)
+
+var (
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+	ErrTwoFactorCode     = errors.New("not valid two-factor code")
+)
+
+const (
+	totpIssuer = "Studyum"
+
+	challengeTTL      = time.Minute * 5
+	challengeAttempts = 5
+
+	recoveryCodesAmount = 10
+	recoveryCodeLength  = 10
+	recoveryCodeRunes   = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+type TwoFactor interface {
+	Enroll(ctx context.Context, user entities.User) (entities.TOTPEnrollment, error)
+	Enable(ctx context.Context, user entities.User, data dto.TwoFactorCode) ([]string, error)
+	Disable(ctx context.Context, user entities.User, data dto.TwoFactorCode) error
+	RegenerateRecoveryCodes(ctx context.Context, user entities.User, data dto.TwoFactorCode) ([]string, error)
+
+	Challenge(ctx context.Context, ip string, user entities.User) (entities.TwoFactorChallenge, error)
+	Login(ctx context.Context, ip string, data dto.TwoFactorLogin) (entities.User, entities2.TokenPair, error)
+}
+
+type twoFactor struct {
+	jwt        jwt.JWT
+	encryption encryption.Encryption
+
+	repository repositories.TwoFactor
+}
+
+func NewTwoFactor(jwt jwt.JWT, encryption encryption.Encryption, repository repositories.TwoFactor) TwoFactor {
+	return &twoFactor{jwt: jwt, encryption: encryption, repository: repository}
+}
+
+func (c *twoFactor) secret(user entities.User) string {
+	return c.encryption.DecryptString(user.TOTP.Secret)
+}
+
+// Enroll generates a new TOTP secret, it takes effect only after Enable confirms a code
+func (c *twoFactor) Enroll(ctx context.Context, user entities.User) (entities.TOTPEnrollment, error) {
+	if user.TOTP.Enabled {
+		return entities.TOTPEnrollment{}, errors.Wrap(ValidationError, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return entities.TOTPEnrollment{}, err
+	}
+
+	if err = c.repository.SetTOTP(ctx, user.Id, entities.TOTP{Secret: c.encryption.EncryptString(secret)}); err != nil {
+		return entities.TOTPEnrollment{}, err
+	}
+
+	return entities.TOTPEnrollment{Secret: secret, URI: totp.URI(totpIssuer, user.Login, secret)}, nil
+}
+
+func (c *twoFactor) Enable(ctx context.Context, user entities.User, data dto.TwoFactorCode) ([]string, error) {
+	if user.TOTP.Enabled || c.secret(user) == "" {
+		return nil, errors.Wrap(ValidationError, "two-factor enrolment")
+	}
+
+	step, ok := totp.Validate(c.secret(user), data.Code, time.Now(), user.TOTP.LastStep)
+	if !ok {
+		return nil, ErrTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTP.Enabled = true
+	user.TOTP.LastStep = step
+	user.TOTP.RecoveryCodes = hashes
+	if err = c.repository.SetTOTP(ctx, user.Id, user.TOTP); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (c *twoFactor) Disable(ctx context.Context, user entities.User, data dto.TwoFactorCode) error {
+	if !user.TOTP.Enabled {
+		return nil
+	}
+
+	if err := c.verify(ctx, user, data.Code); err != nil {
+		return err
+	}
+
+	return c.repository.SetTOTP(ctx, user.Id, entities.TOTP{})
+}
+
+func (c *twoFactor) RegenerateRecoveryCodes(ctx context.Context, user entities.User, data dto.TwoFactorCode) ([]string, error) {
+	if !user.TOTP.Enabled {
+		return nil, errors.Wrap(ValidationError, "two-factor authentication is disabled")
+	}
+
+	step, ok := totp.Validate(c.secret(user), data.Code, time.Now(), user.TOTP.LastStep)
+	if !ok {
+		return nil, ErrTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTP.LastStep = step
+	user.TOTP.RecoveryCodes = hashes
+	if err = c.repository.SetTOTP(ctx, user.Id, user.TOTP); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Challenge starts the second login step for a user whose password or OAuth2 login succeeded
+func (c *twoFactor) Challenge(ctx context.Context, ip string, user entities.User) (entities.TwoFactorChallenge, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return entities.TwoFactorChallenge{}, err
+	}
+
+	challenge := entities.TwoFactorChallenge{
+		Token:     base64.RawURLEncoding.EncodeToString(token),
+		UserID:    user.Id,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(challengeTTL),
+	}
+
+	if err := c.repository.AddChallenge(ctx, challenge); err != nil {
+		return entities.TwoFactorChallenge{}, err
+	}
+
+	return challenge, nil
+}
+
+// Login finishes the second login step with a TOTP or a recovery code and issues a token pair.
+// The attempt is counted before the code is checked, so parallel guesses can't exceed the limit,
+// and the challenge is bound to the ip which passed the first step
+func (c *twoFactor) Login(ctx context.Context, ip string, data dto.TwoFactorLogin) (entities.User, entities2.TokenPair, error) {
+	challenge, err := c.repository.IncChallengeAttempts(ctx, data.Token, challengeAttempts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			_ = c.repository.DeleteChallenge(ctx, data.Token)
+			return entities.User{}, entities2.TokenPair{}, ErrExpired
+		}
+
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if time.Now().After(challenge.ExpiresAt) {
+		_ = c.repository.DeleteChallenge(ctx, challenge.Token)
+		return entities.User{}, entities2.TokenPair{}, ErrExpired
+	}
+
+	if challenge.IP != ip {
+		return entities.User{}, entities2.TokenPair{}, ErrExpired
+	}
+
+	user, err := c.repository.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if err = c.verify(ctx, user, data.Code); err != nil {
+		if challenge.Attempts >= challengeAttempts {
+			_ = c.repository.DeleteChallenge(ctx, challenge.Token)
+		}
+
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if _, err = c.repository.TakeChallenge(ctx, challenge.Token); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, entities2.TokenPair{}, ErrExpired
+		}
+
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	pair, err := c.jwt.Create(ctx, ip, user.Id.Hex())
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	c.encryption.Decrypt(&user)
+	return user, pair, nil
+}
+
+// verify accepts a TOTP code or consumes one of the recovery codes
+func (c *twoFactor) verify(ctx context.Context, user entities.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(c.secret(user), code, time.Now(), user.TOTP.LastStep)
+		if !ok || c.repository.UseTOTPStep(ctx, user.Id, step) != nil {
+			return ErrTwoFactorCode
+		}
+
+		return nil
+	}
+
+	hash := hashRecoveryCode(code)
+	if !slices.Contains(user.TOTP.RecoveryCodes, hash) || c.repository.UseRecoveryCode(ctx, user.Id, hash) != nil {
+		return ErrTwoFactorCode
+	}
+
+	return nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodesAmount)
+	hashes := make([]string, recoveryCodesAmount)
+
+	max := big.NewInt(int64(len(recoveryCodeRunes)))
+	for i := range codes {
+		code := make([]byte, recoveryCodeLength)
+		for j := range code {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			code[j] = recoveryCodeRunes[n.Int64()]
+		}
+
+		codes[i] = string(code[:recoveryCodeLength/2]) + "-" + string(code[recoveryCodeLength/2:])
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// twoFactorRequired reports whether the study place demands two-factor authentication for any of the permissions
+func twoFactorRequired(studyPlaceTwoFactorPermissions []string, user entities.User, permissions []string) bool {
+	if user.TOTP.Enabled {
+		return false
+	}
+
+	for _, permission := range permissions {
+		if slices.Contains(studyPlaceTwoFactorPermissions, permission) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/pkg/encryption"
+	"sync"
+	"testing"
+)
+
+// memoryTwoFactor keeps a user and challenges in memory
+type memoryTwoFactor struct {
+	mutex sync.Mutex
+
+	user       entities.User
+	challenges map[string]entities.TwoFactorChallenge
+}
+
+func (r *memoryTwoFactor) GetUserByID(context.Context, primitive.ObjectID) (entities.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.user, nil
+}
+
+func (r *memoryTwoFactor) SetTOTP(context.Context, primitive.ObjectID, entities.TOTP) error {
+	return nil
+}
+
+func (r *memoryTwoFactor) UseTOTPStep(context.Context, primitive.ObjectID, int64) error {
+	return mongo.ErrNoDocuments
+}
+
+func (r *memoryTwoFactor) UseRecoveryCode(_ context.Context, _ primitive.ObjectID, code string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i := slices.Index(r.user.TOTP.RecoveryCodes, code)
+	if i == -1 {
+		return mongo.ErrNoDocuments
+	}
+
+	r.user.TOTP.RecoveryCodes = slices.Delete(r.user.TOTP.RecoveryCodes, i, i+1)
+	return nil
+}
+
+func (r *memoryTwoFactor) AddChallenge(_ context.Context, challenge entities.TwoFactorChallenge) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.challenges[challenge.Token] = challenge
+	return nil
+}
+
+func (r *memoryTwoFactor) IncChallengeAttempts(_ context.Context, token string, maxAttempts int) (entities.TwoFactorChallenge, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	challenge, ok := r.challenges[token]
+	if !ok || challenge.Attempts >= maxAttempts {
+		return entities.TwoFactorChallenge{}, mongo.ErrNoDocuments
+	}
+
+	challenge.Attempts++
+	r.challenges[token] = challenge
+	return challenge, nil
+}
+
+func (r *memoryTwoFactor) TakeChallenge(_ context.Context, token string) (entities.TwoFactorChallenge, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	challenge, ok := r.challenges[token]
+	if !ok {
+		return entities.TwoFactorChallenge{}, mongo.ErrNoDocuments
+	}
+
+	delete(r.challenges, token)
+	return challenge, nil
+}
+
+func (r *memoryTwoFactor) DeleteChallenge(_ context.Context, token string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.challenges, token)
+	return nil
+}
+
+func newTestTwoFactor(t *testing.T, codes ...string) (*twoFactor, *memoryTwoFactor, entities.User) {
+	user := entities.User{Id: primitive.NewObjectID(), TOTP: entities.TOTP{Enabled: true}}
+	for _, code := range codes {
+		user.TOTP.RecoveryCodes = append(user.TOTP.RecoveryCodes, hashRecoveryCode(code))
+	}
+
+	repository := &memoryTwoFactor{user: user, challenges: map[string]entities.TwoFactorChallenge{}}
+	return NewTwoFactor(newTestJWT(t), encryption.NewEncryption("0123456789abcdef"), repository).(*twoFactor), repository, user
+}
+
+func TestTwoFactorLoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	c, repository, user := newTestTwoFactor(t, "recovery1")
+
+	challenge, err := c.Challenge(ctx, "ip", user)
+	assert.Equal(t, err, nil)
+
+	// parallel guesses are counted before they are checked
+	var wg sync.WaitGroup
+	for i := 0; i < challengeAttempts*4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = c.Login(ctx, "ip", dto.TwoFactorLogin{Token: challenge.Token, Code: "wrong"})
+		}()
+	}
+	wg.Wait()
+
+	_, _, err = c.Login(ctx, "ip", dto.TwoFactorLogin{Token: challenge.Token, Code: "recovery1"})
+	assert.Equal(t, errors.Is(err, ErrExpired), true)
+	assert.Equal(t, len(repository.challenges), 0)
+	assert.Equal(t, len(repository.user.TOTP.RecoveryCodes), 1)
+}
+
+func TestTwoFactorLoginOnce(t *testing.T) {
+	ctx := context.Background()
+	c, _, user := newTestTwoFactor(t, "recovery1", "recovery2")
+
+	challenge, err := c.Challenge(ctx, "ip", user)
+	assert.Equal(t, err, nil)
+
+	// the challenge is bound to the ip which passed the password check
+	_, _, err = c.Login(ctx, "another ip", dto.TwoFactorLogin{Token: challenge.Token, Code: "recovery1"})
+	assert.Equal(t, errors.Is(err, ErrExpired), true)
+
+	loggedIn, pair, err := c.Login(ctx, "ip", dto.TwoFactorLogin{Token: challenge.Token, Code: "recovery1"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loggedIn.Id, user.Id)
+	assert.NotEqual(t, pair.Access, "")
+
+	_, _, err = c.Login(ctx, "ip", dto.TwoFactorLogin{Token: challenge.Token, Code: "recovery2"})
+	assert.Equal(t, errors.Is(err, ErrExpired), true)
+}
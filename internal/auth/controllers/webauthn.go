@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/repositories"
+	"studyum/internal/utils/jwt"
+	"studyum/pkg/encryption"
+	entities2 "studyum/pkg/jwt/entities"
+	"studyum/pkg/webauthn"
+	"time"
+)
+
+var ErrNotValidPasskey = errors.New("not valid passkey")
+
+const passkeyChallengeTTL = time.Minute * 5
+
+type WebAuthn interface {
+	BeginRegistration(ctx context.Context, user entities.User) (entities.PasskeyCreation, error)
+	FinishRegistration(ctx context.Context, user entities.User, data dto.PasskeyRegistration) (entities.Passkey, error)
+
+	BeginLogin(ctx context.Context, data dto.PasskeyLoginOptions) (entities.PasskeyRequest, error)
+	Login(ctx context.Context, ip string, data dto.PasskeyLogin) (entities.User, entities2.TokenPair, error)
+
+	GetPasskeys(ctx context.Context, user entities.User) []entities.Passkey
+	DeletePasskey(ctx context.Context, user entities.User, id string) error
+}
+
+type webAuthn struct {
+	rp         webauthn.RelyingParty
+	jwt        jwt.JWT
+	encryption encryption.Encryption
+
+	repository repositories.WebAuthn
+}
+
+func NewWebAuthn(config webauthn.Config, jwt jwt.JWT, encryption encryption.Encryption, repository repositories.WebAuthn) WebAuthn {
+	return &webAuthn{rp: webauthn.NewRelyingParty(config), jwt: jwt, encryption: encryption, repository: repository}
+}
+
+func passkeyIDs(passkeys []entities.Passkey) [][]byte {
+	ids := make([][]byte, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		ids = append(ids, passkey.ID)
+	}
+
+	return ids
+}
+
+func (c *webAuthn) challenge(ctx context.Context, ceremony string, userID primitive.ObjectID) (entities.PasskeyChallenge, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return entities.PasskeyChallenge{}, err
+	}
+
+	value, err := webauthn.NewChallenge()
+	if err != nil {
+		return entities.PasskeyChallenge{}, err
+	}
+
+	challenge := entities.PasskeyChallenge{
+		Token:     token,
+		Ceremony:  ceremony,
+		UserID:    userID,
+		Challenge: value,
+		ExpiresAt: time.Now().Add(passkeyChallengeTTL),
+	}
+	if err = c.repository.AddChallenge(ctx, challenge); err != nil {
+		return entities.PasskeyChallenge{}, err
+	}
+
+	return challenge, nil
+}
+
+// takeChallenge returns ErrNotValidPasskey if the challenge has expired or has been answered already
+func (c *webAuthn) takeChallenge(ctx context.Context, token string, ceremony string) (entities.PasskeyChallenge, error) {
+	challenge, err := c.repository.TakeChallenge(ctx, token, ceremony)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.PasskeyChallenge{}, ErrNotValidPasskey
+	}
+
+	return challenge, err
+}
+
+func (c *webAuthn) BeginRegistration(ctx context.Context, user entities.User) (entities.PasskeyCreation, error) {
+	challenge, err := c.challenge(ctx, entities.PasskeyRegistration, user.Id)
+	if err != nil {
+		return entities.PasskeyCreation{}, err
+	}
+
+	c.encryption.Decrypt(&user)
+	webauthnUser := webauthn.User{ID: user.Id[:], Name: user.Login, DisplayName: user.StudyPlaceInfo.Name}
+	if webauthnUser.DisplayName == "" {
+		webauthnUser.DisplayName = user.Login
+	}
+
+	return entities.PasskeyCreation{
+		Token:     challenge.Token,
+		PublicKey: c.rp.CreationOptions(webauthnUser, challenge.Challenge, passkeyIDs(user.Passkeys)),
+	}, nil
+}
+
+func (c *webAuthn) FinishRegistration(ctx context.Context, user entities.User, data dto.PasskeyRegistration) (entities.Passkey, error) {
+	challenge, err := c.takeChallenge(ctx, data.Token, entities.PasskeyRegistration)
+	if err != nil {
+		return entities.Passkey{}, err
+	}
+
+	if challenge.UserID != user.Id {
+		return entities.Passkey{}, ErrNotValidPasskey
+	}
+
+	credential, err := c.rp.VerifyRegistration(challenge.Challenge, data.Credential)
+	if err != nil {
+		return entities.Passkey{}, err
+	}
+
+	passkey := entities.Passkey{
+		ID:        credential.ID,
+		Name:      data.Name,
+		PublicKey: credential.PublicKey,
+		SignCount: credential.SignCount,
+		AAGUID:    credential.AAGUID,
+		CreatedAt: time.Now(),
+	}
+	if err = c.repository.AddPasskey(ctx, user.Id, passkey); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.Passkey{}, errors.Wrap(ValidationError, "passkey is already registered")
+		}
+		return entities.Passkey{}, err
+	}
+
+	return passkey, nil
+}
+
+// BeginLogin allows passkeys of the user with the login, without a login any discoverable passkey can be used.
+// Unknown logins get discoverable options as well, so the response does not reveal registered users.
+func (c *webAuthn) BeginLogin(ctx context.Context, data dto.PasskeyLoginOptions) (entities.PasskeyRequest, error) {
+	var user entities.User
+	if data.Login != "" {
+		var err error
+		user, err = c.repository.GetUserByLogin(ctx, data.Login)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.PasskeyRequest{}, err
+		}
+	}
+
+	challenge, err := c.challenge(ctx, entities.PasskeyLogin, user.Id)
+	if err != nil {
+		return entities.PasskeyRequest{}, err
+	}
+
+	return entities.PasskeyRequest{
+		Token:     challenge.Token,
+		PublicKey: c.rp.RequestOptions(challenge.Challenge, passkeyIDs(user.Passkeys)),
+	}, nil
+}
+
+// Login verifies the assertion and issues a token pair, passkeys require user verification and replace the second factor
+func (c *webAuthn) Login(ctx context.Context, ip string, data dto.PasskeyLogin) (entities.User, entities2.TokenPair, error) {
+	challenge, err := c.takeChallenge(ctx, data.Token, entities.PasskeyLogin)
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	user, err := c.repository.GetUserByPasskey(ctx, data.Credential.RawID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, entities2.TokenPair{}, ErrNotValidPasskey
+		}
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if !challenge.UserID.IsZero() && challenge.UserID != user.Id {
+		return entities.User{}, entities2.TokenPair{}, ErrNotValidPasskey
+	}
+
+	if handle := data.Credential.Response.UserHandle; len(handle) != 0 && !bytes.Equal(handle, user.Id[:]) {
+		return entities.User{}, entities2.TokenPair{}, ErrNotValidPasskey
+	}
+
+	var passkey entities.Passkey
+	for _, p := range user.Passkeys {
+		if bytes.Equal(p.ID, data.Credential.RawID) {
+			passkey = p
+		}
+	}
+
+	signCount, err := c.rp.VerifyAssertion(challenge.Challenge, data.Credential, passkey.Credential())
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	if err = c.repository.UsePasskey(ctx, user.Id, passkey.ID, signCount, time.Now()); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.User{}, entities2.TokenPair{}, webauthn.ErrSignCount
+		}
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	pair, err := c.jwt.Create(ctx, ip, user.Id.Hex())
+	if err != nil {
+		return entities.User{}, entities2.TokenPair{}, err
+	}
+
+	c.encryption.Decrypt(&user)
+	return user, pair, nil
+}
+
+func (c *webAuthn) GetPasskeys(_ context.Context, user entities.User) []entities.Passkey {
+	if user.Passkeys == nil {
+		return []entities.Passkey{}
+	}
+
+	return user.Passkeys
+}
+
+func (c *webAuthn) DeletePasskey(ctx context.Context, user entities.User, id string) error {
+	passkeyID, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return ValidationError
+	}
+
+	return c.repository.DeletePasskey(ctx, user.Id, passkeyID)
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bytes"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/net/context"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+	jUtils "studyum/internal/utils/jwt"
+	"studyum/pkg/encryption"
+	jwt "studyum/pkg/jwt/controllers"
+	"studyum/pkg/jwt/repositories"
+	"studyum/pkg/webauthn"
+	"studyum/pkg/webauthn/webauthntest"
+	"testing"
+	"time"
+)
+
+const testOrigin = "https://studyum.net"
+
+// memoryWebAuthn keeps users and challenges in memory
+type memoryWebAuthn struct {
+	users      map[primitive.ObjectID]entities.User
+	challenges map[string]entities.PasskeyChallenge
+}
+
+func (r *memoryWebAuthn) GetUserByID(_ context.Context, id primitive.ObjectID) (entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return entities.User{}, mongo.ErrNoDocuments
+	}
+
+	return user, nil
+}
+
+func (r *memoryWebAuthn) GetUserByLogin(_ context.Context, login string) (entities.User, error) {
+	for _, user := range r.users {
+		if user.Login == login {
+			return user, nil
+		}
+	}
+
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryWebAuthn) GetUserByPasskey(_ context.Context, id []byte) (entities.User, error) {
+	for _, user := range r.users {
+		for _, passkey := range user.Passkeys {
+			if bytes.Equal(passkey.ID, id) {
+				return user, nil
+			}
+		}
+	}
+
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryWebAuthn) AddPasskey(_ context.Context, userID primitive.ObjectID, passkey entities.Passkey) error {
+	user := r.users[userID]
+	user.Passkeys = append(user.Passkeys, passkey)
+	r.users[userID] = user
+	return nil
+}
+
+func (r *memoryWebAuthn) UsePasskey(_ context.Context, userID primitive.ObjectID, id []byte, signCount uint32, usedAt time.Time) error {
+	user := r.users[userID]
+	for i, passkey := range user.Passkeys {
+		if bytes.Equal(passkey.ID, id) && passkey.SignCount <= signCount {
+			user.Passkeys[i].SignCount = signCount
+			user.Passkeys[i].LastUsedAt = &usedAt
+			return nil
+		}
+	}
+
+	return mongo.ErrNoDocuments
+}
+
+func (r *memoryWebAuthn) DeletePasskey(context.Context, primitive.ObjectID, []byte) error {
+	return nil
+}
+
+func (r *memoryWebAuthn) AddChallenge(_ context.Context, challenge entities.PasskeyChallenge) error {
+	r.challenges[challenge.Token] = challenge
+	return nil
+}
+
+func (r *memoryWebAuthn) TakeChallenge(_ context.Context, token string, ceremony string) (entities.PasskeyChallenge, error) {
+	challenge, ok := r.challenges[token]
+	if !ok || challenge.Ceremony != ceremony {
+		return entities.PasskeyChallenge{}, mongo.ErrNoDocuments
+	}
+
+	delete(r.challenges, token)
+	return challenge, nil
+}
+
+func newTestJWT(t *testing.T) jUtils.JWT {
+	server := miniredis.RunT(t)
+	sessions := repositories.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	return jwt.NewControllerWithCreateClaimsFunc[jUtils.Claims]("@daily", time.Minute, time.Hour, time.Second, "secret", sessions, func(ctx context.Context, id, userID string) (jUtils.Claims, error) {
+		return jUtils.NewClaims(id, entities.User{}), nil
+	})
+}
+
+func newTestWebAuthn(t *testing.T, user entities.User) *webAuthn {
+	j := newTestJWT(t)
+	repository := &memoryWebAuthn{users: map[primitive.ObjectID]entities.User{user.Id: user}, challenges: map[string]entities.PasskeyChallenge{}}
+	config := webauthn.Config{RPID: "studyum.net", RPName: "Studyum", Origins: []string{testOrigin}}
+
+	return NewWebAuthn(config, j, encryption.NewEncryption("0123456789abcdef"), repository).(*webAuthn)
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	ctx := context.Background()
+	user := entities.User{Id: primitive.NewObjectID(), Login: "teacher"}
+	c := newTestWebAuthn(t, user)
+	authenticator := webauthntest.New(testOrigin)
+
+	creation, err := c.BeginRegistration(ctx, user)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, []byte(creation.PublicKey.User.ID), user.Id[:])
+
+	attestation, err := authenticator.Create(creation.PublicKey)
+	assert.Equal(t, err, nil)
+
+	passkey, err := c.FinishRegistration(ctx, user, dto.PasskeyRegistration{Token: creation.Token, Name: "Laptop", Credential: attestation})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, passkey.Name, "Laptop")
+
+	// the challenge is answered once
+	_, err = c.FinishRegistration(ctx, user, dto.PasskeyRegistration{Token: creation.Token, Name: "Laptop", Credential: attestation})
+	assert.Equal(t, err, ErrNotValidPasskey)
+
+	request, err := c.BeginLogin(ctx, dto.PasskeyLoginOptions{Login: "teacher"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(request.PublicKey.AllowCredentials), 1)
+
+	assertion, err := authenticator.Get(request.PublicKey)
+	assert.Equal(t, err, nil)
+
+	loggedIn, pair, err := c.Login(ctx, "ip", dto.PasskeyLogin{Token: request.Token, Credential: assertion})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loggedIn.Id, user.Id)
+
+	userID, _, err := c.jwt.Auth(ctx, pair)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, userID, user.Id.Hex())
+
+	// a replayed assertion does not pass the challenge nor the counter
+	_, _, err = c.Login(ctx, "ip", dto.PasskeyLogin{Token: request.Token, Credential: assertion})
+	assert.Equal(t, err, ErrNotValidPasskey)
+
+	request, _ = c.BeginLogin(ctx, dto.PasskeyLoginOptions{})
+	_, _, err = c.Login(ctx, "ip", dto.PasskeyLogin{Token: request.Token, Credential: assertion})
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+}
+
+func TestPasskeyLoginOfUnknownUser(t *testing.T) {
+	ctx := context.Background()
+	c := newTestWebAuthn(t, entities.User{Id: primitive.NewObjectID(), Login: "teacher"})
+
+	request, err := c.BeginLogin(ctx, dto.PasskeyLoginOptions{Login: "unknown"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(request.PublicKey.AllowCredentials), 0)
+
+	authenticator := webauthntest.New(testOrigin)
+	_, _ = authenticator.Create(c.rp.CreationOptions(webauthn.User{ID: []byte("unknown")}, []byte("challenge"), nil))
+
+	assertion, err := authenticator.Get(request.PublicKey)
+	assert.Equal(t, err, nil)
+
+	_, _, err = c.Login(ctx, "ip", dto.PasskeyLogin{Token: request.Token, Credential: assertion})
+	assert.Equal(t, err, ErrNotValidPasskey)
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/pkg/webauthn"
+	"time"
+)
 
 type Login struct {
 	Login    string `json:"login" binding:"req"`
@@ -14,6 +18,23 @@ type SignUp struct {
 	Code     string `json:"code"`
 }
 
+type TwoFactorCode struct {
+	Code string `json:"code" binding:"req"`
+}
+
+type TwoFactorLogin struct {
+	Token string `json:"token" binding:"req"`
+	Code  string `json:"code" binding:"req"`
+}
+
+type MagicLink struct {
+	Email string `json:"email" binding:"req"`
+}
+
+type MagicLinkLogin struct {
+	Token string `json:"token" binding:"req"`
+}
+
 type SignUpWithCode struct {
 	Code string `json:"code" binding:"req"`
 }
@@ -28,3 +49,75 @@ type SignUpStage1 struct {
 	Role         string             `json:"role" binding:"req"`
 	RoleName     string             `json:"roleName" binding:"req"`
 }
+
+type OIDCClient struct {
+	Name         string   `json:"name" binding:"req"`
+	RedirectURIs []string `json:"redirectURIs" binding:"req"`
+	Public       bool     `json:"public"`
+}
+
+type OIDCAuthorize struct {
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	ResponseType        string `form:"response_type" json:"response_type"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+type OIDCConsent struct {
+	OIDCAuthorize
+	Approve bool `json:"approve"`
+}
+
+type OIDCToken struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+type ConfirmOAuth2Link struct {
+	Token string `json:"token" binding:"req"`
+}
+
+type APIToken struct {
+	Name        string     `json:"name" binding:"req"`
+	Permissions []string   `json:"permissions"`
+	AllowedIPs  []string   `json:"allowedIPs"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+type Role struct {
+	Name        string               `json:"name" binding:"req"`
+	Permissions []string             `json:"permissions"`
+	Inherits    []primitive.ObjectID `json:"inherits"`
+}
+
+type UserRoles struct {
+	Roles []primitive.ObjectID `json:"roles"`
+}
+
+type Impersonation struct {
+	UserID primitive.ObjectID `json:"userID" binding:"req"`
+	Reason string             `json:"reason" binding:"req"`
+}
+
+type PasskeyRegistration struct {
+	Token      string                       `json:"token" binding:"req"`
+	Name       string                       `json:"name" binding:"req"`
+	Credential webauthn.AttestationResponse `json:"credential"`
+}
+
+type PasskeyLoginOptions struct {
+	Login string `json:"login"`
+}
+
+type PasskeyLogin struct {
+	Token      string                     `json:"token" binding:"req"`
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
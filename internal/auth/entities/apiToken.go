@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// APITokenRole is the study place role of users resolved from api tokens
+const APITokenRole = "apiToken"
+
+// APIToken grants an integration the listed permissions of a study place,
+// the secret is hashed, empty AllowedIPs allow any address
+type APIToken struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	Name         string             `json:"name" bson:"name"`
+	SecretHash   string             `json:"-" bson:"secretHash"`
+	Permissions  []string           `json:"permissions" bson:"permissions"`
+	AllowedIPs   []string           `json:"allowedIPs" bson:"allowedIPs"`
+	ExpiresAt    *time.Time         `json:"expiresAt" bson:"expiresAt"`
+	CreatedBy    primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	LastUsedAt   *time.Time         `json:"lastUsedAt" bson:"lastUsedAt"`
+	LastUsedIP   string             `json:"lastUsedIP" bson:"lastUsedIP"`
+}
+
+// APITokenCredentials is returned once when the token is created
+type APITokenCredentials struct {
+	APIToken
+	Token string `json:"token"`
+}
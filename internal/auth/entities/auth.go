@@ -4,23 +4,36 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const GuardianRole = "guardian"
+
 type User struct {
-	Id             primitive.ObjectID `json:"id" bson:"_id"`
-	Password       string             `json:"-" bson:"password"`
-	Email          string             `json:"email" bson:"email"`
-	VerifiedEmail  bool               `json:"verifiedEmail" bson:"verifiedEmail"`
-	FirebaseToken  string             `json:"-" bson:"firebaseToken" encryption:""`
-	Login          string             `json:"login" bson:"login"`
-	PictureUrl     string             `json:"picture" bson:"picture" encryption:""`
-	StudyPlaceInfo UserStudyPlaceInfo `json:"studyPlaceInfo" bson:"studyPlaceInfo"`
+	Id             primitive.ObjectID   `json:"id" bson:"_id"`
+	Password       string               `json:"-" bson:"password"`
+	Email          string               `json:"email" bson:"email"`
+	VerifiedEmail  bool                 `json:"verifiedEmail" bson:"verifiedEmail"`
+	FirebaseToken  string               `json:"-" bson:"firebaseToken" encryption:""`
+	Login          string               `json:"login" bson:"login"`
+	PictureUrl     string               `json:"picture" bson:"picture" encryption:""`
+	StudyPlaceInfo UserStudyPlaceInfo   `json:"studyPlaceInfo" bson:"studyPlaceInfo"`
+	Children       []primitive.ObjectID `json:"children,omitempty" bson:"children,omitempty"`
+	TOTP           TOTP                 `json:"totp" bson:"totp"`
+	Identities     []OAuth2Identity     `json:"identities,omitempty" bson:"identities,omitempty"`
+	Passkeys       []Passkey            `json:"-" bson:"passkeys,omitempty"`
+	ImpersonatedBy primitive.ObjectID   `json:"impersonatedBy,omitempty" bson:"-"`
+}
+
+// Impersonated reports whether the user is acted on behalf of by an admin
+func (u User) Impersonated() bool {
+	return !u.ImpersonatedBy.IsZero()
 }
 
 type UserStudyPlaceInfo struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id"`
-	Name         string             `json:"name" bson:"name" encryption:""`
-	Role         string             `json:"role" bson:"role"`
-	RoleName     string             `json:"roleName" bson:"roleName"`
-	TuitionGroup string             `json:"tuitionGroup" bson:"tuitionGroup"`
-	Permissions  []string           `json:"permissions" bson:"permissions"`
-	Accepted     bool               `json:"accepted" bson:"accepted"`
+	ID           primitive.ObjectID   `json:"id" bson:"_id"`
+	Name         string               `json:"name" bson:"name" encryption:""`
+	Role         string               `json:"role" bson:"role"`
+	RoleName     string               `json:"roleName" bson:"roleName"`
+	TuitionGroup string               `json:"tuitionGroup" bson:"tuitionGroup"`
+	Permissions  []string             `json:"permissions" bson:"permissions"`
+	Roles        []primitive.ObjectID `json:"roles,omitempty" bson:"roles,omitempty"`
+	Accepted     bool                 `json:"accepted" bson:"accepted"`
 }
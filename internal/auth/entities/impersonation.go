@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Impersonation is an audit record of an admin acting as a member of the study place,
+// SessionID is the family of the jwt sessions issued for it
+type Impersonation struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	ActorID      primitive.ObjectID `json:"actorID" bson:"actorID"`
+	UserID       primitive.ObjectID `json:"userID" bson:"userID"`
+	SessionID    string             `json:"sessionID" bson:"sessionID"`
+	Reason       string             `json:"reason" bson:"reason"`
+	IP           string             `json:"ip" bson:"ip"`
+	StartedAt    time.Time          `json:"startedAt" bson:"startedAt"`
+	ExpiresAt    time.Time          `json:"expiresAt" bson:"expiresAt"`
+	EndedAt      *time.Time         `json:"endedAt" bson:"endedAt"`
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "golang.org/x/oauth2"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/oauth2"
+	"time"
+)
 
 type OAuth2Service struct {
 	oauth2.Config
@@ -36,3 +40,29 @@ type OAuth2CallbackUser struct {
 	Name          string `json:"name"`
 	PictureUrl    string `json:"picture"`
 }
+
+// OAuth2Identity is an account of an external provider linked to the user, Subject is the provider user id
+type OAuth2Identity struct {
+	Provider string    `json:"provider" bson:"provider"`
+	Subject  string    `json:"-" bson:"subject"`
+	Email    string    `json:"email" bson:"email"`
+	LinkedAt time.Time `json:"linkedAt" bson:"linkedAt"`
+}
+
+type OAuth2LinkType string
+
+const (
+	// OAuth2LinkRequest is started by a signed-in user, the identity is known after the provider callback
+	OAuth2LinkRequest OAuth2LinkType = "request"
+	// OAuth2LinkMerge is created when an identity email matches an account, the account owner has to confirm it
+	OAuth2LinkMerge OAuth2LinkType = "merge"
+)
+
+type OAuth2Link struct {
+	Token     string             `bson:"_id"`
+	Type      OAuth2LinkType     `bson:"type"`
+	UserID    primitive.ObjectID `bson:"userID"`
+	Identity  OAuth2Identity     `bson:"identity"`
+	Redirect  string             `bson:"redirect"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
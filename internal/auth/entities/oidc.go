@@ -0,0 +1,122 @@
+package entities
+
+import (
+	"github.com/golang-jwt/jwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// OIDCClient is an application of a study place which signs users in with Studyum,
+// clients without a secret are public and rely on PKCE only
+type OIDCClient struct {
+	ID           string             `json:"id" bson:"_id"`
+	SecretHash   string             `json:"-" bson:"secretHash"`
+	Name         string             `json:"name" bson:"name"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	RedirectURIs []string           `json:"redirectURIs" bson:"redirectURIs"`
+	Public       bool               `json:"public" bson:"public"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// OIDCClientCredentials is returned once when the client is registered
+type OIDCClientCredentials struct {
+	OIDCClient
+	Secret string `json:"secret,omitempty"`
+}
+
+type OIDCConsent struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	UserID   primitive.ObjectID `json:"userID" bson:"userID"`
+	ClientID string             `json:"clientID" bson:"clientID"`
+	Scopes   []string           `json:"scopes" bson:"scopes"`
+}
+
+type OIDCAuthorizationCode struct {
+	CodeHash      string             `bson:"_id"`
+	ClientID      string             `bson:"clientID"`
+	UserID        primitive.ObjectID `bson:"userID"`
+	RedirectURI   string             `bson:"redirectURI"`
+	Scopes        []string           `bson:"scopes"`
+	Nonce         string             `bson:"nonce"`
+	CodeChallenge string             `bson:"codeChallenge"`
+	AuthTime      time.Time          `bson:"authTime"`
+	ExpiresAt     time.Time          `bson:"expiresAt"`
+}
+
+type OIDCAccessToken struct {
+	TokenHash string             `bson:"_id"`
+	ClientID  string             `bson:"clientID"`
+	UserID    primitive.ObjectID `bson:"userID"`
+	Scopes    []string           `bson:"scopes"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+// OIDCAuthorization describes the consent screen of an authorization request
+type OIDCAuthorization struct {
+	Client    OIDCClient `json:"client"`
+	Scopes    []string   `json:"scopes"`
+	Consented bool       `json:"consented"`
+}
+
+type OIDCRedirect struct {
+	Redirect string `json:"redirect"`
+}
+
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+type OIDCStudyPlace struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	RoleName string `json:"role_name"`
+	Group    string `json:"group"`
+}
+
+// OIDCUserInfo holds claims of the user released for the granted scopes
+type OIDCUserInfo struct {
+	Subject       string          `json:"sub"`
+	Name          string          `json:"name,omitempty"`
+	Picture       string          `json:"picture,omitempty"`
+	Email         string          `json:"email,omitempty"`
+	EmailVerified *bool           `json:"email_verified,omitempty"`
+	StudyPlace    *OIDCStudyPlace `json:"study_place,omitempty"`
+}
+
+// OIDCIDToken lists registered claims itself as jwt.StandardClaims would clash with the sub claim of the user info
+type OIDCIDToken struct {
+	OIDCUserInfo
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+	AuthTime  int64  `json:"auth_time"`
+	Nonce     string `json:"nonce,omitempty"`
+}
+
+func (t OIDCIDToken) Valid() error {
+	claims := jwt.StandardClaims{Issuer: t.Issuer, Audience: t.Audience, ExpiresAt: t.ExpiresAt, IssuedAt: t.IssuedAt, Subject: t.Subject}
+	return claims.Valid()
+}
+
+// OIDCConfiguration is the OpenID provider metadata served at /.well-known/openid-configuration
+type OIDCConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
@@ -0,0 +1,154 @@
+package entities
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	ScopeTeacher = "teacher"
+	ScopeGroup   = "group"
+
+	// ScopeSelf is replaced with the user teacher name or tuition group when the scope is checked
+	ScopeSelf = "self"
+)
+
+var ErrNotValidPermission = errors.New("not valid permission")
+
+// Resource is a lesson, a journal or a group schedule scoped permissions are checked against
+type Resource struct {
+	Teacher string
+	Group   string
+}
+
+// PermissionGrant is a parsed permission, permissions are written as name or name:scope=value,value;scope=value.
+// A grant without scopes applies to the whole study place, values of a scope are alternatives
+// and every scope of the grant has to match
+type PermissionGrant struct {
+	Name     string
+	Teachers []string
+	Groups   []string
+}
+
+func ParsePermission(permission string) (PermissionGrant, error) {
+	name, scopes, scoped := strings.Cut(permission, ":")
+	grant := PermissionGrant{Name: name}
+	if name == "" {
+		return PermissionGrant{}, errors.Wrap(ErrNotValidPermission, permission)
+	}
+
+	if !scoped {
+		return grant, nil
+	}
+
+	for _, scope := range strings.Split(scopes, ";") {
+		key, rawValues, found := strings.Cut(scope, "=")
+		if !found || rawValues == "" {
+			return PermissionGrant{}, errors.Wrap(ErrNotValidPermission, permission)
+		}
+
+		values := strings.Split(rawValues, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		switch strings.TrimSpace(key) {
+		case ScopeTeacher:
+			grant.Teachers = append(grant.Teachers, values...)
+		case ScopeGroup:
+			grant.Groups = append(grant.Groups, values...)
+		default:
+			return PermissionGrant{}, errors.Wrap(ErrNotValidPermission, permission)
+		}
+	}
+
+	return grant, nil
+}
+
+func (g PermissionGrant) Scoped() bool {
+	return len(g.Teachers) != 0 || len(g.Groups) != 0
+}
+
+// Allows reports whether the grant covers the resource for the user, self values are resolved with the user info
+func (g PermissionGrant) Allows(user User, resource Resource) bool {
+	if len(g.Teachers) != 0 && !scopeMatches(g.Teachers, resource.Teacher, user.StudyPlaceInfo.RoleName) {
+		return false
+	}
+
+	if len(g.Groups) != 0 && !scopeMatches(g.Groups, resource.Group, user.StudyPlaceInfo.TuitionGroup) {
+		return false
+	}
+
+	return true
+}
+
+func scopeMatches(values []string, value string, self string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, v := range values {
+		if v == value || (v == ScopeSelf && self != "" && self == value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grants returns parsed user permissions with the name, not valid permissions are skipped
+func (u User) grants(name string) []PermissionGrant {
+	grants := make([]PermissionGrant, 0, 1)
+	for _, permission := range u.StudyPlaceInfo.Permissions {
+		grant, err := ParsePermission(permission)
+		if err == nil && grant.Name == name {
+			grants = append(grants, grant)
+		}
+	}
+
+	return grants
+}
+
+// HasPermission reports whether the user has the permission for at least some resources
+func (u User) HasPermission(name string) bool {
+	return len(u.grants(name)) != 0
+}
+
+// HasGlobalPermission reports whether the user has the permission without any scope
+func (u User) HasGlobalPermission(name string) bool {
+	for _, grant := range u.grants(name) {
+		if !grant.Scoped() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Can reports whether any of the user grants of the permission covers the resource
+func (u User) Can(name string, resource Resource) bool {
+	for _, grant := range u.grants(name) {
+		if grant.Allows(u, resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Covers reports whether the user may hand the permission out to roles or api tokens,
+// which requires the same permission or the permission without scopes
+func (u User) Covers(permission string) bool {
+	grant, err := ParsePermission(permission)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range u.StudyPlaceInfo.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return u.HasGlobalPermission(grant.Name)
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestParsePermission(t *testing.T) {
+	grant, err := ParsePermission("editJournal")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, grant, PermissionGrant{Name: "editJournal"})
+	assert.Equal(t, grant.Scoped(), false)
+
+	grant, err = ParsePermission("editSchedule:group=A, B;teacher=self")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, grant, PermissionGrant{Name: "editSchedule", Groups: []string{"A", "B"}, Teachers: []string{ScopeSelf}})
+	assert.Equal(t, grant.Scoped(), true)
+
+	for _, permission := range []string{"", ":group=A", "editJournal:group", "editJournal:group=", "editJournal:room=1"} {
+		_, err = ParsePermission(permission)
+		assert.Equal(t, errors.Is(err, ErrNotValidPermission), true)
+	}
+}
+
+func TestUserCan(t *testing.T) {
+	teacher := User{StudyPlaceInfo: UserStudyPlaceInfo{
+		Role:         "teacher",
+		RoleName:     "Ivanov",
+		TuitionGroup: "95T",
+		Permissions:  []string{"editJournal:teacher=self", "editSchedule:group=95T,96T", "curator:group=self"},
+	}}
+
+	assert.Equal(t, teacher.HasPermission(PermissionEditJournal), true)
+	assert.Equal(t, teacher.HasGlobalPermission(PermissionEditJournal), false)
+	assert.Equal(t, teacher.HasPermission(PermissionManageUsers), false)
+
+	assert.Equal(t, teacher.Can(PermissionEditJournal, Resource{Teacher: "Ivanov", Group: "10A"}), true)
+	assert.Equal(t, teacher.Can(PermissionEditJournal, Resource{Teacher: "Petrov", Group: "95T"}), false)
+	assert.Equal(t, teacher.Can(PermissionEditSchedule, Resource{Teacher: "Petrov", Group: "96T"}), true)
+	assert.Equal(t, teacher.Can(PermissionEditSchedule, Resource{Teacher: "Ivanov", Group: "10A"}), false)
+	assert.Equal(t, teacher.Can(PermissionCurator, Resource{Group: "95T"}), true)
+	assert.Equal(t, teacher.Can(PermissionCurator, Resource{Group: "96T"}), false)
+
+	admin := User{StudyPlaceInfo: UserStudyPlaceInfo{Permissions: []string{"editJournal", "editJournal:teacher=Ivanov"}}}
+	assert.Equal(t, admin.Can(PermissionEditJournal, Resource{Teacher: "Petrov", Group: "95T"}), true)
+	assert.Equal(t, admin.HasGlobalPermission(PermissionEditJournal), true)
+}
+
+func TestUserCovers(t *testing.T) {
+	user := User{StudyPlaceInfo: UserStudyPlaceInfo{Permissions: []string{"editJournal", "editSchedule:group=95T"}}}
+
+	assert.Equal(t, user.Covers("editJournal"), true)
+	assert.Equal(t, user.Covers("editJournal:teacher=Petrov"), true)
+	assert.Equal(t, user.Covers("editSchedule:group=95T"), true)
+	assert.Equal(t, user.Covers("editSchedule:group=96T"), false)
+	assert.Equal(t, user.Covers("editSchedule"), false)
+	assert.Equal(t, user.Covers("editJournal:room=1"), false)
+}
@@ -0,0 +1,45 @@
+package entities
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	PermissionEditSchedule      = "editSchedule"
+	PermissionEditJournal       = "editJournal"
+	PermissionViewJournals      = "viewJournals"
+	PermissionManageUsers       = "manageUsers"
+	PermissionCurator           = "curator"
+	PermissionManageRoles       = "manageRoles"
+	PermissionManageOIDCClients = "manageOIDCClients"
+	PermissionManageAPITokens   = "manageAPITokens"
+	PermissionImpersonateUsers  = "impersonateUsers"
+	PermissionManageApps        = "manageApps"
+)
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PermissionCatalogue lists every permission which can be granted by roles,
+// editSchedule, editJournal and curator may be limited to teachers and groups with scopes, see ParsePermission
+var PermissionCatalogue = []Permission{
+	{Name: PermissionEditSchedule, Description: "Edit lessons and the general schedule"},
+	{Name: PermissionEditJournal, Description: "Edit marks, absences and lesson info"},
+	{Name: PermissionViewJournals, Description: "View journals of every group"},
+	{Name: PermissionManageUsers, Description: "Create sign up codes, accept and block users"},
+	{Name: PermissionCurator, Description: "Invite guardians of students of the group"},
+	{Name: PermissionManageRoles, Description: "Edit roles and assign them to users"},
+	{Name: PermissionManageOIDCClients, Description: "Register applications which sign in with Studyum"},
+	{Name: PermissionManageAPITokens, Description: "Create and revoke api tokens for integrations"},
+	{Name: PermissionImpersonateUsers, Description: "Sign in as members of the study place to reproduce their view"},
+	{Name: PermissionManageApps, Description: "Inspect and replay changes which failed to reach applications"},
+}
+
+// Role is a named permission set of a study place, permissions of the inherited roles are included
+type Role struct {
+	ID           primitive.ObjectID   `json:"id" bson:"_id"`
+	StudyPlaceID primitive.ObjectID   `json:"studyPlaceID" bson:"studyPlaceID"`
+	Name         string               `json:"name" bson:"name"`
+	Permissions  []string             `json:"permissions" bson:"permissions"`
+	Inherits     []primitive.ObjectID `json:"inherits" bson:"inherits"`
+}
@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+type Session struct {
+	ID      string    `json:"id"`
+	IP      string    `json:"ip"`
+	Expire  time.Time `json:"expire"`
+	Current bool      `json:"current"`
+}
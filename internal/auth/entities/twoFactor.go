@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type TOTP struct {
+	Secret        string   `json:"-" bson:"secret" encryption:""`
+	Enabled       bool     `json:"enabled" bson:"enabled"`
+	LastStep      int64    `json:"-" bson:"lastStep"`
+	RecoveryCodes []string `json:"-" bson:"recoveryCodes"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorChallenge struct {
+	Token     string             `json:"token" bson:"_id"`
+	UserID    primitive.ObjectID `json:"-" bson:"userID"`
+	IP        string             `json:"-" bson:"ip"`
+	Attempts  int                `json:"-" bson:"attempts"`
+	ExpiresAt time.Time          `json:"expiresAt" bson:"expiresAt"`
+}
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/pkg/webauthn"
+	"time"
+)
+
+// Passkey is a WebAuthn credential of the user, the public key is COSE encoded
+type Passkey struct {
+	ID         webauthn.Bytes `json:"id" bson:"id"`
+	Name       string         `json:"name" bson:"name"`
+	PublicKey  []byte         `json:"-" bson:"publicKey"`
+	SignCount  uint32         `json:"-" bson:"signCount"`
+	AAGUID     []byte         `json:"-" bson:"aaguid"`
+	CreatedAt  time.Time      `json:"createdAt" bson:"createdAt"`
+	LastUsedAt *time.Time     `json:"lastUsedAt" bson:"lastUsedAt"`
+}
+
+func (p Passkey) Credential() webauthn.Credential {
+	return webauthn.Credential{ID: p.ID, PublicKey: p.PublicKey, SignCount: p.SignCount, AAGUID: p.AAGUID}
+}
+
+const (
+	PasskeyRegistration = "registration"
+	PasskeyLogin        = "login"
+)
+
+// PasskeyChallenge keeps the state of a ceremony until it is finished, UserID is empty for discoverable logins
+type PasskeyChallenge struct {
+	Token     string             `bson:"_id"`
+	Ceremony  string             `bson:"ceremony"`
+	UserID    primitive.ObjectID `bson:"userID"`
+	Challenge []byte             `bson:"challenge"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+type PasskeyCreation struct {
+	Token     string                   `json:"token"`
+	PublicKey webauthn.CreationOptions `json:"publicKey"`
+}
+
+type PasskeyRequest struct {
+	Token     string                  `json:"token"`
+	PublicKey webauthn.RequestOptions `json:"publicKey"`
+}
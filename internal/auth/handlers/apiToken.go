@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+)
+
+type APIToken struct {
+	Middleware
+
+	controller controllers.APIToken
+
+	Group *gin.RouterGroup
+}
+
+func NewAPIToken(middleware Middleware, controller controllers.APIToken, group *gin.RouterGroup) *APIToken {
+	h := &APIToken{Middleware: middleware, controller: controller, Group: group}
+
+	group.Use(h.MemberAuth(entities.PermissionManageAPITokens), h.NotImpersonated())
+
+	group.GET("", h.GetTokens)
+	group.POST("", h.CreateToken)
+	group.DELETE(":id", h.DeleteToken)
+
+	return h
+}
+
+// GetTokens godoc
+// @Router /api-tokens [get]
+func (h *APIToken) GetTokens(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	tokens, err := h.controller.GetTokens(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken godoc
+// @Param data body dto.APIToken true "Token"
+// @Router /api-tokens [post]
+func (h *APIToken) CreateToken(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.APIToken
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.controller.CreateToken(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// DeleteToken godoc
+// @Param id path string true "Token ID"
+// @Router /api-tokens/{id} [delete]
+func (h *APIToken) DeleteToken(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.DeleteToken(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -2,10 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
-	"google.golang.org/grpc"
-	_ "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"net/http"
@@ -19,28 +18,31 @@ type Auth struct {
 	Middleware
 
 	controller controllers.Auth
+	twoFactor  controllers.TwoFactor
 
 	Group *gin.RouterGroup
 }
 
-func NewAuth(middleware Middleware, controller controllers.Auth, group *gin.RouterGroup, grpcServer *grpc.Server) *Auth {
-	h := &Auth{Middleware: middleware, controller: controller, Group: group}
-
-	protoauth.RegisterAuthServer(grpcServer, h)
+func NewAuth(middleware Middleware, controller controllers.Auth, twoFactor controllers.TwoFactor, limit gin.HandlerFunc, group *gin.RouterGroup) *Auth {
+	h := &Auth{Middleware: middleware, controller: controller, twoFactor: twoFactor, Group: group}
 
 	group.PUT("updateToken", h.UpdateByRefreshToken)
 
-	group.PUT("login", h.Login)
+	group.PUT("login", limit, h.Login)
+	group.POST("login/magic", limit, h.SendMagicLink)
+	group.PUT("login/magic", limit, h.LoginViaMagicLink)
 
 	group.POST("signup", h.SignUp)
-	group.PUT("signup/stage1", h.Auth(), h.SignUpUserStage1)
-	group.POST("signup/code", h.Auth(), h.SignUpStage1ViaCode)
+	group.PUT("signup/stage1", h.Auth(), h.NotImpersonated(), h.SignUpUserStage1)
+	group.POST("signup/code", h.Auth(), h.NotImpersonated(), h.SignUpStage1ViaCode)
 	group.DELETE("signout", h.Auth(), h.SignOut)
 
-	group.POST("email/confirm", h.Auth(), h.ConfirmEmail)
-	group.POST("email/resendCode", h.Auth(), h.ResendEmailCode)
+	group.POST("email/confirm", h.Auth(), h.NotImpersonated(), limit, h.ConfirmEmail)
+	group.POST("email/resendCode", h.Auth(), h.NotImpersonated(), h.ResendEmailCode)
 
-	group.DELETE("sessions", h.Auth(), h.TerminateAllSessions)
+	group.GET("sessions", h.Auth(), h.GetSessions)
+	group.DELETE("sessions/:id", h.Auth(), h.NotImpersonated(), h.TerminateSession)
+	group.DELETE("sessions", h.Auth(), h.NotImpersonated(), h.TerminateAllSessions)
 
 	return h
 }
@@ -74,6 +76,64 @@ func (h *Auth) Login(ctx *gin.Context) {
 	}
 
 	user, pair, err := h.controller.Login(ctx, ctx.ClientIP(), data)
+	if errors.Is(err, controllers.ErrTwoFactorRequired) {
+		challenge, err := h.twoFactor.Challenge(ctx, ctx.ClientIP(), user)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, challenge)
+		return
+	}
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.SetTokenPairCookie(ctx, pair)
+	h.SetTokenPairHeader(ctx, pair)
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// SendMagicLink godoc
+// @Router /login/magic [post]
+func (h *Auth) SendMagicLink(ctx *gin.Context) {
+	var data dto.MagicLink
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.controller.SendMagicLink(ctx, data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// LoginViaMagicLink godoc
+// @Router /login/magic [put]
+func (h *Auth) LoginViaMagicLink(ctx *gin.Context) {
+	var data dto.MagicLinkLogin
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, pair, err := h.controller.LoginViaMagicLink(ctx, ctx.ClientIP(), data)
+	if errors.Is(err, controllers.ErrTwoFactorRequired) {
+		challenge, err := h.twoFactor.Challenge(ctx, ctx.ClientIP(), user)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, challenge)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -193,19 +253,53 @@ func (h *Auth) ResendEmailCode(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// GetSessions godoc
+// @Router /sessions [get]
+func (h *Auth) GetSessions(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	sessions, err := h.controller.GetSessions(ctx, user, h.GetTokenPair(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// TerminateSession godoc
+// @Param id path string true "Session ID"
+// @Router /sessions/{id} [delete]
+func (h *Auth) TerminateSession(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+	if err := h.controller.TerminateSession(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 // TerminateAllSessions godoc
 // @Router /sessions [delete]
 func (h *Auth) TerminateAllSessions(ctx *gin.Context) {
 	user := h.GetUser(ctx)
-	if err := h.controller.TerminateAll(ctx, user); err != nil {
+	if err := h.controller.TerminateAll(ctx, user, h.GetTokenPair(ctx)); err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 
-	h.DeleteTokenPairCookie(ctx)
 	ctx.Status(http.StatusNoContent)
 }
 
+// JWKS serves public keys to verify access tokens, it is mounted at /.well-known/jwks.json
+func (h *Auth) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=600")
+	ctx.JSON(http.StatusOK, h.controller.JWKS(ctx))
+}
+
+// AuthUser checks the tokens for other services, the request has no resource to check scoped permissions against,
+// so the required permissions have to be granted for the whole study place
 func (h *Auth) AuthUser(ctx context.Context, request *protoauth.AuthRequest) (*protoauth.AuthResponse, error) {
 	pair, update, user, err := h.GrpcAuth(ctx, entities.TokenPair{
 		Access:  request.Jwt.Access,
@@ -217,14 +311,7 @@ func (h *Auth) AuthUser(ctx context.Context, request *protoauth.AuthRequest) (*p
 
 	successfully := true
 	for _, requiredPermission := range request.RequiredPermissions {
-		found := false
-		for _, permission := range user.StudyPlaceInfo.Permissions {
-			if requiredPermission == permission {
-				found = true
-				break
-			}
-		}
-		if !found {
+		if !user.HasGlobalPermission(requiredPermission) {
 			successfully = false
 			break
 		}
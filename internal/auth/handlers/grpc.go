@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/entities"
+	entities2 "studyum/pkg/jwt/entities"
+)
+
+// grpcUserKey keeps the authenticated user in the context of a grpc call
+type grpcUserKey struct{}
+
+type grpcMethod struct {
+	public      bool
+	permissions []string
+	apiToken    bool
+}
+
+// grpcStream replaces the context of a stream with the one holding the authenticated user
+type grpcStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *grpcStream) Context() context.Context {
+	return s.ctx
+}
+
+// GrpcPublic lets anyone call the full method name, /Service/* allows every method of the service.
+// Methods which are not registered by GrpcPublic, GrpcMemberAuth or GrpcApiTokenAuth are denied.
+func (h *middleware) GrpcPublic(method string) {
+	h.grpcLock.Lock()
+	defer h.grpcLock.Unlock()
+
+	h.grpcMethods[method] = grpcMethod{public: true}
+}
+
+// GrpcMemberAuth requires calls of the full method name, e.g. /Schedule/GetSchedule,
+// to be made by a study place member with the permissions
+func (h *middleware) GrpcMemberAuth(method string, permissions ...string) {
+	h.grpcLock.Lock()
+	defer h.grpcLock.Unlock()
+
+	h.grpcMethods[method] = grpcMethod{permissions: permissions}
+}
+
+// GrpcApiTokenAuth is GrpcMemberAuth for methods meant for applications only, which must pass the apitoken metadata
+func (h *middleware) GrpcApiTokenAuth(method string, permissions ...string) {
+	h.grpcLock.Lock()
+	defer h.grpcLock.Unlock()
+
+	h.grpcMethods[method] = grpcMethod{permissions: permissions, apiToken: true}
+}
+
+// GrpcInterceptor authenticates calls by the authorization and refresh metadata or by the apitoken one,
+// the same way MemberAuth does for http requests
+func (h *middleware) GrpcInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := h.grpcAuth(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// GrpcStreamInterceptor is GrpcInterceptor for streaming calls
+func (h *middleware) GrpcStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := h.grpcAuth(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &grpcStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// grpcMethod returns the registration of the full method or of its whole service
+func (h *middleware) grpcMethod(fullMethod string) (grpcMethod, bool) {
+	h.grpcLock.RLock()
+	defer h.grpcLock.RUnlock()
+
+	if method, ok := h.grpcMethods[fullMethod]; ok {
+		return method, true
+	}
+
+	if i := strings.LastIndexByte(fullMethod, '/'); i > 0 {
+		method, ok := h.grpcMethods[fullMethod[:i]+"/*"]
+		return method, ok
+	}
+
+	return grpcMethod{}, false
+}
+
+// grpcAuth denies methods nobody registered, so a handler can't ship unauthenticated by mistake
+func (h *middleware) grpcAuth(ctx context.Context, fullMethod string) (context.Context, error) {
+	method, ok := h.grpcMethod(fullMethod)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "method "+fullMethod+" is not registered")
+	}
+
+	if method.public {
+		return ctx, nil
+	}
+
+	user, err := h.grpcMemberAuth(ctx, method)
+	if err != nil {
+		if errors.Is(err, controllers.ForbiddenErr) || errors.Is(err, controllers.ErrTwoFactorRequired) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(ctx, grpcUserKey{}, user), nil
+}
+
+func (h *middleware) grpcMemberAuth(ctx context.Context, method grpcMethod) (entities.User, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+
+	permissions := method.permissions
+	if apiToken := metadataValue(md, "apitoken"); apiToken != "" {
+		return h.controller.AuthViaApiToken(ctx, apiToken, ip, permissions...)
+	}
+
+	if method.apiToken {
+		return entities.User{}, controllers.ErrNotValidAPIToken
+	}
+
+	pair := entities2.TokenPair{Access: metadataValue(md, "authorization"), Refresh: metadataValue(md, "refresh")}
+	newPair, update, user, err := h.controller.MemberAuth(ctx, pair, ip, permissions...)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	if update {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("setaccesstoken", newPair.Access, "setrefreshtoken", newPair.Refresh))
+	}
+
+	return user, nil
+}
+
+// GetGrpcUser returns the user authenticated by GrpcInterceptor
+func (h *middleware) GetGrpcUser(ctx context.Context) entities.User {
+	user, _ := ctx.Value(grpcUserKey{}).(entities.User)
+	return user
+}
+
+func metadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) != 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestGrpcAuthDeniesUnregisteredMethods(t *testing.T) {
+	h := NewMiddleware(nil).(*middleware)
+	h.GrpcPublic("/Auth/AuthUser")
+	h.GrpcPublic("/grpc.health.v1.Health/*")
+
+	_, err := h.grpcAuth(context.Background(), "/Auth/AuthUser")
+	assert.Equal(t, err, nil)
+
+	_, err = h.grpcAuth(context.Background(), "/grpc.health.v1.Health/Watch")
+	assert.Equal(t, err, nil)
+
+	_, err = h.grpcAuth(context.Background(), "/Auth/Unknown")
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	_, err = h.grpcAuth(context.Background(), "/Schedule/GetLesson")
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
@@ -0,0 +1,88 @@
+// Package grpctest serves grpc handlers over an in-memory connection behind the auth interceptors,
+// so tests of other modules can check which of their calls are authenticated
+package grpctest
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/entities"
+	"studyum/internal/auth/handlers"
+	entities2 "studyum/pkg/jwt/entities"
+	"testing"
+)
+
+var ErrNotValidToken = errors.New("not valid access token")
+
+// Users authenticates access tokens as the users, it checks membership and permissions like the auth controller does
+type Users map[string]entities.User
+
+func (u Users) Auth(_ context.Context, pair entities2.TokenPair, _ string, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
+	return u.auth(pair, false, permissions)
+}
+
+func (u Users) MemberAuth(_ context.Context, pair entities2.TokenPair, _ string, permissions ...string) (entities2.TokenPair, bool, entities.User, error) {
+	return u.auth(pair, true, permissions)
+}
+
+func (u Users) auth(pair entities2.TokenPair, member bool, permissions []string) (entities2.TokenPair, bool, entities.User, error) {
+	user, ok := u[pair.Access]
+	if !ok {
+		return entities2.TokenPair{}, false, entities.User{}, ErrNotValidToken
+	}
+
+	for _, permission := range permissions {
+		if !user.HasPermission(permission) {
+			return entities2.TokenPair{}, false, entities.User{}, controllers.ForbiddenErr
+		}
+	}
+
+	if member && !user.StudyPlaceInfo.Accepted {
+		return entities2.TokenPair{}, false, entities.User{}, controllers.ForbiddenErr
+	}
+
+	return pair, false, user, nil
+}
+
+func (u Users) AuthViaApiToken(context.Context, string, string, ...string) (entities.User, error) {
+	return entities.User{}, controllers.ErrNotValidAPIToken
+}
+
+func (u Users) ResolvePermissions(_ context.Context, user entities.User) (entities.User, error) {
+	return user, nil
+}
+
+// Serve registers handlers on a server with the auth interceptors of users and returns a connection to it,
+// both are closed when the test ends
+func Serve(t *testing.T, users Users, register func(server *grpc.Server, middleware handlers.Middleware)) *grpc.ClientConn {
+	middleware := handlers.NewMiddleware(users)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(middleware.GrpcInterceptor()),
+		grpc.StreamInterceptor(middleware.GrpcStreamInterceptor()),
+	)
+	register(server, middleware)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// Context returns the context of a call made with the access token
+func Context(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+)
+
+type Impersonation struct {
+	Middleware
+
+	controller controllers.Impersonation
+
+	Group *gin.RouterGroup
+}
+
+func NewImpersonation(middleware Middleware, controller controllers.Impersonation, group *gin.RouterGroup) *Impersonation {
+	h := &Impersonation{Middleware: middleware, controller: controller, Group: group}
+
+	group.POST("", h.MemberAuth(entities.PermissionImpersonateUsers), h.NotImpersonated(), h.Start)
+	group.DELETE("", h.Auth(), h.Stop)
+	group.GET("log", h.MemberAuth(entities.PermissionImpersonateUsers), h.NotImpersonated(), h.GetLog)
+
+	return h
+}
+
+// Start godoc
+// @Param data body dto.Impersonation true "Impersonation"
+// @Router /impersonation [post]
+func (h *Impersonation) Start(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.Impersonation
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pair, err := h.controller.Start(ctx, user, ctx.ClientIP(), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pair)
+}
+
+// Stop godoc
+// @Router /impersonation [delete]
+func (h *Impersonation) Stop(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.Stop(ctx, user, h.GetTokenPair(ctx)); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetLog godoc
+// @Router /impersonation/log [get]
+func (h *Impersonation) GetLog(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	log, err := h.controller.GetLog(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, log)
+}
@@ -3,32 +3,46 @@ package handlers
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 	"studyum/internal/auth/controllers"
 	"studyum/internal/auth/entities"
 	"studyum/internal/utils"
 	entities2 "studyum/pkg/jwt/entities"
+	"sync"
 )
 
 type Middleware interface {
 	GrpcAuth(ctx context.Context, pair entities2.TokenPair) (entities2.TokenPair, bool, entities.User, error)
+	GrpcInterceptor() grpc.UnaryServerInterceptor
+	GrpcStreamInterceptor() grpc.StreamServerInterceptor
+	GrpcPublic(method string)
+	GrpcMemberAuth(method string, permissions ...string)
+	GrpcApiTokenAuth(method string, permissions ...string)
+	GetGrpcUser(ctx context.Context) entities.User
 
 	Auth() gin.HandlerFunc
 	TryAuth() gin.HandlerFunc
 	MemberAuth(permissions ...string) gin.HandlerFunc
+	NotImpersonated() gin.HandlerFunc
 
 	SetTokenPairCookie(ctx *gin.Context, pair entities2.TokenPair)
 	SetTokenPairHeader(ctx *gin.Context, pair entities2.TokenPair)
 	DeleteTokenPairCookie(ctx *gin.Context)
+	GetTokenPair(ctx *gin.Context) entities2.TokenPair
 
 	GetUser(ctx *gin.Context) entities.User
+	ResolvePermissions(ctx context.Context, user entities.User) (entities.User, error)
 }
 
 type middleware struct {
 	controller controllers.Middleware
+
+	grpcMethods map[string]grpcMethod
+	grpcLock    sync.RWMutex
 }
 
 func NewMiddleware(controller controllers.Middleware) Middleware {
-	return &middleware{controller: controller}
+	return &middleware{controller: controller, grpcMethods: map[string]grpcMethod{}}
 }
 
 func (h *middleware) SetTokenPairCookie(ctx *gin.Context, pair entities2.TokenPair) {
@@ -46,6 +60,10 @@ func (h *middleware) DeleteTokenPairCookie(ctx *gin.Context) {
 	ctx.SetCookie("access", "", 0, "/", "", true, true)
 }
 
+func (h *middleware) GetTokenPair(ctx *gin.Context) entities2.TokenPair {
+	return h.tokenPair(ctx)
+}
+
 func (h *middleware) tokenPair(ctx *gin.Context) entities2.TokenPair {
 	access := ctx.GetHeader("Authorization")
 	if access != "" {
@@ -64,13 +82,13 @@ func (h *middleware) tokenPair(ctx *gin.Context) entities2.TokenPair {
 	return entities2.TokenPair{Access: access, Refresh: refresh}
 }
 
-func (h *middleware) authViaApiToken(ctx *gin.Context) bool {
+func (h *middleware) authViaApiToken(ctx *gin.Context, permissions ...string) bool {
 	apiToken := ctx.GetHeader("ApiToken")
 	if apiToken == "" {
 		return false
 	}
 
-	user, err := h.controller.AuthViaApiToken(ctx, apiToken)
+	user, err := h.controller.AuthViaApiToken(ctx, apiToken, ctx.ClientIP(), permissions...)
 	if err != nil {
 		_ = ctx.Error(err)
 		ctx.Abort()
@@ -138,7 +156,7 @@ func (h *middleware) TryAuth() gin.HandlerFunc {
 
 func (h *middleware) MemberAuth(permissions ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if h.authViaApiToken(ctx) {
+		if h.authViaApiToken(ctx, permissions...) {
 			return
 		}
 
@@ -166,6 +184,23 @@ func (h *middleware) MemberAuth(permissions ...string) gin.HandlerFunc {
 	}
 }
 
+// NotImpersonated blocks sensitive actions of impersonation sessions, it goes after the authentication
+func (h *middleware) NotImpersonated() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if h.GetUser(ctx).Impersonated() {
+			_ = ctx.Error(controllers.ErrImpersonating)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func (h *middleware) ResolvePermissions(ctx context.Context, user entities.User) (entities.User, error) {
+	return h.controller.ResolvePermissions(ctx, user)
+}
+
 func (h *middleware) GetUser(ctx *gin.Context) entities.User {
 	return utils.GetViaCtx[entities.User](ctx, "user")
 }
@@ -1,26 +1,35 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
 )
 
 type OAuth2 struct {
 	Middleware
 
 	controller controllers.OAuth2
+	twoFactor  controllers.TwoFactor
 
 	Group *gin.RouterGroup
 }
 
-func NewOAuth2(middleware Middleware, controller controllers.OAuth2, group *gin.RouterGroup) *OAuth2 {
-	h := &OAuth2{Middleware: middleware, controller: controller, Group: group}
+func NewOAuth2(middleware Middleware, controller controllers.OAuth2, twoFactor controllers.TwoFactor, group *gin.RouterGroup) *OAuth2 {
+	h := &OAuth2{Middleware: middleware, controller: controller, twoFactor: twoFactor, Group: group}
 
 	group.GET(":service", h.Auth)
 	group.GET("/callback/:service", h.Receive)
 	group.POST("/token", h.SetToken)
 
+	group.GET("/link/:service", h.Middleware.Auth(), h.NotImpersonated(), h.Link)
+	group.GET("/identities", h.Middleware.Auth(), h.GetIdentities)
+	group.POST("/identities/confirm", h.Middleware.Auth(), h.NotImpersonated(), h.ConfirmLink)
+	group.DELETE("/identities/:service", h.Middleware.Auth(), h.NotImpersonated(), h.Unlink)
+
 	return h
 }
 
@@ -46,14 +55,41 @@ func (h *OAuth2) Auth(ctx *gin.Context) {
 func (h *OAuth2) Receive(ctx *gin.Context) {
 	service := ctx.Param("service")
 	code := ctx.Query("code")
+	state := ctx.Query("state")
+
+	if strings.HasPrefix(state, controllers.OAuth2LinkStatePrefix) {
+		redirect, err := h.controller.LinkIdentity(ctx, service, code, state)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
 
-	pair, err := h.controller.ReceiveUser(ctx, service, code)
+		ctx.Redirect(http.StatusTemporaryRedirect, redirect+"/?linked="+service)
+		return
+	}
+
+	user, pair, err := h.controller.ReceiveUser(ctx, service, code)
+	var confirmErr controllers.ConfirmLinkError
+	if errors.As(err, &confirmErr) {
+		ctx.Redirect(http.StatusTemporaryRedirect, state+"/?confirmLink="+confirmErr.Token)
+		return
+	}
+	if errors.Is(err, controllers.ErrTwoFactorRequired) {
+		challenge, err := h.twoFactor.Challenge(ctx, ctx.ClientIP(), user)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+
+		ctx.Redirect(http.StatusTemporaryRedirect, state+"/?twoFactor="+challenge.Token)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.Redirect(http.StatusPermanentRedirect, ctx.Query("state")+"/?token="+pair.Refresh)
+	ctx.Redirect(http.StatusPermanentRedirect, state+"/?token="+pair.Refresh)
 }
 
 // SetToken godoc
@@ -76,3 +112,66 @@ func (h *OAuth2) SetToken(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, user)
 }
+
+// Link godoc
+// @Param service path string true "OAuth2 Server"
+// @Param redirect query string true "Redirect host"
+// @Router /oauth2/link/{service} [get]
+func (h *OAuth2) Link(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+	service := ctx.Param("service")
+	redirectHost := ctx.Query("redirect")
+
+	url, err := h.controller.GetLinkURL(ctx, user, service, redirectHost)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// GetIdentities godoc
+// @Router /oauth2/identities [get]
+func (h *OAuth2) GetIdentities(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	identities := h.controller.GetIdentities(ctx, user)
+	ctx.JSON(http.StatusOK, identities)
+}
+
+// ConfirmLink godoc
+// @Param data body dto.ConfirmOAuth2Link true "Link token"
+// @Router /oauth2/identities/confirm [post]
+func (h *OAuth2) ConfirmLink(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.ConfirmOAuth2Link
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.controller.ConfirmLink(ctx, user, data.Token)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// Unlink godoc
+// @Param service path string true "OAuth2 Server"
+// @Router /oauth2/identities/{service} [delete]
+func (h *OAuth2) Unlink(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+	service := ctx.Param("service")
+
+	if err := h.controller.UnlinkIdentity(ctx, user, service); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
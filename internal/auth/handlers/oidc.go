@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+)
+
+type OIDC struct {
+	Middleware
+
+	controller controllers.OIDC
+
+	Group *gin.RouterGroup
+}
+
+func NewOIDC(middleware Middleware, controller controllers.OIDC, group *gin.RouterGroup) *OIDC {
+	h := &OIDC{Middleware: middleware, controller: controller, Group: group}
+
+	clients := group.Group("/clients", h.MemberAuth(entities.PermissionManageOIDCClients), h.NotImpersonated())
+	{
+		clients.GET("", h.GetClients)
+		clients.POST("", h.CreateClient)
+		clients.DELETE(":id", h.DeleteClient)
+	}
+
+	group.GET("authorize", h.Auth(), h.NotImpersonated(), h.GetAuthorization)
+	group.POST("authorize", h.Auth(), h.NotImpersonated(), h.Authorize)
+
+	group.POST("token", h.Token)
+	group.GET("userinfo", h.UserInfo)
+	group.POST("userinfo", h.UserInfo)
+
+	return h
+}
+
+// Configuration serves the provider metadata, it is mounted at /.well-known/openid-configuration
+func (h *OIDC) Configuration(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=600")
+	ctx.JSON(http.StatusOK, h.controller.Configuration(ctx))
+}
+
+// GetClients godoc
+// @Router /oidc/clients [get]
+func (h *OIDC) GetClients(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	clients, err := h.controller.GetClients(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clients)
+}
+
+// CreateClient godoc
+// @Router /oidc/clients [post]
+func (h *OIDC) CreateClient(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.OIDCClient
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	client, err := h.controller.CreateClient(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, client)
+}
+
+// DeleteClient godoc
+// @Param id path string true "Client ID"
+// @Router /oidc/clients/{id} [delete]
+func (h *OIDC) DeleteClient(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.DeleteClient(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetAuthorization godoc
+// @Router /oidc/authorize [get]
+func (h *OIDC) GetAuthorization(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.OIDCAuthorize
+	if err := ctx.BindQuery(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	authorization, err := h.controller.GetAuthorization(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authorization)
+}
+
+// Authorize godoc
+// @Router /oidc/authorize [post]
+func (h *OIDC) Authorize(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.OIDCConsent
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	redirect, err := h.controller.Authorize(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, redirect)
+}
+
+// Token godoc
+// @Router /oidc/token [post]
+func (h *OIDC) Token(ctx *gin.Context) {
+	var data dto.OIDCToken
+	if err := ctx.ShouldBind(&data); err != nil {
+		h.oidcError(ctx, controllers.ErrOIDCInvalidRequest.With(err.Error()))
+		return
+	}
+
+	if id, secret, ok := ctx.Request.BasicAuth(); ok {
+		data.ClientID, data.ClientSecret = id, secret
+	}
+
+	token, err := h.controller.Token(ctx, data)
+	if err != nil {
+		h.oidcError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+	ctx.JSON(http.StatusOK, token)
+}
+
+// UserInfo godoc
+// @Router /oidc/userinfo [get]
+func (h *OIDC) UserInfo(ctx *gin.Context) {
+	header := ctx.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		h.oidcError(ctx, controllers.ErrOIDCInvalidToken)
+		return
+	}
+
+	info, err := h.controller.UserInfo(ctx, strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		h.oidcError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+// oidcError responds in the OAuth 2.0 error format which clients of the token and userinfo endpoints expect
+func (h *OIDC) oidcError(ctx *gin.Context, err error) {
+	var oidcErr controllers.OIDCError
+	if !errors.As(err, &oidcErr) {
+		_ = ctx.Error(err)
+		return
+	}
+
+	code := http.StatusBadRequest
+	switch {
+	case errors.Is(err, controllers.ErrOIDCInvalidClient):
+		code = http.StatusUnauthorized
+		ctx.Header("WWW-Authenticate", "Basic")
+	case errors.Is(err, controllers.ErrOIDCInvalidToken):
+		code = http.StatusUnauthorized
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	ctx.JSON(code, gin.H{"error": oidcErr.Code, "error_description": oidcErr.Description})
+}
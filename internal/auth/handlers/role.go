@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+	"studyum/internal/auth/entities"
+)
+
+type Role struct {
+	Middleware
+
+	controller controllers.Role
+
+	Group *gin.RouterGroup
+}
+
+func NewRole(middleware Middleware, controller controllers.Role, group *gin.RouterGroup) *Role {
+	h := &Role{Middleware: middleware, controller: controller, Group: group}
+
+	group.GET("permissions", h.Auth(), h.GetPermissions)
+
+	manage := group.Group("", h.MemberAuth(entities.PermissionManageRoles), h.NotImpersonated())
+	{
+		manage.GET("", h.GetRoles)
+		manage.POST("", h.CreateRole)
+		manage.PUT(":id", h.UpdateRole)
+		manage.DELETE(":id", h.DeleteRole)
+		manage.PUT("users/:id", h.SetUserRoles)
+	}
+
+	return h
+}
+
+// GetPermissions godoc
+// @Router /roles/permissions [get]
+func (h *Role) GetPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.controller.GetPermissions(ctx))
+}
+
+// GetRoles godoc
+// @Router /roles [get]
+func (h *Role) GetRoles(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	roles, err := h.controller.GetRoles(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roles)
+}
+
+// CreateRole godoc
+// @Param data body dto.Role true "Role"
+// @Router /roles [post]
+func (h *Role) CreateRole(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.Role
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role, err := h.controller.CreateRole(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+// UpdateRole godoc
+// @Param id path string true "Role ID"
+// @Param data body dto.Role true "Role"
+// @Router /roles/{id} [put]
+func (h *Role) UpdateRole(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.Role
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role, err := h.controller.UpdateRole(ctx, user, ctx.Param("id"), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+// DeleteRole godoc
+// @Param id path string true "Role ID"
+// @Router /roles/{id} [delete]
+func (h *Role) DeleteRole(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.DeleteRole(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SetUserRoles godoc
+// @Param id path string true "User ID"
+// @Param data body dto.UserRoles true "Roles"
+// @Router /roles/users/{id} [put]
+func (h *Role) SetUserRoles(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.UserRoles
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.controller.SetUserRoles(ctx, user, ctx.Param("id"), data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -15,6 +15,60 @@ const docTemplateauth = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/2fa/login": {
+            "put": {
+                "responses": {}
+            }
+        },
+        "/2fa/totp": {
+            "put": {
+                "responses": {}
+            },
+            "post": {
+                "responses": {}
+            },
+            "delete": {
+                "responses": {}
+            }
+        },
+        "/2fa/totp/recovery": {
+            "post": {
+                "responses": {}
+            }
+        },
+        "/api-tokens": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.APIToken"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api-tokens/{id}": {
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/email/confirm": {
             "post": {
                 "responses": {}
@@ -25,11 +79,43 @@ const docTemplateauth = `{
                 "responses": {}
             }
         },
+        "/impersonation": {
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Impersonation",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Impersonation"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "responses": {}
+            }
+        },
+        "/impersonation/log": {
+            "get": {
+                "responses": {}
+            }
+        },
         "/login": {
             "put": {
                 "responses": {}
             }
         },
+        "/login/magic": {
+            "put": {
+                "responses": {}
+            },
+            "post": {
+                "responses": {}
+            }
+        },
         "/oauth2/callback/{service}": {
             "get": {
                 "parameters": [
@@ -44,6 +130,62 @@ const docTemplateauth = `{
                 "responses": {}
             }
         },
+        "/oauth2/identities": {
+            "get": {
+                "responses": {}
+            }
+        },
+        "/oauth2/identities/confirm": {
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Link token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmOAuth2Link"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oauth2/identities/{service}": {
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OAuth2 Server",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oauth2/link/{service}": {
+            "get": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OAuth2 Server",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect host",
+                        "name": "redirect",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/oauth2/{service}": {
             "get": {
                 "parameters": [
@@ -58,8 +200,147 @@ const docTemplateauth = `{
                 "responses": {}
             }
         },
+        "/oidc/authorize": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "responses": {}
+            }
+        },
+        "/oidc/clients": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "responses": {}
+            }
+        },
+        "/oidc/clients/{id}": {
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oidc/token": {
+            "post": {
+                "responses": {}
+            }
+        },
+        "/oidc/userinfo": {
+            "get": {
+                "responses": {}
+            }
+        },
+        "/roles": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Role",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Role"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "responses": {}
+            }
+        },
+        "/roles/users/{id}": {
+            "put": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Roles",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRoles"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/roles/{id}": {
+            "put": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Role"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/sessions": {
+            "get": {
+                "responses": {}
+            },
+            "delete": {
+                "responses": {}
+            }
+        },
+        "/sessions/{id}": {
             "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {}
             }
         },
@@ -87,6 +368,170 @@ const docTemplateauth = `{
             "post": {
                 "responses": {}
             }
+        },
+        "/updateToken": {
+            "put": {
+                "responses": {}
+            }
+        },
+        "/webauthn/login": {
+            "put": {
+                "parameters": [
+                    {
+                        "description": "Assertion",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyLogin"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webauthn/login/options": {
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Login",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyLoginOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webauthn/passkeys": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "parameters": [
+                    {
+                        "description": "Attestation",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PasskeyRegistration"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webauthn/passkeys/options": {
+            "post": {
+                "responses": {}
+            }
+        },
+        "/webauthn/passkeys/{id}": {
+            "delete": {
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        }
+    },
+    "definitions": {
+        "dto.APIToken": {
+            "type": "object",
+            "properties": {
+                "allowedIPs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ConfirmOAuth2Link": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Impersonation": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PasskeyLogin": {
+            "type": "object"
+        },
+        "dto.PasskeyLoginOptions": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PasskeyRegistration": {
+            "type": "object"
+        },
+        "dto.Role": {
+            "type": "object",
+            "properties": {
+                "inherits": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.UserRoles": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+)
+
+type TwoFactor struct {
+	Middleware
+
+	controller controllers.TwoFactor
+
+	Group *gin.RouterGroup
+}
+
+func NewTwoFactor(middleware Middleware, controller controllers.TwoFactor, limit gin.HandlerFunc, group *gin.RouterGroup) *TwoFactor {
+	h := &TwoFactor{Middleware: middleware, controller: controller, Group: group}
+
+	group.PUT("login", limit, h.Login)
+
+	totp := group.Group("/totp", h.Auth(), h.NotImpersonated())
+	{
+		totp.POST("", h.Enroll)
+		totp.PUT("", h.Enable)
+		totp.DELETE("", h.Disable)
+		totp.POST("recovery", h.RegenerateRecoveryCodes)
+	}
+
+	return h
+}
+
+// Login godoc
+// @Router /2fa/login [put]
+func (h *TwoFactor) Login(ctx *gin.Context) {
+	var data dto.TwoFactorLogin
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, pair, err := h.controller.Login(ctx, ctx.ClientIP(), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.SetTokenPairCookie(ctx, pair)
+	h.SetTokenPairHeader(ctx, pair)
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// Enroll godoc
+// @Router /2fa/totp [post]
+func (h *TwoFactor) Enroll(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	enrollment, err := h.controller.Enroll(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// Enable godoc
+// @Router /2fa/totp [put]
+func (h *TwoFactor) Enable(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.TwoFactorCode
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.controller.Enable(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codes)
+}
+
+// Disable godoc
+// @Router /2fa/totp [delete]
+func (h *TwoFactor) Disable(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.TwoFactorCode
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.controller.Disable(ctx, user, data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Router /2fa/totp/recovery [post]
+func (h *TwoFactor) RegenerateRecoveryCodes(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.TwoFactorCode
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.controller.RegenerateRecoveryCodes(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codes)
+}
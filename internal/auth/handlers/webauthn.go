@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/dto"
+)
+
+type WebAuthn struct {
+	Middleware
+
+	controller controllers.WebAuthn
+
+	Group *gin.RouterGroup
+}
+
+func NewWebAuthn(middleware Middleware, controller controllers.WebAuthn, limit gin.HandlerFunc, group *gin.RouterGroup) *WebAuthn {
+	h := &WebAuthn{Middleware: middleware, controller: controller, Group: group}
+
+	group.POST("login/options", limit, h.BeginLogin)
+	group.PUT("login", limit, h.Login)
+
+	passkeys := group.Group("/passkeys", h.Auth())
+	{
+		passkeys.GET("", h.GetPasskeys)
+		passkeys.POST("options", h.NotImpersonated(), h.BeginRegistration)
+		passkeys.POST("", h.NotImpersonated(), h.FinishRegistration)
+		passkeys.DELETE(":id", h.NotImpersonated(), h.DeletePasskey)
+	}
+
+	return h
+}
+
+// BeginLogin godoc
+// @Param data body dto.PasskeyLoginOptions true "Login"
+// @Router /webauthn/login/options [post]
+func (h *WebAuthn) BeginLogin(ctx *gin.Context) {
+	var data dto.PasskeyLoginOptions
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	options, err := h.controller.BeginLogin(ctx, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, options)
+}
+
+// Login godoc
+// @Param data body dto.PasskeyLogin true "Assertion"
+// @Router /webauthn/login [put]
+func (h *WebAuthn) Login(ctx *gin.Context) {
+	var data dto.PasskeyLogin
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, pair, err := h.controller.Login(ctx, ctx.ClientIP(), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.SetTokenPairCookie(ctx, pair)
+	h.SetTokenPairHeader(ctx, pair)
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// GetPasskeys godoc
+// @Router /webauthn/passkeys [get]
+func (h *WebAuthn) GetPasskeys(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	ctx.JSON(http.StatusOK, h.controller.GetPasskeys(ctx, user))
+}
+
+// BeginRegistration godoc
+// @Router /webauthn/passkeys/options [post]
+func (h *WebAuthn) BeginRegistration(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	options, err := h.controller.BeginRegistration(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, options)
+}
+
+// FinishRegistration godoc
+// @Param data body dto.PasskeyRegistration true "Attestation"
+// @Router /webauthn/passkeys [post]
+func (h *WebAuthn) FinishRegistration(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.PasskeyRegistration
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	passkey, err := h.controller.FinishRegistration(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, passkey)
+}
+
+// DeletePasskey godoc
+// @Param id path string true "Passkey ID"
+// @Router /webauthn/passkeys/{id} [delete]
+func (h *WebAuthn) DeletePasskey(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	if err := h.controller.DeletePasskey(ctx, user, ctx.Param("id")); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
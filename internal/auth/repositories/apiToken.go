@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/auth/entities"
+	"time"
+)
+
+type APIToken interface {
+	AddToken(ctx context.Context, token entities.APIToken) error
+	GetTokenByID(ctx context.Context, id primitive.ObjectID) (entities.APIToken, error)
+	GetTokens(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.APIToken, error)
+	DeleteToken(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error
+	UpdateLastUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time, ip string) error
+}
+
+type apiToken struct {
+	tokens *mongo.Collection
+}
+
+func NewAPIToken(tokens *mongo.Collection) APIToken {
+	r := &apiToken{tokens: tokens}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *apiToken) createIndexes(ctx context.Context) {
+	_, err := r.tokens.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "studyPlaceID", Value: 1}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating api tokens indexes: " + err.Error())
+	}
+}
+
+func (r *apiToken) AddToken(ctx context.Context, token entities.APIToken) error {
+	_, err := r.tokens.InsertOne(ctx, token)
+	return err
+}
+
+func (r *apiToken) GetTokenByID(ctx context.Context, id primitive.ObjectID) (token entities.APIToken, err error) {
+	err = r.tokens.FindOne(ctx, bson.M{"_id": id}).Decode(&token)
+	return
+}
+
+func (r *apiToken) GetTokens(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.APIToken, error) {
+	cursor, err := r.tokens.Find(ctx, bson.M{"studyPlaceID": studyPlaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]entities.APIToken, 0)
+	if err = cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *apiToken) DeleteToken(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error {
+	result, err := r.tokens.DeleteOne(ctx, bson.M{"_id": id, "studyPlaceID": studyPlaceID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *apiToken) UpdateLastUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time, ip string) error {
+	_, err := r.tokens.UpdateByID(ctx, id, bson.M{"$set": bson.M{"lastUsedAt": usedAt, "lastUsedIP": ip}})
+	return err
+}
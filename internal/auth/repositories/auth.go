@@ -10,6 +10,7 @@ import (
 
 type Auth interface {
 	GetUserByLogin(ctx context.Context, login string) (entities.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entities.User, error)
 	AddUser(ctx context.Context, user entities.User) error
 	UpdateUser(ctx context.Context, user entities.User) error
 	VerifyEmail(ctx context.Context, userID primitive.ObjectID) error
@@ -30,6 +31,11 @@ func (r *auth) GetUserByLogin(ctx context.Context, login string) (user entities.
 	return
 }
 
+func (r *auth) GetUserByEmail(ctx context.Context, email string) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	return
+}
+
 func (r *auth) AddUser(ctx context.Context, user entities.User) error {
 	_, err := r.users.InsertOne(ctx, user)
 	return err
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/auth/entities"
+	"time"
+)
+
+type Impersonation interface {
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
+
+	AddImpersonation(ctx context.Context, impersonation entities.Impersonation) error
+	EndImpersonation(ctx context.Context, sessionID string, endedAt time.Time) error
+	GetImpersonations(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.Impersonation, error)
+}
+
+type impersonation struct {
+	users          *mongo.Collection
+	impersonations *mongo.Collection
+}
+
+func NewImpersonation(users *mongo.Collection, impersonations *mongo.Collection) Impersonation {
+	r := &impersonation{users: users, impersonations: impersonations}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *impersonation) createIndexes(ctx context.Context) {
+	_, err := r.impersonations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "studyPlaceID", Value: 1}, {Key: "startedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "sessionID", Value: 1}}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating impersonation indexes: " + err.Error())
+	}
+}
+
+func (r *impersonation) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return
+}
+
+func (r *impersonation) AddImpersonation(ctx context.Context, impersonation entities.Impersonation) error {
+	_, err := r.impersonations.InsertOne(ctx, impersonation)
+	return err
+}
+
+func (r *impersonation) EndImpersonation(ctx context.Context, sessionID string, endedAt time.Time) error {
+	_, err := r.impersonations.UpdateOne(ctx, bson.M{"sessionID": sessionID, "endedAt": nil}, bson.M{"$set": bson.M{"endedAt": endedAt}})
+	return err
+}
+
+func (r *impersonation) GetImpersonations(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.Impersonation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "startedAt", Value: -1}})
+	cursor, err := r.impersonations.Find(ctx, bson.M{"studyPlaceID": studyPlaceID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonations := make([]entities.Impersonation, 0)
+	if err = cursor.All(ctx, &impersonations); err != nil {
+		return nil, err
+	}
+
+	return impersonations, nil
+}
@@ -12,7 +12,7 @@ import (
 type Middleware interface {
 	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
 
-	GetStudyPlaceByApiToken(ctx context.Context, token string) (entities2.StudyPlace, error)
+	GetStudyPlaceByID(ctx context.Context, id primitive.ObjectID) (entities2.StudyPlace, error)
 }
 
 type middleware struct {
@@ -29,7 +29,7 @@ func (r *middleware) GetUserByID(ctx context.Context, id primitive.ObjectID) (us
 	return
 }
 
-func (r *middleware) GetStudyPlaceByApiToken(ctx context.Context, token string) (studyPlace entities2.StudyPlace, err error) {
-	err = r.studyPlaces.FindOne(ctx, bson.M{"apiToken": token}).Decode(&studyPlace)
+func (r *middleware) GetStudyPlaceByID(ctx context.Context, id primitive.ObjectID) (studyPlace entities2.StudyPlace, err error) {
+	err = r.studyPlaces.FindOne(ctx, bson.M{"_id": id}).Decode(&studyPlace)
 	return
 }
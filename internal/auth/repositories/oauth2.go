@@ -3,10 +3,14 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"net/http"
 	"studyum/internal/auth/entities"
+	"time"
 )
 
 type OAuth2 interface {
@@ -14,16 +18,48 @@ type OAuth2 interface {
 	GetCallbackUser(ctx context.Context, url string) (entities.OAuth2CallbackUser, error)
 
 	GetUserByEmail(ctx context.Context, email string) (entities.User, error)
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
+	GetUserByIdentity(ctx context.Context, provider string, subject string) (entities.User, error)
 	SignUp(ctx context.Context, user entities.User) error
+
+	AddIdentity(ctx context.Context, userID primitive.ObjectID, identity entities.OAuth2Identity) error
+	RemoveIdentity(ctx context.Context, userID primitive.ObjectID, provider string) error
+
+	AddLink(ctx context.Context, link entities.OAuth2Link) error
+	GetLinkAndDelete(ctx context.Context, token string) (entities.OAuth2Link, error)
 }
 
 type oauth2 struct {
 	services *mongo.Collection
 	users    *mongo.Collection
+	links    *mongo.Collection
+}
+
+func NewOAuth2(services *mongo.Collection, users *mongo.Collection, links *mongo.Collection) OAuth2 {
+	r := &oauth2{services: services, users: users, links: links}
+	r.createIndexes(context.Background())
+
+	return r
 }
 
-func NewOAuth2(services *mongo.Collection, users *mongo.Collection) OAuth2 {
-	return &oauth2{services: services, users: users}
+func (r *oauth2) createIndexes(ctx context.Context) {
+	_, err := r.users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"identities.subject": bson.M{"$exists": true},
+		}),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating OAuth2 identities indexes: " + err.Error())
+	}
+
+	_, err = r.links.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating OAuth2 links indexes: " + err.Error())
+	}
 }
 
 func (r *oauth2) GetService(ctx context.Context, serviceName string) (service entities.OAuth2ServiceRaw, err error) {
@@ -51,7 +87,49 @@ func (r *oauth2) GetUserByEmail(ctx context.Context, email string) (user entitie
 	return
 }
 
+func (r *oauth2) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return
+}
+
+func (r *oauth2) GetUserByIdentity(ctx context.Context, provider string, subject string) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}).Decode(&user)
+	return
+}
+
 func (r *oauth2) SignUp(ctx context.Context, user entities.User) error {
 	_, err := r.users.InsertOne(ctx, user)
 	return err
 }
+
+// AddIdentity links the identity unless the user has an identity of the same provider already
+func (r *oauth2) AddIdentity(ctx context.Context, userID primitive.ObjectID, identity entities.OAuth2Identity) error {
+	result, err := r.users.UpdateOne(ctx,
+		bson.M{"_id": userID, "identities.provider": bson.M{"$ne": identity.Provider}},
+		bson.M{"$push": bson.M{"identities": identity}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *oauth2) RemoveIdentity(ctx context.Context, userID primitive.ObjectID, provider string) error {
+	_, err := r.users.UpdateByID(ctx, userID, bson.M{"$pull": bson.M{"identities": bson.M{"provider": provider}}})
+	return err
+}
+
+func (r *oauth2) AddLink(ctx context.Context, link entities.OAuth2Link) error {
+	_, err := r.links.InsertOne(ctx, link)
+	return err
+}
+
+func (r *oauth2) GetLinkAndDelete(ctx context.Context, token string) (link entities.OAuth2Link, err error) {
+	err = r.links.FindOneAndDelete(ctx, bson.M{"_id": token, "expiresAt": bson.M{"$gt": time.Now()}}).Decode(&link)
+	return
+}
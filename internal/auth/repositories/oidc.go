@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/auth/entities"
+)
+
+type OIDC interface {
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
+
+	AddClient(ctx context.Context, client entities.OIDCClient) error
+	GetClient(ctx context.Context, id string) (entities.OIDCClient, error)
+	GetClients(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.OIDCClient, error)
+	DeleteClient(ctx context.Context, studyPlaceID primitive.ObjectID, id string) error
+
+	GetConsent(ctx context.Context, userID primitive.ObjectID, clientID string) (entities.OIDCConsent, error)
+	SaveConsent(ctx context.Context, consent entities.OIDCConsent) error
+
+	AddCode(ctx context.Context, code entities.OIDCAuthorizationCode) error
+	GetCodeAndDelete(ctx context.Context, codeHash string) (entities.OIDCAuthorizationCode, error)
+
+	AddAccessToken(ctx context.Context, token entities.OIDCAccessToken) error
+	GetAccessToken(ctx context.Context, tokenHash string) (entities.OIDCAccessToken, error)
+}
+
+type oidc struct {
+	users    *mongo.Collection
+	clients  *mongo.Collection
+	consents *mongo.Collection
+	codes    *mongo.Collection
+	tokens   *mongo.Collection
+}
+
+func NewOIDC(users, clients, consents, codes, tokens *mongo.Collection) OIDC {
+	r := &oidc{users: users, clients: clients, consents: consents, codes: codes, tokens: tokens}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *oidc) createIndexes(ctx context.Context) {
+	expire := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	for _, collection := range []*mongo.Collection{r.codes, r.tokens} {
+		if _, err := collection.Indexes().CreateOne(ctx, expire); err != nil {
+			logrus.Warningln("Error creating OIDC indexes: " + err.Error())
+		}
+	}
+
+	_, err := r.consents.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "clientID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating OIDC indexes: " + err.Error())
+	}
+}
+
+func (r *oidc) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return
+}
+
+func (r *oidc) AddClient(ctx context.Context, client entities.OIDCClient) error {
+	_, err := r.clients.InsertOne(ctx, client)
+	return err
+}
+
+func (r *oidc) GetClient(ctx context.Context, id string) (client entities.OIDCClient, err error) {
+	err = r.clients.FindOne(ctx, bson.M{"_id": id}).Decode(&client)
+	return
+}
+
+func (r *oidc) GetClients(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.OIDCClient, error) {
+	cursor, err := r.clients.Find(ctx, bson.M{"studyPlaceID": studyPlaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]entities.OIDCClient, 0)
+	if err = cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (r *oidc) DeleteClient(ctx context.Context, studyPlaceID primitive.ObjectID, id string) error {
+	result, err := r.clients.DeleteOne(ctx, bson.M{"_id": id, "studyPlaceID": studyPlaceID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	if _, err = r.consents.DeleteMany(ctx, bson.M{"clientID": id}); err != nil {
+		return err
+	}
+
+	_, err = r.tokens.DeleteMany(ctx, bson.M{"clientID": id})
+	return err
+}
+
+func (r *oidc) GetConsent(ctx context.Context, userID primitive.ObjectID, clientID string) (consent entities.OIDCConsent, err error) {
+	err = r.consents.FindOne(ctx, bson.M{"userID": userID, "clientID": clientID}).Decode(&consent)
+	return
+}
+
+func (r *oidc) SaveConsent(ctx context.Context, consent entities.OIDCConsent) error {
+	_, err := r.consents.UpdateOne(ctx,
+		bson.M{"userID": consent.UserID, "clientID": consent.ClientID},
+		bson.M{"$set": bson.M{"scopes": consent.Scopes}, "$setOnInsert": bson.M{"_id": consent.ID}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *oidc) AddCode(ctx context.Context, code entities.OIDCAuthorizationCode) error {
+	_, err := r.codes.InsertOne(ctx, code)
+	return err
+}
+
+// GetCodeAndDelete returns the code only once, so it can't be exchanged twice
+func (r *oidc) GetCodeAndDelete(ctx context.Context, codeHash string) (code entities.OIDCAuthorizationCode, err error) {
+	err = r.codes.FindOneAndDelete(ctx, bson.M{"_id": codeHash}).Decode(&code)
+	return
+}
+
+func (r *oidc) AddAccessToken(ctx context.Context, token entities.OIDCAccessToken) error {
+	_, err := r.tokens.InsertOne(ctx, token)
+	return err
+}
+
+func (r *oidc) GetAccessToken(ctx context.Context, tokenHash string) (token entities.OIDCAccessToken, err error) {
+	err = r.tokens.FindOne(ctx, bson.M{"_id": tokenHash}).Decode(&token)
+	return
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/auth/entities"
+)
+
+type Role interface {
+	GetRoles(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.Role, error)
+	AddRole(ctx context.Context, role entities.Role) error
+	UpdateRole(ctx context.Context, role entities.Role) error
+	DeleteRole(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error
+
+	SetUserRoles(ctx context.Context, studyPlaceID primitive.ObjectID, userID primitive.ObjectID, roles []primitive.ObjectID) error
+}
+
+type role struct {
+	users *mongo.Collection
+	roles *mongo.Collection
+}
+
+func NewRole(users *mongo.Collection, roles *mongo.Collection) Role {
+	r := &role{users: users, roles: roles}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *role) createIndexes(ctx context.Context) {
+	_, err := r.roles.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "studyPlaceID", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating roles indexes: " + err.Error())
+	}
+}
+
+func (r *role) GetRoles(ctx context.Context, studyPlaceID primitive.ObjectID) ([]entities.Role, error) {
+	cursor, err := r.roles.Find(ctx, bson.M{"studyPlaceID": studyPlaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]entities.Role, 0)
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *role) AddRole(ctx context.Context, role entities.Role) error {
+	_, err := r.roles.InsertOne(ctx, role)
+	return err
+}
+
+func (r *role) UpdateRole(ctx context.Context, role entities.Role) error {
+	result, err := r.roles.UpdateOne(ctx,
+		bson.M{"_id": role.ID, "studyPlaceID": role.StudyPlaceID},
+		bson.M{"$set": bson.M{"name": role.Name, "permissions": role.Permissions, "inherits": role.Inherits}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteRole removes the role and unassigns it from the study place users
+func (r *role) DeleteRole(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) error {
+	result, err := r.roles.DeleteOne(ctx, bson.M{"_id": id, "studyPlaceID": studyPlaceID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	_, err = r.users.UpdateMany(ctx,
+		bson.M{"studyPlaceInfo._id": studyPlaceID, "studyPlaceInfo.roles": id},
+		bson.M{"$pull": bson.M{"studyPlaceInfo.roles": id}},
+	)
+	return err
+}
+
+func (r *role) SetUserRoles(ctx context.Context, studyPlaceID primitive.ObjectID, userID primitive.ObjectID, roles []primitive.ObjectID) error {
+	result, err := r.users.UpdateOne(ctx,
+		bson.M{"_id": userID, "studyPlaceInfo._id": studyPlaceID},
+		bson.M{"$set": bson.M{"studyPlaceInfo.roles": roles}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
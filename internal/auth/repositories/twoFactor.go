@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/auth/entities"
+)
+
+type TwoFactor interface {
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
+
+	SetTOTP(ctx context.Context, userID primitive.ObjectID, totp entities.TOTP) error
+	UseTOTPStep(ctx context.Context, userID primitive.ObjectID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID primitive.ObjectID, code string) error
+
+	AddChallenge(ctx context.Context, challenge entities.TwoFactorChallenge) error
+	IncChallengeAttempts(ctx context.Context, token string, maxAttempts int) (entities.TwoFactorChallenge, error)
+	TakeChallenge(ctx context.Context, token string) (entities.TwoFactorChallenge, error)
+	DeleteChallenge(ctx context.Context, token string) error
+}
+
+type twoFactor struct {
+	users      *mongo.Collection
+	challenges *mongo.Collection
+}
+
+func NewTwoFactor(users *mongo.Collection, challenges *mongo.Collection) TwoFactor {
+	r := &twoFactor{users: users, challenges: challenges}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *twoFactor) createIndexes(ctx context.Context) {
+	_, err := r.challenges.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating two-factor challenges indexes: " + err.Error())
+	}
+}
+
+func (r *twoFactor) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return
+}
+
+func (r *twoFactor) SetTOTP(ctx context.Context, userID primitive.ObjectID, totp entities.TOTP) error {
+	_, err := r.users.UpdateByID(ctx, userID, bson.M{"$set": bson.M{"totp": totp}})
+	return err
+}
+
+// UseTOTPStep marks the step as used, it fails if the same or a later step was used already
+func (r *twoFactor) UseTOTPStep(ctx context.Context, userID primitive.ObjectID, step int64) error {
+	result, err := r.users.UpdateOne(ctx, bson.M{"_id": userID, "totp.lastStep": bson.M{"$lt": step}}, bson.M{"$set": bson.M{"totp.lastStep": step}})
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UseRecoveryCode removes the hashed recovery code, it fails if the code was used already
+func (r *twoFactor) UseRecoveryCode(ctx context.Context, userID primitive.ObjectID, code string) error {
+	result, err := r.users.UpdateOne(ctx, bson.M{"_id": userID, "totp.recoveryCodes": code}, bson.M{"$pull": bson.M{"totp.recoveryCodes": code}})
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *twoFactor) AddChallenge(ctx context.Context, challenge entities.TwoFactorChallenge) error {
+	_, err := r.challenges.InsertOne(ctx, challenge)
+	return err
+}
+
+// IncChallengeAttempts counts an attempt of the challenge and returns it with the attempts updated,
+// challenges which have no attempts left are not found
+func (r *twoFactor) IncChallengeAttempts(ctx context.Context, token string, maxAttempts int) (challenge entities.TwoFactorChallenge, err error) {
+	err = r.challenges.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": token, "attempts": bson.M{"$lt": maxAttempts}},
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&challenge)
+	return
+}
+
+// TakeChallenge deletes and returns the challenge, so concurrent requests can't finish it twice
+func (r *twoFactor) TakeChallenge(ctx context.Context, token string) (challenge entities.TwoFactorChallenge, err error) {
+	err = r.challenges.FindOneAndDelete(ctx, bson.M{"_id": token}).Decode(&challenge)
+	return
+}
+
+func (r *twoFactor) DeleteChallenge(ctx context.Context, token string) error {
+	_, err := r.challenges.DeleteOne(ctx, bson.M{"_id": token})
+	return err
+}
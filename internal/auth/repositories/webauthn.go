@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/auth/entities"
+	"time"
+)
+
+type WebAuthn interface {
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (entities.User, error)
+	GetUserByLogin(ctx context.Context, login string) (entities.User, error)
+	GetUserByPasskey(ctx context.Context, id []byte) (entities.User, error)
+
+	AddPasskey(ctx context.Context, userID primitive.ObjectID, passkey entities.Passkey) error
+	UsePasskey(ctx context.Context, userID primitive.ObjectID, id []byte, signCount uint32, usedAt time.Time) error
+	DeletePasskey(ctx context.Context, userID primitive.ObjectID, id []byte) error
+
+	AddChallenge(ctx context.Context, challenge entities.PasskeyChallenge) error
+	TakeChallenge(ctx context.Context, token string, ceremony string) (entities.PasskeyChallenge, error)
+}
+
+type webAuthn struct {
+	users      *mongo.Collection
+	challenges *mongo.Collection
+}
+
+func NewWebAuthn(users *mongo.Collection, challenges *mongo.Collection) WebAuthn {
+	r := &webAuthn{users: users, challenges: challenges}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *webAuthn) createIndexes(ctx context.Context) {
+	_, err := r.users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "passkeys.id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"passkeys.id": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating passkeys indexes: " + err.Error())
+	}
+
+	_, err = r.challenges.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating passkey challenges indexes: " + err.Error())
+	}
+}
+
+func (r *webAuthn) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	return
+}
+
+func (r *webAuthn) GetUserByLogin(ctx context.Context, login string) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"login": login}).Decode(&user)
+	return
+}
+
+func (r *webAuthn) GetUserByPasskey(ctx context.Context, id []byte) (user entities.User, err error) {
+	err = r.users.FindOne(ctx, bson.M{"passkeys.id": id}).Decode(&user)
+	return
+}
+
+func (r *webAuthn) AddPasskey(ctx context.Context, userID primitive.ObjectID, passkey entities.Passkey) error {
+	_, err := r.users.UpdateByID(ctx, userID, bson.M{"$push": bson.M{"passkeys": passkey}})
+	return err
+}
+
+// UsePasskey stores the new signature counter, it fails if the counter was moved by a concurrent login
+func (r *webAuthn) UsePasskey(ctx context.Context, userID primitive.ObjectID, id []byte, signCount uint32, usedAt time.Time) error {
+	filter := bson.M{"_id": userID, "passkeys": bson.M{"$elemMatch": bson.M{"id": id, "signCount": bson.M{"$lte": signCount}}}}
+	result, err := r.users.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"passkeys.$.signCount": signCount, "passkeys.$.lastUsedAt": usedAt}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *webAuthn) DeletePasskey(ctx context.Context, userID primitive.ObjectID, id []byte) error {
+	result, err := r.users.UpdateOne(ctx, bson.M{"_id": userID, "passkeys.id": id}, bson.M{"$pull": bson.M{"passkeys": bson.M{"id": id}}})
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *webAuthn) AddChallenge(ctx context.Context, challenge entities.PasskeyChallenge) error {
+	_, err := r.challenges.InsertOne(ctx, challenge)
+	return err
+}
+
+// TakeChallenge removes the challenge of the ceremony, so every challenge is answered once
+func (r *webAuthn) TakeChallenge(ctx context.Context, token string, ceremony string) (challenge entities.PasskeyChallenge, err error) {
+	err = r.challenges.FindOneAndDelete(ctx, bson.M{"_id": token, "ceremony": ceremony, "expiresAt": bson.M{"$gt": time.Now()}}).Decode(&challenge)
+	return
+}
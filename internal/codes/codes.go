@@ -8,11 +8,11 @@ import (
 	"time"
 )
 
-func New(expireTime time.Duration, timeout time.Duration, mailer mail.Mail, db *mongo.Database) controllers.Controller {
+func New(expireTime time.Duration, timeout time.Duration, secret string, mailer mail.Mail, db *mongo.Database) controllers.Controller {
 	collection := db.Collection("VerificationCodes")
 
 	repository := repositories.New(collection)
-	controller := controllers.New(repository, mailer, expireTime, timeout)
+	controller := controllers.New(repository, mailer, secret, expireTime, timeout)
 
 	return controller
 }
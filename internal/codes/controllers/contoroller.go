@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"math/rand"
@@ -13,23 +14,30 @@ import (
 
 var ErrForbidden = errors.New("forbidden")
 
+// maxAttempts is the amount of guesses after which the code is deleted
+const maxAttempts = 5
+
 type Controller interface {
 	Send(ctx context.Context, code entities.Code) error
-	Receive(ctx context.Context, codeType entities.CodeType, code string) (entities.Code, error)
+	Receive(ctx context.Context, codeType entities.CodeType, email string, code string) (entities.Code, error)
+
+	SendLink(ctx context.Context, code entities.Code) error
+	ReceiveLink(ctx context.Context, codeType entities.CodeType, token string) (entities.Code, error)
 }
 
 type controller struct {
 	repository repositories.Repository
 
-	mail mail.Mail
+	mail   mail.Mail
+	secret []byte
 
 	expireTime time.Duration
 	timeout    time.Duration
 }
 
-func New(repository repositories.Repository, mail mail.Mail, expireTime time.Duration, timeout time.Duration) Controller {
+func New(repository repositories.Repository, mail mail.Mail, secret string, expireTime time.Duration, timeout time.Duration) Controller {
 	rand.Seed(time.Now().Unix())
-	return &controller{repository: repository, mail: mail, expireTime: expireTime, timeout: timeout}
+	return &controller{repository: repository, mail: mail, secret: []byte(secret), expireTime: expireTime, timeout: timeout}
 }
 
 // 78_364_164_096 variations
@@ -42,12 +50,14 @@ func (c *controller) generate() string {
 	return string(b)
 }
 
-func (c *controller) sendEmail(_ context.Context, code entities.Code) error {
-	data := mail.Data{"code": code.Code, "name": code.To, "expire": code.CreatedAt.Add(time.Minute * 15).Format("01-02-2006 15:04")}
+// sendEmail sends the value which is the code or the link token
+func (c *controller) sendEmail(_ context.Context, code entities.Code, value string) error {
+	data := mail.Data{"code": value, "name": code.To, "expire": code.CreatedAt.Add(c.expireTime).Format("01-02-2006 15:04")}
 	return c.mail.SendFile(code.Email, code.Subject, code.Filename, data)
 }
 
-func (c *controller) Send(ctx context.Context, code entities.Code) error {
+// prepare rejects codes requested during the timeout and removes previous codes of the email and the user
+func (c *controller) prepare(ctx context.Context, code entities.Code) error {
 	if tempCode, err := c.repository.GetCodeByEmail(ctx, code.Email); err == nil {
 		if tempCode.CreatedAt.Add(c.timeout).After(time.Now()) {
 			return ErrForbidden
@@ -64,7 +74,11 @@ func (c *controller) Send(ctx context.Context, code entities.Code) error {
 		return err
 	}
 
-	if err := c.repository.DeleteAllByUserID(ctx, code.UserID); err != nil {
+	return c.repository.DeleteAllByUserID(ctx, code.UserID)
+}
+
+func (c *controller) Send(ctx context.Context, code entities.Code) error {
+	if err := c.prepare(ctx, code); err != nil {
 		return err
 	}
 
@@ -76,18 +90,32 @@ func (c *controller) Send(ctx context.Context, code entities.Code) error {
 		return err
 	}
 
-	return c.sendEmail(ctx, code)
+	return c.sendEmail(ctx, code, code.Code)
 }
 
-func (c *controller) Receive(ctx context.Context, codeType entities.CodeType, rawCode string) (entities.Code, error) {
-	code, err := c.repository.GetCodeAndDelete(ctx, codeType, rawCode)
+// Receive deletes and returns the code sent to the email, every guess counts as an attempt before the code is compared,
+// so concurrent guesses can't exceed maxAttempts
+func (c *controller) Receive(ctx context.Context, codeType entities.CodeType, email string, rawCode string) (entities.Code, error) {
+	code, err := c.repository.IncAttempts(ctx, codeType, email, maxAttempts)
 	if err != nil {
 		return entities.Code{}, err
 	}
 
+	if subtle.ConstantTimeCompare([]byte(code.Code), []byte(rawCode)) != 1 {
+		if code.Attempts >= maxAttempts {
+			_ = c.repository.DeleteByID(ctx, code.ID)
+		}
+
+		return entities.Code{}, ErrForbidden
+	}
+
+	if code, err = c.repository.TakeByID(ctx, code.ID); err != nil {
+		return entities.Code{}, err
+	}
+
 	if code.CreatedAt.Add(c.expireTime).Before(time.Now()) {
 		return entities.Code{}, ErrForbidden
 	}
 
-	return code, err
+	return code, nil
 }
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/codes/entities"
+	"studyum/internal/codes/repositories"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testRepository struct {
+	repositories.Repository
+
+	mutex sync.Mutex
+	codes map[primitive.ObjectID]entities.Code
+}
+
+func (r *testRepository) IncAttempts(_ context.Context, codeType entities.CodeType, email string, maxAttempts int) (entities.Code, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, code := range r.codes {
+		if code.Type == codeType && code.Email == email && code.Attempts < maxAttempts {
+			code.Attempts++
+			r.codes[id] = code
+			return code, nil
+		}
+	}
+	return entities.Code{}, mongo.ErrNoDocuments
+}
+
+func (r *testRepository) DeleteByID(_ context.Context, id primitive.ObjectID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.codes, id)
+	return nil
+}
+
+func (r *testRepository) TakeByID(_ context.Context, id primitive.ObjectID) (entities.Code, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[id]
+	if !ok {
+		return entities.Code{}, mongo.ErrNoDocuments
+	}
+
+	delete(r.codes, id)
+	return code, nil
+}
+
+func newTestController(codes ...entities.Code) (*controller, *testRepository) {
+	repository := &testRepository{codes: map[primitive.ObjectID]entities.Code{}}
+	for _, code := range codes {
+		repository.codes[code.ID] = code
+	}
+
+	return &controller{repository: repository, expireTime: time.Minute}, repository
+}
+
+func TestReceive(t *testing.T) {
+	ctx := context.Background()
+	code := entities.Code{ID: primitive.NewObjectID(), Type: "type", Email: "user@example.com", Code: "CODE12", CreatedAt: time.Now()}
+	c, repository := newTestController(code)
+
+	_, err := c.Receive(ctx, "type", "user@example.com", "WRONG1")
+	assert.Equal(t, err, ErrForbidden)
+	assert.Equal(t, repository.codes[code.ID].Attempts, 1)
+
+	received, err := c.Receive(ctx, "type", "user@example.com", "CODE12")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, received.ID, code.ID)
+	assert.Equal(t, len(repository.codes), 0)
+
+	// codes are used once
+	_, err = c.Receive(ctx, "type", "user@example.com", "CODE12")
+	assert.Equal(t, err, mongo.ErrNoDocuments)
+}
+
+func TestReceiveConcurrentGuesses(t *testing.T) {
+	ctx := context.Background()
+	code := entities.Code{ID: primitive.NewObjectID(), Type: "type", Email: "user@example.com", Code: "CODE12", CreatedAt: time.Now()}
+	c, repository := newTestController(code)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	forbidden := 0
+	for i := 0; i < maxAttempts*4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Receive(ctx, "type", "user@example.com", "WRONG1"); err == ErrForbidden {
+				mutex.Lock()
+				forbidden++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// only maxAttempts guesses are compared, the code is deleted after the last one
+	assert.Equal(t, forbidden, maxAttempts)
+	assert.Equal(t, len(repository.codes), 0)
+
+	_, err := c.Receive(ctx, "type", "user@example.com", "CODE12")
+	assert.Equal(t, err, mongo.ErrNoDocuments)
+}
+
+func TestReceiveExpired(t *testing.T) {
+	code := entities.Code{ID: primitive.NewObjectID(), Type: "type", Email: "user@example.com", Code: "CODE12", CreatedAt: time.Now().Add(-time.Hour)}
+	c, repository := newTestController(code)
+
+	_, err := c.Receive(context.Background(), "type", "user@example.com", "CODE12")
+	assert.Equal(t, err, ErrForbidden)
+	assert.Equal(t, len(repository.codes), 0)
+}
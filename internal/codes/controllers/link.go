@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+	"studyum/internal/codes/entities"
+	"time"
+)
+
+const linkSecretLength = 32
+
+// linkPayloadLength is the code id, the expiry unix time and the secret
+const linkPayloadLength = 12 + 8 + linkSecretLength
+
+// SendLink sends a single-use link instead of a code, only the hash of the link secret is stored
+func (c *controller) SendLink(ctx context.Context, code entities.Code) error {
+	if err := c.prepare(ctx, code); err != nil {
+		return err
+	}
+
+	secret := make([]byte, linkSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	code.ID = primitive.NewObjectID()
+	code.Code = hashLinkSecret(secret)
+	code.CreatedAt = time.Now()
+
+	if err := c.repository.Create(ctx, code); err != nil {
+		return err
+	}
+
+	return c.sendEmail(ctx, code, c.signLink(code.ID, code.CreatedAt.Add(c.expireTime), secret))
+}
+
+// ReceiveLink deletes and returns the code of the link, the link can be used only once
+func (c *controller) ReceiveLink(ctx context.Context, codeType entities.CodeType, token string) (entities.Code, error) {
+	id, expire, secret, ok := c.parseLink(token)
+	if !ok || expire.Before(time.Now()) {
+		return entities.Code{}, ErrForbidden
+	}
+
+	code, err := c.repository.TakeByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Code{}, ErrForbidden
+		}
+		return entities.Code{}, err
+	}
+
+	if code.Type != codeType || subtle.ConstantTimeCompare([]byte(code.Code), []byte(hashLinkSecret(secret))) != 1 {
+		return entities.Code{}, ErrForbidden
+	}
+
+	if code.CreatedAt.Add(c.expireTime).Before(time.Now()) {
+		return entities.Code{}, ErrForbidden
+	}
+
+	return code, nil
+}
+
+func (c *controller) signLink(id primitive.ObjectID, expire time.Time, secret []byte) string {
+	payload := make([]byte, linkPayloadLength)
+	copy(payload, id[:])
+	binary.BigEndian.PutUint64(payload[12:20], uint64(expire.Unix()))
+	copy(payload[20:], secret)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.mac(payload))
+}
+
+func (c *controller) parseLink(token string) (id primitive.ObjectID, expire time.Time, secret []byte, ok bool) {
+	rawPayload, rawSignature, found := strings.Cut(token, ".")
+	if !found {
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(rawPayload)
+	if err != nil || len(payload) != linkPayloadLength {
+		return
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(rawSignature)
+	if err != nil || !hmac.Equal(signature, c.mac(payload)) {
+		return
+	}
+
+	copy(id[:], payload[:12])
+	expire = time.Unix(int64(binary.BigEndian.Uint64(payload[12:20])), 0)
+	return id, expire, payload[20:], true
+}
+
+func (c *controller) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+func hashLinkSecret(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:])
+}
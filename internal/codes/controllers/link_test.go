@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkSignature(t *testing.T) {
+	c := &controller{secret: []byte("secret")}
+	id := primitive.NewObjectID()
+	expire := time.Now().Add(time.Minute).Truncate(time.Second)
+	secret := []byte(strings.Repeat("s", linkSecretLength))
+
+	token := c.signLink(id, expire, secret)
+
+	parsedID, parsedExpire, parsedSecret, ok := c.parseLink(token)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, parsedID, id)
+	assert.Equal(t, parsedExpire.Equal(expire), true)
+	assert.Equal(t, parsedSecret, secret)
+
+	// the payload can not be changed without the key
+	payload, signature, _ := strings.Cut(token, ".")
+	tampered := []byte(payload)
+	tampered[0] ^= 1
+	_, _, _, ok = c.parseLink(string(tampered) + "." + signature)
+	assert.Equal(t, ok, false)
+
+	_, _, _, ok = (&controller{secret: []byte("other")}).parseLink(token)
+	assert.Equal(t, ok, false)
+
+	_, _, _, ok = c.parseLink(payload)
+	assert.Equal(t, ok, false)
+}
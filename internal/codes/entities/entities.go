@@ -14,6 +14,7 @@ type Code struct {
 	To        string             `bson:"to"`
 	Filename  string             `bson:"filename"`
 	Type      CodeType           `bson:"type"`
+	Attempts  int                `bson:"attempts"`
 	CreatedAt time.Time          `bson:"createdAt"`
 }
 
@@ -22,4 +23,6 @@ type CodeType string
 const (
 	Verification  CodeType = "VERIFICATION"
 	PasswordReset CodeType = "PASSWORD_RESET"
+	EmailChange   CodeType = "EMAIL_CHANGE"
+	MagicLink     CodeType = "MAGIC_LINK"
 )
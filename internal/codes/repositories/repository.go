@@ -5,6 +5,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"studyum/internal/codes/entities"
 )
 
@@ -12,7 +13,9 @@ type Repository interface {
 	Create(ctx context.Context, code entities.Code) error
 	GetCodeByEmail(ctx context.Context, email string) (entities.Code, error)
 	GetCodeByUserID(ctx context.Context, id primitive.ObjectID) (entities.Code, error)
-	GetCodeAndDelete(ctx context.Context, codeType entities.CodeType, rawCode string) (entities.Code, error)
+	IncAttempts(ctx context.Context, codeType entities.CodeType, email string, maxAttempts int) (entities.Code, error)
+	DeleteByID(ctx context.Context, id primitive.ObjectID) error
+	TakeByID(ctx context.Context, id primitive.ObjectID) (entities.Code, error)
 	DeleteAllByEmail(ctx context.Context, email string) error
 	DeleteAllByUserID(ctx context.Context, id primitive.ObjectID) error
 }
@@ -40,8 +43,26 @@ func (r *repository) GetCodeByUserID(ctx context.Context, userID primitive.Objec
 	return
 }
 
-func (r *repository) GetCodeAndDelete(ctx context.Context, codeType entities.CodeType, rawCode string) (codeData entities.Code, err error) {
-	err = r.codes.FindOneAndDelete(ctx, bson.M{"code": rawCode, "type": codeType}).Decode(&codeData)
+// IncAttempts counts an attempt of the code and returns it with the attempts updated,
+// codes which have no attempts left are not found
+func (r *repository) IncAttempts(ctx context.Context, codeType entities.CodeType, email string, maxAttempts int) (code entities.Code, err error) {
+	err = r.codes.FindOneAndUpdate(
+		ctx,
+		bson.M{"type": codeType, "email": email, "attempts": bson.M{"$lt": maxAttempts}},
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&code)
+	return
+}
+
+func (r *repository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.codes.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// TakeByID deletes and returns the code, so concurrent requests can't use it twice
+func (r *repository) TakeByID(ctx context.Context, id primitive.ObjectID) (code entities.Code, err error) {
+	err = r.codes.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&code)
 	return
 }
 
@@ -16,6 +16,8 @@ type StudyPlace struct {
 	Restricted        bool               `json:"restricted" bson:"restricted"`
 	AdminID           primitive.ObjectID `json:"adminID" bson:"adminID"`
 	AbsenceMark       string             `json:"absenceMark" bson:"absenceMark"`
+
+	TwoFactorPermissions []string `json:"twoFactorPermissions" bson:"twoFactorPermissions"`
 }
 
 type MarkType struct {
@@ -50,6 +50,7 @@ func NewGeneralHandler(middleware auth.Middleware, controller controllers.Contro
 	h := &handler{Middleware: middleware, controller: controller, Group: group}
 
 	protostudyplaces.RegisterStudyPlacesServer(grpcServer, h)
+	h.GrpcPublic("/StudyPlaces/GetByID")
 
 	group.GET("/studyPlaces", h.GetStudyPlaces)
 	group.GET("/studyPlaces/:id", h.GetStudyPlaceByID)
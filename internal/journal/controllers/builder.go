@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/exp/slices"
+	"strconv"
+	general "studyum/internal/general/entities"
+	"studyum/internal/journal/entities"
+	"studyum/pkg/datetime"
+	"time"
+)
+
+func studentMarks(marks []entities.Mark, studentID primitive.ObjectID) []entities.Mark {
+	var filtered []entities.Mark
+	for _, mark := range marks {
+		if mark.StudentID == studentID {
+			filtered = append(filtered, mark)
+		}
+	}
+
+	return filtered
+}
+
+func studentAbsences(absences []entities.Absence, studentID primitive.ObjectID) []entities.Absence {
+	var filtered []entities.Absence
+	for _, absence := range absences {
+		if absence.StudentID == studentID {
+			filtered = append(filtered, absence)
+		}
+	}
+
+	return filtered
+}
+
+func lessonCell(lesson entities.Lesson, studentID primitive.ObjectID) *entities.Cell {
+	return &entities.Cell{
+		Id:               lesson.Id,
+		Version:          lesson.Version,
+		Type:             []string{lesson.Type},
+		JournalCellColor: lesson.JournalCellColor,
+		Marks:            studentMarks(lesson.Marks, studentID),
+		Absences:         studentAbsences(lesson.Absences, studentID),
+	}
+}
+
+// buildRowCells expects lessons sorted by start date and returns a cell for every lesson
+func buildRowCells(lessons []entities.Lesson, studentID primitive.ObjectID) ([]*entities.Cell, []time.Time) {
+	cells := make([]*entities.Cell, len(lessons))
+	dates := make([]time.Time, len(lessons))
+	for i, lesson := range lessons {
+		cells[i] = lessonCell(lesson, studentID)
+		dates[i] = lesson.StartDate
+	}
+
+	return cells, dates
+}
+
+// buildSubjectsJournal expects lessons sorted by start date, every lesson becomes a separate column
+func buildSubjectsJournal(option entities.AvailableOption, studyPlace general.StudyPlace, students []entities.Student, lessons []entities.Lesson) entities.Journal {
+	journal := entities.Journal{
+		Info: entities.Info{
+			StudyPlace: studyPlace,
+			Group:      option.Group,
+			Teacher:    option.Teacher,
+			Subject:    option.Subject,
+		},
+	}
+
+	if len(students) == 0 || len(lessons) == 0 {
+		return journal
+	}
+
+	journal.Dates = make([]entities.Lesson, len(lessons))
+	for i, lesson := range lessons {
+		lesson.Marks = nil
+		lesson.Absences = nil
+		lesson.JournalCellColor = ""
+
+		journal.Dates[i] = lesson
+	}
+
+	journal.Rows = make([]entities.Row, len(students))
+	for i, student := range students {
+		cells, _ := buildRowCells(lessons, student.ID)
+		journal.Rows[i] = entities.Row{
+			ID:    student.ID.Hex(),
+			Title: student.Name,
+			Cells: cells,
+		}
+	}
+
+	return journal
+}
+
+func journalDates(lessons []entities.Lesson) []time.Time {
+	var dates []time.Time
+	for _, lesson := range lessons {
+		date := datetime.ToDateWithoutTime(lesson.StartDate)
+		if !slices.Contains(dates, date) {
+			dates = append(dates, date)
+		}
+	}
+
+	slices.SortFunc(dates, func(a, b time.Time) bool {
+		return a.Before(b)
+	})
+
+	return dates
+}
+
+// buildStudentsJournal expects lessons sorted by start date, every subject becomes a row
+// and lessons of the same subject on the same day are merged into one cell, which takes the first color that is not general
+func buildStudentsJournal(studentID primitive.ObjectID, group string, studyPlace general.StudyPlace, lessons []entities.Lesson) entities.Journal {
+	journal := entities.Journal{
+		Info: entities.Info{
+			StudyPlace: studyPlace,
+			Group:      group,
+		},
+	}
+
+	if len(lessons) == 0 {
+		return journal
+	}
+
+	dates := journalDates(lessons)
+	journal.Dates = make([]entities.Lesson, len(dates))
+	for i, date := range dates {
+		journal.Dates[i] = entities.Lesson{StartDate: date, EndDate: date}
+	}
+
+	rows := map[string]*entities.Row{}
+	for _, lesson := range lessons {
+		row, ok := rows[lesson.Subject]
+		if !ok {
+			row = &entities.Row{Title: lesson.Subject, Cells: make([]*entities.Cell, len(dates))}
+			rows[lesson.Subject] = row
+		}
+
+		column := slices.Index(dates, datetime.ToDateWithoutTime(lesson.StartDate))
+		cell := lessonCell(lesson, studentID)
+
+		if row.Cells[column] == nil {
+			row.Cells[column] = cell
+			continue
+		}
+
+		if row.Cells[column].JournalCellColor == studyPlace.JournalColors.General && cell.JournalCellColor != studyPlace.JournalColors.General {
+			row.Cells[column].JournalCellColor = cell.JournalCellColor
+		}
+
+		row.Cells[column].Type = append(row.Cells[column].Type, cell.Type...)
+		row.Cells[column].Marks = append(row.Cells[column].Marks, cell.Marks...)
+		row.Cells[column].Absences = append(row.Cells[column].Absences, cell.Absences...)
+	}
+
+	journal.Rows = make([]entities.Row, 0, len(rows))
+	for _, row := range rows {
+		journal.Rows = append(journal.Rows, *row)
+	}
+
+	slices.SortFunc(journal.Rows, func(el1, el2 entities.Row) bool {
+		return el1.Title < el2.Title
+	})
+
+	return journal
+}
+
+// hideTeacherComments removes comments that are visible only for teachers
+func hideTeacherComments(journal *entities.Journal) {
+	for _, row := range journal.Rows {
+		for _, cell := range row.Cells {
+			if cell == nil {
+				continue
+			}
+
+			for i := range cell.Marks {
+				if cell.Marks[i].CommentVisibility == entities.TeacherVisibility {
+					cell.Marks[i].Comment = ""
+					cell.Marks[i].CommentVisibility = ""
+				}
+			}
+
+			for i := range cell.Absences {
+				if cell.Absences[i].CommentVisibility == entities.TeacherVisibility {
+					cell.Absences[i].Comment = ""
+					cell.Absences[i].CommentVisibility = ""
+				}
+			}
+		}
+	}
+}
+
+func lessonsSubjects(lessons []entities.Lesson) []string {
+	var subjects []string
+	for _, lesson := range lessons {
+		if !slices.Contains(subjects, lesson.Subject) {
+			subjects = append(subjects, lesson.Subject)
+		}
+	}
+
+	slices.Sort(subjects)
+	return subjects
+}
+
+// buildMarksReport counts for every student and subject lessons without the passed mark,
+// the cell looks like "without mark/lessons amount"
+func buildMarksReport(students []entities.Student, lessons []entities.Lesson, mark string) entities.GeneratedTable {
+	if len(students) == 0 || len(lessons) == 0 {
+		return entities.GeneratedTable{}
+	}
+
+	subjects := lessonsSubjects(lessons)
+
+	table := entities.GeneratedTable{
+		Titles: append(append([]string{""}, subjects...), ""),
+		Rows:   make([][]string, len(students)),
+	}
+
+	for i, student := range students {
+		row := make([]string, 0, len(subjects)+2)
+		row = append(row, student.Name)
+
+		totalLessons, totalMarks := 0, 0
+		for _, subject := range subjects {
+			lessonsAmount, marksAmount := 0, 0
+			for _, lesson := range lessons {
+				if lesson.Subject != subject {
+					continue
+				}
+
+				lessonsAmount++
+				for _, m := range studentMarks(lesson.Marks, student.ID) {
+					if m.Mark == mark {
+						marksAmount++
+					}
+				}
+			}
+
+			totalLessons += lessonsAmount
+			totalMarks += marksAmount
+			row = append(row, strconv.Itoa(lessonsAmount-marksAmount)+"/"+strconv.Itoa(lessonsAmount))
+		}
+
+		table.Rows[i] = append(row, strconv.Itoa(totalLessons-totalMarks)+"/"+strconv.Itoa(totalLessons))
+	}
+
+	return table
+}
+
+// buildAbsencesReport counts full absences (without lateness) of every student per day
+func buildAbsencesReport(students []entities.Student, lessons []entities.Lesson) entities.GeneratedTable {
+	if len(students) == 0 || len(lessons) == 0 {
+		return entities.GeneratedTable{}
+	}
+
+	dates := journalDates(lessons)
+
+	table := entities.GeneratedTable{
+		Titles: make([]string, 0, len(dates)+2),
+		Rows:   make([][]string, len(students)),
+	}
+
+	table.Titles = append(table.Titles, "")
+	for _, date := range dates {
+		table.Titles = append(table.Titles, strconv.Itoa(date.Day())+"."+strconv.Itoa(int(date.Month())))
+	}
+	table.Titles = append(table.Titles, "")
+
+	for i, student := range students {
+		absences := make([]int, len(dates))
+		for _, lesson := range lessons {
+			column := slices.Index(dates, datetime.ToDateWithoutTime(lesson.StartDate))
+			for _, absence := range studentAbsences(lesson.Absences, student.ID) {
+				if absence.Time == nil {
+					absences[column]++
+				}
+			}
+		}
+
+		row := make([]string, 0, len(dates)+2)
+		row = append(row, student.Name)
+		for _, amount := range absences {
+			if amount == 0 {
+				row = append(row, "")
+				continue
+			}
+
+			row = append(row, strconv.Itoa(amount))
+		}
+
+		table.Rows[i] = append(row, "")
+	}
+
+	return table
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	general "studyum/internal/general/entities"
+	"studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestBuildStudentsJournal(t *testing.T) {
+	student := primitive.NewObjectID()
+	day := time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	lessons := []entities.Lesson{
+		{Subject: "Math", Type: "Lecture", StartDate: day, Marks: []entities.Mark{{Mark: "5", StudentID: student}}},
+		{Subject: "Math", Type: "Practice", StartDate: day.Add(time.Hour * 2), Marks: []entities.Mark{{Mark: "4", StudentID: primitive.NewObjectID()}}},
+		{Subject: "Art", Type: "Lecture", StartDate: day.AddDate(0, 0, 1), Absences: []entities.Absence{{StudentID: student}}},
+	}
+
+	journal := buildStudentsJournal(student, "group", general.StudyPlace{}, lessons)
+
+	assert.Equal(t, len(journal.Dates), 2)
+	assert.Equal(t, len(journal.Rows), 2)
+
+	assert.Equal(t, journal.Rows[0].Title, "Art")
+	assert.Equal(t, journal.Rows[0].Cells[0], (*entities.Cell)(nil))
+	assert.Equal(t, len(journal.Rows[0].Cells[1].Absences), 1)
+
+	assert.Equal(t, journal.Rows[1].Title, "Math")
+	assert.Equal(t, journal.Rows[1].Cells[0].Type, []string{"Lecture", "Practice"})
+	assert.Equal(t, len(journal.Rows[1].Cells[0].Marks), 1)
+	assert.Equal(t, journal.Rows[1].Cells[1], (*entities.Cell)(nil))
+}
+
+func TestBuildStudentsJournalColor(t *testing.T) {
+	student := primitive.NewObjectID()
+	day := time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)
+	studyPlace := general.StudyPlace{JournalColors: general.JournalColors{General: "white", Warning: "yellow", Danger: "red"}}
+
+	lessons := []entities.Lesson{
+		{Subject: "Math", StartDate: day, JournalCellColor: "white"},
+		{Subject: "Math", StartDate: day.Add(time.Hour), JournalCellColor: "yellow"},
+		{Subject: "Math", StartDate: day.Add(time.Hour * 2), JournalCellColor: "red"},
+		{Subject: "Art", StartDate: day, JournalCellColor: "red"},
+		{Subject: "Art", StartDate: day.Add(time.Hour), JournalCellColor: "white"},
+	}
+
+	journal := buildStudentsJournal(student, "group", studyPlace, lessons)
+
+	assert.Equal(t, journal.Rows[0].Title, "Art")
+	assert.Equal(t, journal.Rows[0].Cells[0].JournalCellColor, "red")
+	assert.Equal(t, journal.Rows[1].Title, "Math")
+	assert.Equal(t, journal.Rows[1].Cells[0].JournalCellColor, "yellow")
+}
+
+func TestBuildMarksReport(t *testing.T) {
+	student := entities.Student{ID: primitive.NewObjectID(), Name: "student"}
+
+	lessons := []entities.Lesson{
+		{Subject: "Math", Marks: []entities.Mark{{Mark: "n", StudentID: student.ID}}},
+		{Subject: "Math"},
+		{Subject: "Art", Marks: []entities.Mark{{Mark: "n", StudentID: primitive.NewObjectID()}}},
+	}
+
+	table := buildMarksReport([]entities.Student{student}, lessons, "n")
+
+	assert.Equal(t, table.Titles, []string{"", "Art", "Math", ""})
+	assert.Equal(t, table.Rows, [][]string{{"student", "1/1", "1/2", "2/3"}})
+}
+
+func TestBuildAbsencesReport(t *testing.T) {
+	student := entities.Student{ID: primitive.NewObjectID(), Name: "student"}
+	late := 10
+	day := time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	lessons := []entities.Lesson{
+		{StartDate: day, Absences: []entities.Absence{{StudentID: student.ID}}},
+		{StartDate: day.Add(time.Hour), Absences: []entities.Absence{{StudentID: student.ID}}},
+		{StartDate: day.AddDate(0, 0, 1), Absences: []entities.Absence{{StudentID: student.ID, Time: &late}}},
+	}
+
+	table := buildAbsencesReport([]entities.Student{student}, lessons)
+
+	assert.Equal(t, table.Titles, []string{"", "10.1", "11.1", ""})
+	assert.Equal(t, table.Rows, [][]string{{"student", "2", "", ""}})
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	apps "studyum/internal/apps/controllers"
+	auth "studyum/internal/auth/entities"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+	"studyum/internal/journal/repositories"
+	"time"
+)
+
+var ErrCheckInClosed = errors.New("check-in is closed")
+
+const (
+	defaultCheckInPeriod = time.Second * 15
+	minCheckInPeriod     = time.Second * 5
+	maxCheckInPeriod     = time.Minute * 5
+)
+
+type CheckIn interface {
+	StartCheckIn(ctx context.Context, user auth.User, lessonIDHex string, dto dtos.StartCheckInDTO) (entities.CheckInSession, error)
+	GetCheckInCode(ctx context.Context, user auth.User, lessonIDHex string) (entities.CheckInCode, error)
+	CloseCheckIn(ctx context.Context, user auth.User, lessonIDHex string) ([]entities.Absence, error)
+
+	CheckIn(ctx context.Context, user auth.User, dto dtos.CheckInDTO) error
+
+	Run(ctx context.Context, interval time.Duration)
+	CloseExpired(ctx context.Context, now time.Time) error
+}
+
+type checkIn struct {
+	controller Controller
+	apps       apps.Controller
+
+	repository repositories.CheckInRepository
+	journal    repositories.Repository
+}
+
+func NewCheckInController(repository repositories.CheckInRepository, journal repositories.Repository, controller Controller, apps apps.Controller) CheckIn {
+	return &checkIn{repository: repository, journal: journal, controller: controller, apps: apps}
+}
+
+func (c *checkIn) code(session entities.CheckInSession, step int64) string {
+	message := make([]byte, len(session.ID)+8)
+	copy(message, session.ID[:])
+	binary.BigEndian.PutUint64(message[len(session.ID):], uint64(step))
+
+	mac := hmac.New(sha256.New, session.Secret)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func (c *checkIn) step(session entities.CheckInSession, date time.Time) int64 {
+	return int64(date.Sub(session.StartDate) / session.Period)
+}
+
+func (c *checkIn) teacherLesson(ctx context.Context, user auth.User, lessonIDHex string) (entities.Lesson, error) {
+	lessonID, err := primitive.ObjectIDFromHex(lessonIDHex)
+	if err != nil {
+		return entities.Lesson{}, errors.Wrap(NotValidParams, "lessonID")
+	}
+
+	lesson, err := c.journal.GetLessonByID(ctx, lessonID)
+	if err != nil {
+		return entities.Lesson{}, err
+	}
+
+	if lesson.StudyPlaceId != user.StudyPlaceInfo.ID || !user.Can(auth.PermissionEditJournal, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}) {
+		return entities.Lesson{}, ErrNoPermission
+	}
+
+	return lesson, nil
+}
+
+func (c *checkIn) StartCheckIn(ctx context.Context, user auth.User, lessonIDHex string, dto dtos.StartCheckInDTO) (entities.CheckInSession, error) {
+	lesson, err := c.teacherLesson(ctx, user, lessonIDHex)
+	if err != nil {
+		return entities.CheckInSession{}, err
+	}
+
+	if session, err := c.repository.GetOpenCheckInSession(ctx, lesson.Id); err == nil {
+		return session, nil
+	}
+
+	period := defaultCheckInPeriod
+	if dto.Period != 0 {
+		period = time.Duration(dto.Period) * time.Second
+	}
+
+	if period < minCheckInPeriod || period > maxCheckInPeriod {
+		return entities.CheckInSession{}, errors.Wrap(NotValidParams, "period")
+	}
+
+	now := time.Now()
+	if !now.Before(lesson.EndDate) {
+		return entities.CheckInSession{}, ErrCheckInClosed
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return entities.CheckInSession{}, err
+	}
+
+	session := entities.CheckInSession{
+		ID:           primitive.NewObjectID(),
+		StudyPlaceID: lesson.StudyPlaceId,
+		LessonID:     lesson.Id,
+		Group:        lesson.Group,
+		Teacher:      lesson.Teacher,
+		Secret:       secret,
+		Period:       period,
+		StartDate:    now,
+		EndDate:      lesson.EndDate,
+		CheckIns:     []entities.CheckIn{},
+	}
+
+	if err = c.repository.AddCheckInSession(ctx, session); err != nil {
+		return entities.CheckInSession{}, err
+	}
+
+	return session, nil
+}
+
+func (c *checkIn) GetCheckInCode(ctx context.Context, user auth.User, lessonIDHex string) (entities.CheckInCode, error) {
+	lesson, err := c.teacherLesson(ctx, user, lessonIDHex)
+	if err != nil {
+		return entities.CheckInCode{}, err
+	}
+
+	session, err := c.repository.GetOpenCheckInSession(ctx, lesson.Id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.CheckInCode{}, ErrCheckInClosed
+		}
+
+		return entities.CheckInCode{}, err
+	}
+
+	step := c.step(session, time.Now())
+	return entities.CheckInCode{
+		LessonID:  session.LessonID,
+		Code:      c.code(session, step),
+		ExpiresAt: session.StartDate.Add(session.Period * time.Duration(step+1)),
+	}, nil
+}
+
+func (c *checkIn) CloseCheckIn(ctx context.Context, user auth.User, lessonIDHex string) ([]entities.Absence, error) {
+	lesson, err := c.teacherLesson(ctx, user, lessonIDHex)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := c.repository.GetOpenCheckInSession(ctx, lesson.Id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCheckInClosed
+		}
+
+		return nil, err
+	}
+
+	return c.close(ctx, user, session, lesson)
+}
+
+// close closes the session and marks students who haven't checked in as absent in one transaction,
+// so a session is never left closed without its absences
+func (c *checkIn) close(ctx context.Context, user auth.User, session entities.CheckInSession, lesson entities.Lesson) ([]entities.Absence, error) {
+	var absences []entities.Absence
+	err := c.apps.Transaction(ctx, func(ctx context.Context) error {
+		session, err := c.repository.CloseCheckInSession(ctx, session.ID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return ErrCheckInClosed
+			}
+
+			return err
+		}
+
+		absences, err = c.addAbsences(ctx, user, session, lesson)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return absences, nil
+}
+
+// addAbsences marks students who haven't checked in as absent
+func (c *checkIn) addAbsences(ctx context.Context, user auth.User, session entities.CheckInSession, lesson entities.Lesson) ([]entities.Absence, error) {
+	students, err := c.journal.GetStudents(ctx, session.StudyPlaceID, session.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[primitive.ObjectID]bool, len(session.CheckIns)+len(lesson.Absences))
+	for _, checkIn := range session.CheckIns {
+		present[checkIn.StudentID] = true
+	}
+	for _, absence := range lesson.Absences {
+		present[absence.StudentID] = true
+	}
+
+	var absences []dtos.AddAbsencesDTO
+	for _, student := range students {
+		if present[student.ID] {
+			continue
+		}
+
+		absences = append(absences, dtos.AddAbsencesDTO{
+			StudentID: student.ID,
+			LessonID:  lesson.Id,
+		})
+	}
+
+	if len(absences) == 0 {
+		return []entities.Absence{}, nil
+	}
+
+	return c.controller.AddAbsences(ctx, absences, user)
+}
+
+// Run closes sessions of ended lessons every interval, so absences are added even if the teacher doesn't close the check-in
+func (c *checkIn) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.CloseExpired(ctx, now); err != nil {
+				logrus.Warningln("Error closing check-in sessions: " + err.Error())
+			}
+		}
+	}
+}
+
+// CloseExpired closes open sessions whose lesson has ended by the time now,
+// absences are added on behalf of the study place as the teacher is not around.
+// A session which fails to close is logged and retried on the next run without blocking the others
+func (c *checkIn) CloseExpired(ctx context.Context, now time.Time) error {
+	sessions, err := c.repository.GetExpiredCheckInSessions(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err = c.closeExpired(ctx, session); err != nil && !errors.Is(err, ErrCheckInClosed) {
+			logrus.Warningln("Error closing check-in session " + session.ID.Hex() + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (c *checkIn) closeExpired(ctx context.Context, session entities.CheckInSession) error {
+	lesson, err := c.journal.GetLessonByID(ctx, session.LessonID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		// the lesson was deleted, there is nobody to mark absent
+		_, err = c.repository.CloseCheckInSession(ctx, session.ID)
+		return err
+	}
+
+	user := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{
+		ID:          session.StudyPlaceID,
+		Permissions: []string{auth.PermissionEditJournal},
+	}}
+	_, err = c.close(ctx, user, session, lesson)
+	return err
+}
+
+func (c *checkIn) CheckIn(ctx context.Context, user auth.User, dto dtos.CheckInDTO) error {
+	if dto.LessonID.IsZero() || dto.Code == "" {
+		return NotValidParams
+	}
+
+	session, err := c.repository.GetOpenCheckInSession(ctx, dto.LessonID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrCheckInClosed
+		}
+
+		return err
+	}
+
+	if session.StudyPlaceID != user.StudyPlaceInfo.ID || user.StudyPlaceInfo.Role != "group" || user.StudyPlaceInfo.RoleName != session.Group {
+		return ErrNoPermission
+	}
+
+	now := time.Now()
+	if now.After(session.EndDate) {
+		return ErrCheckInClosed
+	}
+
+	step := c.step(session, now)
+	if !hmac.Equal([]byte(dto.Code), []byte(c.code(session, step))) &&
+		!(step > 0 && hmac.Equal([]byte(dto.Code), []byte(c.code(session, step-1)))) {
+		return errors.Wrap(NotValidParams, "code")
+	}
+
+	return c.repository.AddCheckIn(ctx, session.ID, entities.CheckIn{
+		StudentID: user.Id,
+		Date:      now,
+	})
+}
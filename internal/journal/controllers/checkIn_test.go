@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	auth "studyum/internal/auth/entities"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestCheckIn_Code(t *testing.T) {
+	c := &checkIn{}
+	start := time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)
+	session := entities.CheckInSession{
+		ID:        primitive.ObjectID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		Secret:    []byte("secret"),
+		Period:    time.Second * 15,
+		StartDate: start,
+	}
+
+	assert.Equal(t, c.step(session, start.Add(time.Second*14)), int64(0))
+	assert.Equal(t, c.step(session, start.Add(time.Second*15)), int64(1))
+
+	code := c.code(session, 1)
+	assert.Equal(t, len(code), 6)
+	assert.Equal(t, c.code(session, 1), code)
+	assert.NotEqual(t, c.code(session, 2), code)
+
+	session.Secret = []byte("another secret")
+	assert.NotEqual(t, c.code(session, 1), code)
+}
+
+func TestCheckInSessionJSON(t *testing.T) {
+	data, err := json.Marshal(entities.CheckInSession{Period: time.Second * 15, Secret: []byte("secret")})
+	assert.Equal(t, err, nil)
+
+	var session map[string]any
+	assert.Equal(t, json.Unmarshal(data, &session), nil)
+	assert.Equal(t, session["period"], float64(15))
+	_, ok := session["secret"]
+	assert.Equal(t, ok, false)
+}
+
+func TestCloseExpiredCheckIns(t *testing.T) {
+	now := time.Date(2023, 1, 10, 10, 0, 0, 0, time.UTC)
+	studyPlaceID := primitive.NewObjectID()
+	present, absent, excused := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	lesson := entities.Lesson{Id: primitive.NewObjectID(), StudyPlaceId: studyPlaceID, Absences: []entities.Absence{{StudentID: excused}}}
+	repository := &testCheckInRepository{sessions: []entities.CheckInSession{
+		{ID: primitive.NewObjectID(), StudyPlaceID: studyPlaceID, LessonID: lesson.Id, EndDate: now.Add(-time.Minute), CheckIns: []entities.CheckIn{{StudentID: present}}},
+		{ID: primitive.NewObjectID(), StudyPlaceID: studyPlaceID, LessonID: lesson.Id, EndDate: now.Add(time.Minute)},
+	}}
+	journal := &testRepository{lessons: []entities.Lesson{lesson}, students: []entities.Student{{ID: present}, {ID: absent}, {ID: excused}}}
+	controller := &testController{}
+	c := &checkIn{repository: repository, journal: journal, controller: controller, apps: &testApps{}}
+
+	assert.Equal(t, c.CloseExpired(context.Background(), now), nil)
+
+	assert.Equal(t, repository.sessions[0].Closed, true)
+	assert.Equal(t, repository.sessions[1].Closed, false)
+	assert.Equal(t, controller.absences, []dtos.AddAbsencesDTO{{StudentID: absent, LessonID: lesson.Id}})
+	assert.Equal(t, controller.user.StudyPlaceInfo.ID, studyPlaceID)
+	assert.Equal(t, controller.user.Can(auth.PermissionEditJournal, auth.Resource{Teacher: "teacher"}), true)
+
+	// closed sessions are not swept again
+	controller.absences = nil
+	assert.Equal(t, c.CloseExpired(context.Background(), now), nil)
+	assert.Equal(t, len(controller.absences), 0)
+}
+
+func TestCloseExpiredCheckInsFailures(t *testing.T) {
+	now := time.Date(2023, 1, 10, 10, 0, 0, 0, time.UTC)
+	studyPlaceID := primitive.NewObjectID()
+	student := primitive.NewObjectID()
+
+	lesson := entities.Lesson{Id: primitive.NewObjectID(), StudyPlaceId: studyPlaceID}
+	repository := &testCheckInRepository{sessions: []entities.CheckInSession{
+		{ID: primitive.NewObjectID(), StudyPlaceID: studyPlaceID, LessonID: primitive.NewObjectID(), EndDate: now.Add(-time.Minute)},
+		{ID: primitive.NewObjectID(), StudyPlaceID: studyPlaceID, LessonID: lesson.Id, EndDate: now.Add(-time.Minute)},
+	}}
+	journal := &testRepository{lessons: []entities.Lesson{lesson}, students: []entities.Student{{ID: student}}}
+	controller := &testController{err: errors.New("write failed")}
+	c := &checkIn{repository: repository, journal: journal, controller: controller, apps: &testApps{}}
+
+	// the session of a deleted lesson is closed without absences,
+	// a session whose absences are not added stays open for the next run
+	assert.Equal(t, c.CloseExpired(context.Background(), now), nil)
+	assert.Equal(t, repository.sessions[0].Closed, true)
+	assert.Equal(t, repository.sessions[1].Closed, false)
+
+	controller.err = nil
+	assert.Equal(t, c.CloseExpired(context.Background(), now), nil)
+	assert.Equal(t, repository.sessions[1].Closed, true)
+	assert.Equal(t, controller.absences, []dtos.AddAbsencesDTO{{StudentID: student, LessonID: lesson.Id}})
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/xuri/excelize/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/exp/slices"
+	"io"
 	"strconv"
 	apps "studyum/internal/apps/controllers"
 	auth "studyum/internal/auth/entities"
@@ -18,20 +20,24 @@ import (
 
 var NotValidParams = errors.New("not valid params")
 var ErrNoPermission = errors.New("no permission")
+var ErrConflict = errors.New("version conflict")
 
 type Controller interface {
 	AddMarks(ctx context.Context, marks []dtos.AddMarkDTO, user auth.User) ([]entities.Mark, error)
 	AddMark(ctx context.Context, dto dtos.AddMarkDTO, user auth.User) (entities.CellResponse, error)
 	UpdateMark(ctx context.Context, user auth.User, dto dtos.UpdateMarkDTO) (entities.CellResponse, error)
-	DeleteMark(ctx context.Context, user auth.User, markIdHex string) (entities.CellResponse, error)
+	DeleteMark(ctx context.Context, user auth.User, markIdHex string, version int) (entities.CellResponse, error)
 
 	AddAbsences(ctx context.Context, dto []dtos.AddAbsencesDTO, user auth.User) ([]entities.Absence, error)
 	AddAbsence(ctx context.Context, absencesDTO dtos.AddAbsencesDTO, user auth.User) (entities.CellResponse, error)
 	UpdateAbsence(ctx context.Context, user auth.User, absences dtos.UpdateAbsencesDTO) (entities.CellResponse, error)
-	DeleteAbsence(ctx context.Context, user auth.User, id string) (entities.CellResponse, error)
+	DeleteAbsence(ctx context.Context, user auth.User, id string, version int) (entities.CellResponse, error)
 
 	GenerateMarksReport(ctx context.Context, config dtos.MarksReport, user auth.User) (*excelize.File, error)
 	GenerateAbsencesReport(ctx context.Context, config dtos.AbsencesReport, user auth.User) (*excelize.File, error)
+
+	PreviewJournalImport(ctx context.Context, user auth.User, group, subject, teacher string, file io.Reader) (entities.ImportPreview, error)
+	ImportJournal(ctx context.Context, user auth.User, group, subject, teacher string, file io.Reader) (entities.ImportResult, error)
 }
 
 type controller struct {
@@ -47,11 +53,18 @@ func NewController(journal Journal, repository repositories.Repository, encrypt
 }
 
 func (j *controller) GenerateMarksReport(ctx context.Context, config dtos.MarksReport, user auth.User) (*excelize.File, error) {
-	table, err := j.repository.GenerateMarksReport(ctx, user.StudyPlaceInfo.TuitionGroup, config.LessonType, config.Mark, config.StartDate, config.EndDate, user.StudyPlaceInfo.ID)
+	students, err := j.repository.GetStudents(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.TuitionGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	lessons, err := j.repository.GetReportLessons(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.TuitionGroup, config.LessonType, config.StartDate, config.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
+	table := buildMarksReport(students, lessons, config.Mark)
+
 	for i := range table.Rows {
 		table.Rows[i][0] = j.encrypt.DecryptString(table.Rows[i][0])
 	}
@@ -94,11 +107,18 @@ func (j *controller) GenerateMarksReport(ctx context.Context, config dtos.MarksR
 }
 
 func (j *controller) GenerateAbsencesReport(ctx context.Context, config dtos.AbsencesReport, user auth.User) (*excelize.File, error) {
-	table, err := j.repository.GenerateAbsencesReport(ctx, user.StudyPlaceInfo.TuitionGroup, config.StartDate, config.EndDate, user.StudyPlaceInfo.ID)
+	students, err := j.repository.GetStudents(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.TuitionGroup)
 	if err != nil {
 		return nil, err
 	}
 
+	lessons, err := j.repository.GetReportLessons(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.TuitionGroup, "", config.StartDate, config.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	table := buildAbsencesReport(students, lessons)
+
 	for i := range table.Rows {
 		table.Rows[i][0] = j.encrypt.DecryptString(table.Rows[i][0])
 	}
@@ -170,6 +190,81 @@ func (j *controller) checkMarkExistence(ctx context.Context, mark dtos.AddMarkDT
 	return false
 }
 
+func (j *controller) commentVisibility(comment string, visibility entities.CommentVisibility) (entities.CommentVisibility, error) {
+	if comment == "" {
+		return "", nil
+	}
+
+	switch visibility {
+	case "":
+		return entities.StudentVisibility, nil
+	case entities.StudentVisibility, entities.TeacherVisibility:
+		return visibility, nil
+	default:
+		return "", errors.Wrap(NotValidParams, "commentVisibility")
+	}
+}
+
+// writeError resolves a failed versioned write, if the item was changed or removed since the passed version
+// it returns ErrConflict with the current cell state
+func (j *controller) writeError(ctx context.Context, err error, version int, currentVersion func() (int, error), studentID, lessonID primitive.ObjectID) (entities.CellResponse, error) {
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.CellResponse{}, err
+	}
+
+	if current, err := currentVersion(); err == nil && current == version {
+		return entities.CellResponse{}, ErrNoPermission
+	}
+
+	cell, err := j.journal.GetUpdateInfo(ctx, studentID, lessonID)
+	if err != nil {
+		return entities.CellResponse{}, err
+	}
+
+	return cell, ErrConflict
+}
+
+func (j *controller) markVersion(ctx context.Context, id primitive.ObjectID) func() (int, error) {
+	return func() (int, error) {
+		mark, err := j.repository.GetMarkByID(ctx, id)
+		return mark.Version, err
+	}
+}
+
+func (j *controller) absenceVersion(ctx context.Context, id primitive.ObjectID) func() (int, error) {
+	return func() (int, error) {
+		absence, err := j.repository.GetAbsenceByID(ctx, id)
+		return absence.Version, err
+	}
+}
+
+// invalidateLessonJournals invalidates the journals of the lesson once the current transaction is committed,
+// a journal read before the commit would be cached again under the new generation otherwise
+func (j *controller) invalidateLessonJournals(ctx context.Context, lessonID primitive.ObjectID) {
+	lesson, err := j.repository.GetLessonByID(ctx, lessonID)
+	if err != nil {
+		return
+	}
+
+	j.apps.AfterCommit(ctx, func(ctx context.Context) {
+		j.journal.InvalidateGroup(ctx, lesson.StudyPlaceId, lesson.Group)
+	})
+}
+
+// editableLesson returns the lesson if the user may edit its journal
+func (j *controller) editableLesson(ctx context.Context, user auth.User, lessonID primitive.ObjectID) (entities.Lesson, error) {
+	lesson, err := j.repository.GetLessonByID(ctx, lessonID)
+	if err != nil {
+		return entities.Lesson{}, err
+	}
+
+	if lesson.StudyPlaceId != user.StudyPlaceInfo.ID || !user.Can(auth.PermissionEditJournal, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}) {
+		return entities.Lesson{}, ErrNoPermission
+	}
+
+	return lesson, nil
+}
+
 func (j *controller) AddMarks(ctx context.Context, addDTO []dtos.AddMarkDTO, user auth.User) ([]entities.Mark, error) {
 	marks := make([]entities.Mark, len(addDTO))
 	for i, markDTO := range addDTO {
@@ -177,19 +272,39 @@ func (j *controller) AddMarks(ctx context.Context, addDTO []dtos.AddMarkDTO, use
 			return nil, NotValidParams
 		}
 
+		lesson, err := j.editableLesson(ctx, user, markDTO.LessonID)
+		if err != nil {
+			return nil, err
+		}
+
+		visibility, err := j.commentVisibility(markDTO.Comment, markDTO.CommentVisibility)
+		if err != nil {
+			return nil, err
+		}
+
 		mark := entities.Mark{
-			ID:           primitive.NewObjectID(),
-			Mark:         markDTO.Mark,
-			StudentID:    markDTO.StudentID,
-			LessonID:     markDTO.LessonID,
-			StudyPlaceID: user.StudyPlaceInfo.ID,
+			ID:                primitive.NewObjectID(),
+			Mark:              markDTO.Mark,
+			Comment:           markDTO.Comment,
+			CommentVisibility: visibility,
+			Version:           1,
+			StudentID:         markDTO.StudentID,
+			LessonID:          markDTO.LessonID,
+			StudyPlaceID:      user.StudyPlaceInfo.ID,
 		}
 
-		if err := j.repository.AddMark(ctx, mark, user.StudyPlaceInfo.RoleName); err != nil {
+		err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+			if err := j.repository.AddMark(ctx, mark, lesson.Teacher); err != nil {
+				return err
+			}
+
+			return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddMark", mark, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+		})
+		if err != nil {
 			return nil, err
 		}
 
-		j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddMark", mark)
+		j.invalidateLessonJournals(ctx, mark.LessonID)
 
 		marks[i] = mark
 	}
@@ -202,19 +317,39 @@ func (j *controller) AddMark(ctx context.Context, addDTO dtos.AddMarkDTO, user a
 		return entities.CellResponse{}, NotValidParams
 	}
 
-	mark := entities.Mark{
-		ID:           primitive.NewObjectID(),
-		Mark:         addDTO.Mark,
-		StudentID:    addDTO.StudentID,
-		LessonID:     addDTO.LessonID,
-		StudyPlaceID: user.StudyPlaceInfo.ID,
+	lesson, err := j.editableLesson(ctx, user, addDTO.LessonID)
+	if err != nil {
+		return entities.CellResponse{}, err
+	}
+
+	visibility, err := j.commentVisibility(addDTO.Comment, addDTO.CommentVisibility)
+	if err != nil {
+		return entities.CellResponse{}, err
 	}
 
-	if err := j.repository.AddMark(ctx, mark, user.StudyPlaceInfo.RoleName); err != nil {
+	mark := entities.Mark{
+		ID:                primitive.NewObjectID(),
+		Mark:              addDTO.Mark,
+		Comment:           addDTO.Comment,
+		CommentVisibility: visibility,
+		Version:           1,
+		StudentID:         addDTO.StudentID,
+		LessonID:          addDTO.LessonID,
+		StudyPlaceID:      user.StudyPlaceInfo.ID,
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.repository.AddMark(ctx, mark, lesson.Teacher); err != nil {
+			return err
+		}
+
+		return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddMark", mark, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+	})
+	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddMark", mark)
+	j.invalidateLessonJournals(ctx, mark.LessonID)
 
 	return j.journal.GetUpdateInfo(ctx, mark.StudentID, mark.LessonID)
 }
@@ -224,23 +359,46 @@ func (j *controller) UpdateMark(ctx context.Context, user auth.User, updateDTO d
 		return entities.CellResponse{}, NotValidParams
 	}
 
-	mark := entities.Mark{
-		ID:        updateDTO.ID,
-		Mark:      updateDTO.Mark,
-		StudentID: updateDTO.StudentID,
-		LessonID:  updateDTO.LessonID,
+	lesson, err := j.editableLesson(ctx, user, updateDTO.LessonID)
+	if err != nil {
+		return entities.CellResponse{}, err
 	}
 
-	if err := j.repository.UpdateMark(ctx, mark, user.StudyPlaceInfo.RoleName); err != nil {
+	visibility, err := j.commentVisibility(updateDTO.Comment, updateDTO.CommentVisibility)
+	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "UpdateMark", mark)
+	mark := entities.Mark{
+		ID:                updateDTO.ID,
+		Mark:              updateDTO.Mark,
+		Comment:           updateDTO.Comment,
+		CommentVisibility: visibility,
+		Version:           updateDTO.Version,
+		StudentID:         updateDTO.StudentID,
+		LessonID:          updateDTO.LessonID,
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.repository.UpdateMark(ctx, mark, lesson.Teacher); err != nil {
+			return err
+		}
+
+		updated := mark
+		updated.Version++
+
+		return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "UpdateMark", updated, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+	})
+	if err != nil {
+		return j.writeError(ctx, err, updateDTO.Version, j.markVersion(ctx, mark.ID), mark.StudentID, mark.LessonID)
+	}
+
+	j.invalidateLessonJournals(ctx, mark.LessonID)
 
 	return j.journal.GetUpdateInfo(ctx, mark.StudentID, mark.LessonID)
 }
 
-func (j *controller) DeleteMark(ctx context.Context, user auth.User, markIdHex string) (entities.CellResponse, error) {
+func (j *controller) DeleteMark(ctx context.Context, user auth.User, markIdHex string, version int) (entities.CellResponse, error) {
 	markId, err := primitive.ObjectIDFromHex(markIdHex)
 	if err != nil || markId == primitive.NilObjectID {
 		return entities.CellResponse{}, errors.Wrap(NotValidParams, "markId")
@@ -251,12 +409,28 @@ func (j *controller) DeleteMark(ctx context.Context, user auth.User, markIdHex s
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.Event(user.StudyPlaceInfo.ID, "RemoveMark", mark)
-
-	if err = j.repository.DeleteMarkByID(ctx, markId, user.StudyPlaceInfo.RoleName); err != nil {
+	lesson, err := j.editableLesson(ctx, user, mark.LessonID)
+	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
+	if mark.Version != version {
+		return j.writeError(ctx, mongo.ErrNoDocuments, version, j.markVersion(ctx, markId), mark.StudentID, mark.LessonID)
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.apps.Event(ctx, user.StudyPlaceInfo.ID, "RemoveMark", mark, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}); err != nil {
+			return err
+		}
+
+		return j.repository.DeleteMarkByID(ctx, markId, version, lesson.Teacher)
+	})
+	if err != nil {
+		return j.writeError(ctx, err, version, j.markVersion(ctx, markId), mark.StudentID, mark.LessonID)
+	}
+
+	j.invalidateLessonJournals(ctx, mark.LessonID)
+
 	return j.journal.GetUpdateInfo(ctx, mark.StudentID, mark.LessonID)
 }
 
@@ -267,19 +441,39 @@ func (j *controller) AddAbsences(ctx context.Context, dto []dtos.AddAbsencesDTO,
 			return nil, NotValidParams
 		}
 
+		lesson, err := j.editableLesson(ctx, user, markDTO.LessonID)
+		if err != nil {
+			return nil, err
+		}
+
+		visibility, err := j.commentVisibility(markDTO.Comment, markDTO.CommentVisibility)
+		if err != nil {
+			return nil, err
+		}
+
 		absence := entities.Absence{
-			ID:           primitive.NewObjectID(),
-			Time:         markDTO.Time,
-			StudentID:    markDTO.StudentID,
-			LessonID:     markDTO.LessonID,
-			StudyPlaceID: user.StudyPlaceInfo.ID,
+			ID:                primitive.NewObjectID(),
+			Time:              markDTO.Time,
+			Comment:           markDTO.Comment,
+			CommentVisibility: visibility,
+			Version:           1,
+			StudentID:         markDTO.StudentID,
+			LessonID:          markDTO.LessonID,
+			StudyPlaceID:      user.StudyPlaceInfo.ID,
 		}
 
-		if err := j.repository.AddAbsence(ctx, absence, user.StudyPlaceInfo.RoleName); err != nil {
+		err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+			if err := j.repository.AddAbsence(ctx, absence, lesson.Teacher); err != nil {
+				return err
+			}
+
+			return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddAbsence", absence, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+		})
+		if err != nil {
 			return nil, err
 		}
 
-		j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddAbsence", absence)
+		j.invalidateLessonJournals(ctx, absence.LessonID)
 
 		absences[i] = absence
 	}
@@ -292,20 +486,39 @@ func (j *controller) AddAbsence(ctx context.Context, dto dtos.AddAbsencesDTO, us
 		return entities.CellResponse{}, NotValidParams
 	}
 
-	absence := entities.Absence{
-		ID:           primitive.NewObjectID(),
-		Time:         dto.Time,
-		StudentID:    dto.StudentID,
-		LessonID:     dto.LessonID,
-		StudyPlaceID: user.StudyPlaceInfo.ID,
+	lesson, err := j.editableLesson(ctx, user, dto.LessonID)
+	if err != nil {
+		return entities.CellResponse{}, err
+	}
+
+	visibility, err := j.commentVisibility(dto.Comment, dto.CommentVisibility)
+	if err != nil {
+		return entities.CellResponse{}, err
 	}
 
-	err := j.repository.AddAbsence(ctx, absence, user.StudyPlaceInfo.RoleName)
+	absence := entities.Absence{
+		ID:                primitive.NewObjectID(),
+		Time:              dto.Time,
+		Comment:           dto.Comment,
+		CommentVisibility: visibility,
+		Version:           1,
+		StudentID:         dto.StudentID,
+		LessonID:          dto.LessonID,
+		StudyPlaceID:      user.StudyPlaceInfo.ID,
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.repository.AddAbsence(ctx, absence, lesson.Teacher); err != nil {
+			return err
+		}
+
+		return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddAbsence", absence, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+	})
 	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddAbsence", absence)
+	j.invalidateLessonJournals(ctx, absence.LessonID)
 
 	return j.journal.GetUpdateInfo(ctx, absence.StudentID, absence.LessonID)
 }
@@ -315,24 +528,47 @@ func (j *controller) UpdateAbsence(ctx context.Context, user auth.User, dto dtos
 		return entities.CellResponse{}, NotValidParams
 	}
 
-	absence := entities.Absence{
-		ID:           dto.ID,
-		Time:         dto.Time,
-		StudentID:    dto.StudentID,
-		LessonID:     dto.LessonID,
-		StudyPlaceID: user.StudyPlaceInfo.ID,
+	lesson, err := j.editableLesson(ctx, user, dto.LessonID)
+	if err != nil {
+		return entities.CellResponse{}, err
 	}
 
-	if err := j.repository.UpdateAbsence(ctx, absence, user.StudyPlaceInfo.RoleName); err != nil {
+	visibility, err := j.commentVisibility(dto.Comment, dto.CommentVisibility)
+	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "UpdateAbsence", absence)
+	absence := entities.Absence{
+		ID:                dto.ID,
+		Time:              dto.Time,
+		Comment:           dto.Comment,
+		CommentVisibility: visibility,
+		Version:           dto.Version,
+		StudentID:         dto.StudentID,
+		LessonID:          dto.LessonID,
+		StudyPlaceID:      user.StudyPlaceInfo.ID,
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.repository.UpdateAbsence(ctx, absence, lesson.Teacher); err != nil {
+			return err
+		}
+
+		updated := absence
+		updated.Version++
+
+		return j.apps.Event(ctx, user.StudyPlaceInfo.ID, "UpdateAbsence", updated, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group})
+	})
+	if err != nil {
+		return j.writeError(ctx, err, dto.Version, j.absenceVersion(ctx, absence.ID), absence.StudentID, absence.LessonID)
+	}
+
+	j.invalidateLessonJournals(ctx, absence.LessonID)
 
 	return j.journal.GetUpdateInfo(ctx, absence.StudentID, absence.LessonID)
 }
 
-func (j *controller) DeleteAbsence(ctx context.Context, user auth.User, idHex string) (entities.CellResponse, error) {
+func (j *controller) DeleteAbsence(ctx context.Context, user auth.User, idHex string, version int) (entities.CellResponse, error) {
 	id, err := primitive.ObjectIDFromHex(idHex)
 	if err != nil {
 		return entities.CellResponse{}, errors.Wrap(NotValidParams, "markId")
@@ -343,11 +579,27 @@ func (j *controller) DeleteAbsence(ctx context.Context, user auth.User, idHex st
 		return entities.CellResponse{}, err
 	}
 
-	j.apps.AsyncEvent(user.StudyPlaceInfo.ID, "RemoveAbsence", entities.DeleteAbsenceID{ID: absence.ID})
-
-	if err = j.repository.DeleteAbsenceByID(ctx, id, user.StudyPlaceInfo.RoleName); err != nil {
+	lesson, err := j.editableLesson(ctx, user, absence.LessonID)
+	if err != nil {
 		return entities.CellResponse{}, err
 	}
 
+	if absence.Version != version {
+		return j.writeError(ctx, mongo.ErrNoDocuments, version, j.absenceVersion(ctx, id), absence.StudentID, absence.LessonID)
+	}
+
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := j.apps.Event(ctx, user.StudyPlaceInfo.ID, "RemoveAbsence", absence, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}); err != nil {
+			return err
+		}
+
+		return j.repository.DeleteAbsenceByID(ctx, id, version, lesson.Teacher)
+	})
+	if err != nil {
+		return j.writeError(ctx, err, version, j.absenceVersion(ctx, id), absence.StudentID, absence.LessonID)
+	}
+
+	j.invalidateLessonJournals(ctx, absence.LessonID)
+
 	return j.journal.GetUpdateInfo(ctx, absence.StudentID, absence.LessonID)
 }
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	auth "studyum/internal/auth/entities"
+	general "studyum/internal/general/entities"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+	"testing"
+)
+
+func newTestVersionController(marks ...entities.Mark) (*controller, *testRepository, *testApps, auth.User) {
+	studyPlaceID := primitive.NewObjectID()
+	lesson := entities.Lesson{Id: primitive.NewObjectID(), StudyPlaceId: studyPlaceID, Type: "Lecture", Teacher: "Teacher", Group: "Group", Marks: marks}
+	for i := range lesson.Marks {
+		lesson.Marks[i].LessonID = lesson.Id
+	}
+
+	repository := &testRepository{
+		lessons:    []entities.Lesson{lesson},
+		studyPlace: general.StudyPlace{LessonTypes: []general.LessonType{{Type: "Lecture", Marks: []general.MarkType{{Mark: "5"}, {Mark: "4"}}}}},
+	}
+	events := &testApps{}
+	c := &controller{journal: &testJournal{repository: repository}, apps: events, repository: repository}
+	user := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{ID: studyPlaceID, Permissions: []string{auth.PermissionEditJournal}}}
+
+	return c, repository, events, user
+}
+
+func TestUpdateMarkVersion(t *testing.T) {
+	mark := entities.Mark{ID: primitive.NewObjectID(), Mark: "5", Version: 1, StudentID: primitive.NewObjectID()}
+	c, repository, events, user := newTestVersionController(mark)
+
+	update := dtos.UpdateMarkDTO{ID: mark.ID, Version: 1, AddMarkDTO: dtos.AddMarkDTO{Mark: "4", StudentID: mark.StudentID, LessonID: repository.lessons[0].Id}}
+	cell, err := c.UpdateMark(context.Background(), user, update)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, cell.Cell.Marks[0].Mark, "4")
+	assert.Equal(t, cell.Cell.Marks[0].Version, 2)
+	assert.Equal(t, events.events[0].(entities.Mark).Version, 2)
+
+	// the stale version is rejected with the current cell
+	update.Mark = "5"
+	cell, err = c.UpdateMark(context.Background(), user, update)
+	assert.Equal(t, errors.Is(err, ErrConflict), true)
+	assert.Equal(t, cell.Cell.Marks[0].Mark, "4")
+	assert.Equal(t, cell.Cell.Marks[0].Version, 2)
+
+	// omitting the version doesn't skip the check
+	update.Version = 0
+	cell, err = c.UpdateMark(context.Background(), user, update)
+	assert.Equal(t, errors.Is(err, ErrConflict), true)
+	assert.Equal(t, cell.Cell.Marks[0].Mark, "4")
+	assert.Equal(t, len(events.events), 1)
+}
+
+func TestUpdateMarkWithoutVersion(t *testing.T) {
+	mark := entities.Mark{ID: primitive.NewObjectID(), Mark: "5", StudentID: primitive.NewObjectID()}
+	c, repository, _, user := newTestVersionController(mark)
+
+	update := dtos.UpdateMarkDTO{ID: mark.ID, AddMarkDTO: dtos.AddMarkDTO{Mark: "4", StudentID: mark.StudentID, LessonID: repository.lessons[0].Id}}
+	cell, err := c.UpdateMark(context.Background(), user, update)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, cell.Cell.Marks[0].Version, 1)
+}
+
+func TestDeleteMarkVersion(t *testing.T) {
+	mark := entities.Mark{ID: primitive.NewObjectID(), Mark: "5", Version: 2, StudentID: primitive.NewObjectID()}
+	c, repository, _, user := newTestVersionController(mark)
+
+	for _, version := range []int{0, 1} {
+		cell, err := c.DeleteMark(context.Background(), user, mark.ID.Hex(), version)
+		assert.Equal(t, errors.Is(err, ErrConflict), true)
+		assert.Equal(t, len(cell.Cell.Marks), 1)
+		assert.Equal(t, cell.Cell.Marks[0].Version, 2)
+	}
+
+	cell, err := c.DeleteMark(context.Background(), user, mark.ID.Hex(), 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(cell.Cell.Marks), 0)
+	assert.Equal(t, len(repository.lessons[0].Marks), 0)
+}
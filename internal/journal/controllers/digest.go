@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"html"
+	"strings"
+	auth "studyum/internal/auth/entities"
+	"studyum/internal/journal/entities"
+	"studyum/internal/journal/repositories"
+	"studyum/pkg/encryption"
+	"studyum/pkg/firebase"
+	"studyum/pkg/mail"
+	"time"
+)
+
+// digestLease is how long an instance may send digests before another one can take over
+const digestLease = time.Hour
+
+// digestCheckInterval is how often instances check if a digest is due
+const digestCheckInterval = time.Minute
+
+type Digest interface {
+	SendDigests(ctx context.Context, since time.Time) error
+	Process(ctx context.Context, schedule cron.Schedule, now time.Time) error
+	Run(ctx context.Context, pattern string)
+}
+
+type digest struct {
+	repository repositories.Repository
+	digests    repositories.DigestRepository
+	encrypt    encryption.Encryption
+	mailer     mail.Mail
+	firebase   firebase.Firebase
+}
+
+func NewDigestController(repository repositories.Repository, digests repositories.DigestRepository, encrypt encryption.Encryption, mailer mail.Mail, firebase firebase.Firebase) Digest {
+	return &digest{repository: repository, digests: digests, encrypt: encrypt, mailer: mailer, firebase: firebase}
+}
+
+// Run sends guardian digests on the schedule of the cron pattern until the context is done
+func (d *digest) Run(ctx context.Context, pattern string) {
+	schedule, err := cron.ParseStandard(pattern)
+	if err != nil {
+		logrus.Errorf("Not valid guardian digest schedule %s: %s", pattern, err.Error())
+		return
+	}
+
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err = d.Process(ctx, schedule, now); err != nil {
+				logrus.Warningln("Error sending guardian digests: " + err.Error())
+			}
+		}
+	}
+}
+
+// Process sends digests with everything added since the last digest if the schedule is due by the time now.
+// The digest is locked, so only one instance sends it, the first run only saves the time to start from
+func (d *digest) Process(ctx context.Context, schedule cron.Schedule, now time.Time) error {
+	state, err := d.digests.LockDigest(ctx, now, now.Add(digestLease))
+	if err != nil {
+		if errors.Is(err, repositories.ErrDigestLocked) {
+			return nil
+		}
+		return err
+	}
+
+	if state.SentAt.IsZero() {
+		return d.digests.UnlockDigest(ctx, now)
+	}
+
+	if now.Before(schedule.Next(state.SentAt)) {
+		return d.digests.UnlockDigest(ctx, state.SentAt)
+	}
+
+	if err = d.SendDigests(ctx, state.SentAt); err != nil {
+		_ = d.digests.UnlockDigest(ctx, state.SentAt)
+		return err
+	}
+
+	return d.digests.UnlockDigest(ctx, now)
+}
+
+// SendDigests sends every guardian marks and absences of their children added after since
+func (d *digest) SendDigests(ctx context.Context, since time.Time) error {
+	guardians, err := d.repository.GetGuardians(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, guardian := range guardians {
+		d.encrypt.Decrypt(&guardian)
+
+		for _, childID := range guardian.Children {
+			if err = d.sendChildDigest(ctx, guardian, childID, since); err != nil {
+				logrus.Warningf("Error sending digest to guardian %s: %s", guardian.Id.Hex(), err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *digest) sendChildDigest(ctx context.Context, guardian auth.User, childID primitive.ObjectID, since time.Time) error {
+	child, err := d.repository.GetStudentByID(ctx, guardian.StudyPlaceInfo.ID, childID)
+	if err != nil {
+		return err
+	}
+
+	lessons, err := d.repository.GetStudentLessonsSince(ctx, child.ID, child.Group, since)
+	if err != nil {
+		return err
+	}
+
+	lines := buildDigest(child.ID, since, lessons)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	child.Name = d.encrypt.DecryptString(child.Name)
+
+	if d.mailer != nil && guardian.VerifiedEmail {
+		for i := range lines {
+			lines[i] = html.EscapeString(lines[i])
+		}
+
+		data := mail.Data{"name": html.EscapeString(guardian.Login), "child": html.EscapeString(child.Name), "digest": strings.Join(lines, "<br>")}
+		if err = d.mailer.SendFile(guardian.Email, "Studyum digest", "guardian-digest.html", data); err != nil {
+			return err
+		}
+	}
+
+	if d.firebase != nil && guardian.FirebaseToken != "" {
+		body := fmt.Sprintf("%d new marks and absences", len(lines))
+		if _, err = d.firebase.SendNotification(ctx, guardian.FirebaseToken, "", child.Name, body, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func buildDigest(studentID primitive.ObjectID, since time.Time, lessons []entities.Lesson) []string {
+	lines := make([]string, 0)
+	for _, lesson := range lessons {
+		date := lesson.StartDate.Format("02.01")
+
+		for _, mark := range lesson.Marks {
+			if mark.StudentID == studentID && !mark.ID.Timestamp().Before(since.Truncate(time.Second)) {
+				lines = append(lines, fmt.Sprintf("%s %s: %s", date, lesson.Subject, mark.Mark))
+			}
+		}
+
+		for _, absence := range lesson.Absences {
+			if absence.StudentID != studentID || absence.ID.Timestamp().Before(since.Truncate(time.Second)) {
+				continue
+			}
+
+			if absence.Time != nil {
+				lines = append(lines, fmt.Sprintf("%s %s: late %d min", date, lesson.Subject, *absence.Time))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s %s: absent", date, lesson.Subject))
+			}
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"github.com/robfig/cron"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestBuildDigest(t *testing.T) {
+	student := primitive.NewObjectID()
+	since := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	late := 5
+
+	lessons := []entities.Lesson{
+		{Subject: "Math", StartDate: since.Add(time.Hour), Marks: []entities.Mark{
+			{ID: primitive.NewObjectIDFromTimestamp(since.Add(time.Hour)), Mark: "5", StudentID: student},
+			{ID: primitive.NewObjectIDFromTimestamp(since.Add(-time.Hour)), Mark: "4", StudentID: student},
+			{ID: primitive.NewObjectIDFromTimestamp(since.Add(time.Hour)), Mark: "3", StudentID: primitive.NewObjectID()},
+		}},
+		{Subject: "Art", StartDate: since.AddDate(0, 0, 1), Absences: []entities.Absence{
+			{ID: primitive.NewObjectIDFromTimestamp(since.AddDate(0, 0, 1)), StudentID: student},
+			{ID: primitive.NewObjectIDFromTimestamp(since.AddDate(0, 0, 1)), StudentID: student, Time: &late},
+		}},
+	}
+
+	lines := buildDigest(student, since, lessons)
+
+	assert.Equal(t, lines, []string{"10.01 Math: 5", "11.01 Art: absent", "11.01 Art: late 5 min"})
+}
+
+func TestDigestSchedule(t *testing.T) {
+	ctx := context.Background()
+	schedule, err := cron.ParseStandard("0 8 * * 1")
+	assert.Equal(t, err, nil)
+
+	digests, journal := &testDigestRepository{}, &testRepository{}
+	d := &digest{repository: journal, digests: digests}
+
+	// the first run only saves the time to start from
+	sunday := time.Date(2023, 1, 8, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, d.Process(ctx, schedule, sunday), nil)
+	assert.Equal(t, journal.guardianRequests, 0)
+	assert.Equal(t, digests.state.SentAt, sunday)
+
+	// restarts don't send digests before the schedule
+	assert.Equal(t, d.Process(ctx, schedule, sunday.Add(time.Hour*19)), nil)
+	assert.Equal(t, journal.guardianRequests, 0)
+	assert.Equal(t, digests.state.SentAt, sunday)
+
+	monday := time.Date(2023, 1, 9, 8, 0, 0, 0, time.Local)
+	assert.Equal(t, d.Process(ctx, schedule, monday), nil)
+	assert.Equal(t, journal.guardianRequests, 1)
+	assert.Equal(t, digests.state.SentAt, monday)
+
+	assert.Equal(t, d.Process(ctx, schedule, monday.Add(time.Minute)), nil)
+	assert.Equal(t, journal.guardianRequests, 1)
+
+	// another instance holding the lock sends the digest
+	next := monday.AddDate(0, 0, 7)
+	digests.state.LockedUntil = next.Add(digestLease)
+	assert.Equal(t, d.Process(ctx, schedule, next), nil)
+	assert.Equal(t, journal.guardianRequests, 1)
+	assert.Equal(t, digests.state.SentAt, monday)
+
+	// the lock of a stopped instance expires
+	assert.Equal(t, d.Process(ctx, schedule, next.Add(digestLease)), nil)
+	assert.Equal(t, journal.guardianRequests, 2)
+	assert.Equal(t, digests.state.SentAt, next.Add(digestLease))
+}
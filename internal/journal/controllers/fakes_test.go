@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	apps "studyum/internal/apps/controllers"
+	auth "studyum/internal/auth/entities"
+	general "studyum/internal/general/entities"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+	"studyum/internal/journal/repositories"
+	"time"
+)
+
+// Fakes shared by the controller tests keep data in memory and implement only the methods the tests call
+
+// testRepository keeps lessons in memory and checks mark versions the way the mongo filters do
+type testRepository struct {
+	repositories.Repository
+
+	lessons    []entities.Lesson
+	students   []entities.Student
+	studyPlace general.StudyPlace
+
+	guardianRequests int
+}
+
+func (r *testRepository) lesson(id primitive.ObjectID) *entities.Lesson {
+	for i := range r.lessons {
+		if r.lessons[i].Id == id {
+			return &r.lessons[i]
+		}
+	}
+	return nil
+}
+
+func (r *testRepository) GetLessonByID(_ context.Context, id primitive.ObjectID) (entities.Lesson, error) {
+	lesson := r.lesson(id)
+	if lesson == nil {
+		return entities.Lesson{}, mongo.ErrNoDocuments
+	}
+	return *lesson, nil
+}
+
+func (r *testRepository) GetStudyPlaceByID(context.Context, primitive.ObjectID) (general.StudyPlace, error) {
+	return r.studyPlace, nil
+}
+
+func (r *testRepository) GetStudents(context.Context, primitive.ObjectID, string) ([]entities.Student, error) {
+	return r.students, nil
+}
+
+func (r *testRepository) GetGuardians(context.Context) ([]auth.User, error) {
+	r.guardianRequests++
+	return nil, nil
+}
+
+func (r *testRepository) GetMarkByID(_ context.Context, id primitive.ObjectID) (entities.Mark, error) {
+	for _, lesson := range r.lessons {
+		for _, mark := range lesson.Marks {
+			if mark.ID == id {
+				return mark, nil
+			}
+		}
+	}
+	return entities.Mark{}, mongo.ErrNoDocuments
+}
+
+func (r *testRepository) UpdateMark(_ context.Context, mark entities.Mark, teacher string) error {
+	lesson := r.lesson(mark.LessonID)
+	if lesson == nil || lesson.Teacher != teacher {
+		return mongo.ErrNoDocuments
+	}
+
+	for i := range lesson.Marks {
+		if lesson.Marks[i].ID == mark.ID && lesson.Marks[i].Version == mark.Version {
+			mark.Version++
+			lesson.Marks[i] = mark
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *testRepository) DeleteMarkByID(_ context.Context, id primitive.ObjectID, version int, teacher string) error {
+	for l := range r.lessons {
+		lesson := &r.lessons[l]
+		for i := range lesson.Marks {
+			if lesson.Marks[i].ID == id && lesson.Marks[i].Version == version && lesson.Teacher == teacher {
+				lesson.Marks = append(lesson.Marks[:i], lesson.Marks[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+type testCheckInRepository struct {
+	repositories.CheckInRepository
+
+	sessions []entities.CheckInSession
+}
+
+func (r *testCheckInRepository) GetExpiredCheckInSessions(_ context.Context, now time.Time) ([]entities.CheckInSession, error) {
+	var sessions []entities.CheckInSession
+	for _, session := range r.sessions {
+		if !session.Closed && !session.EndDate.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *testCheckInRepository) CloseCheckInSession(ctx context.Context, id primitive.ObjectID) (entities.CheckInSession, error) {
+	for i := range r.sessions {
+		if r.sessions[i].ID == id && !r.sessions[i].Closed {
+			r.sessions[i].Closed = true
+			testUndo(ctx, func() { r.sessions[i].Closed = false })
+			return r.sessions[i], nil
+		}
+	}
+	return entities.CheckInSession{}, mongo.ErrNoDocuments
+}
+
+type testDigestRepository struct {
+	state entities.DigestState
+}
+
+func (r *testDigestRepository) LockDigest(_ context.Context, now time.Time, lockedUntil time.Time) (entities.DigestState, error) {
+	if r.state.LockedUntil.After(now) {
+		return entities.DigestState{}, repositories.ErrDigestLocked
+	}
+
+	r.state.LockedUntil = lockedUntil
+	return r.state, nil
+}
+
+func (r *testDigestRepository) UnlockDigest(_ context.Context, sentAt time.Time) error {
+	r.state.SentAt = sentAt
+	r.state.LockedUntil = time.Time{}
+	return nil
+}
+
+// testJournal returns the cell from the marks kept by the repository
+type testJournal struct {
+	Journal
+
+	repository *testRepository
+}
+
+func (j *testJournal) GetUpdateInfo(_ context.Context, _, lessonID primitive.ObjectID) (entities.CellResponse, error) {
+	lesson := j.repository.lesson(lessonID)
+	if lesson == nil {
+		return entities.CellResponse{}, mongo.ErrNoDocuments
+	}
+	return entities.CellResponse{Cell: entities.Cell{Marks: append([]entities.Mark{}, lesson.Marks...)}}, nil
+}
+
+func (j *testJournal) InvalidateGroup(context.Context, primitive.ObjectID, string) {}
+
+// testController records the absences added by check-in
+type testController struct {
+	Controller
+
+	err      error
+	user     auth.User
+	absences []dtos.AddAbsencesDTO
+}
+
+func (c *testController) AddAbsences(_ context.Context, dto []dtos.AddAbsencesDTO, user auth.User) ([]entities.Absence, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.user = user
+	c.absences = append(c.absences, dto...)
+	return make([]entities.Absence, len(dto)), nil
+}
+
+type testTransactionKey struct{}
+
+// testTransaction keeps the functions that undo the changes of fake repositories
+type testTransaction struct {
+	undo []func()
+}
+
+// testUndo registers fn to run if the transaction of ctx fails
+func testUndo(ctx context.Context, fn func()) {
+	if tx, ok := ctx.Value(testTransactionKey{}).(*testTransaction); ok {
+		tx.undo = append(tx.undo, fn)
+	}
+}
+
+// testApps runs transactions in memory and records events
+type testApps struct {
+	apps.Controller
+
+	events []any
+}
+
+func (a *testApps) Transaction(ctx context.Context, write func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(testTransactionKey{}).(*testTransaction); ok {
+		return write(ctx)
+	}
+
+	tx := &testTransaction{}
+	if err := write(context.WithValue(ctx, testTransactionKey{}, tx)); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *testApps) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	fn(ctx)
+}
+
+func (a *testApps) Event(_ context.Context, _ primitive.ObjectID, _ string, data ...any) error {
+	a.events = append(a.events, data[0])
+	return nil
+}
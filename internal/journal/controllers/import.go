@@ -0,0 +1,380 @@
+package controllers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"strconv"
+	"strings"
+	auth "studyum/internal/auth/entities"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+	"studyum/pkg/datetime"
+	"time"
+)
+
+// minSheetDate is a lower bound for excel date serials, so numeric marks are not treated as dates
+const minSheetDate = 1000
+
+var sheetDateLayouts = []string{"02.01.2006", "2.1.2006", "2006-01-02", "02/01/2006"}
+var sheetShortDateLayouts = []string{"02.01", "2.1", "02/01"}
+
+type sheetDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func (d sheetDate) matches(date time.Time) bool {
+	return (d.year == 0 || d.year == date.Year()) && d.month == date.Month() && d.day == date.Day()
+}
+
+func parseSheetDate(value string) (sheetDate, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return sheetDate{}, false
+	}
+
+	for _, layout := range sheetDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return sheetDate{year: date.Year(), month: date.Month(), day: date.Day()}, true
+		}
+	}
+
+	for _, layout := range sheetShortDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return sheetDate{month: date.Month(), day: date.Day()}, true
+		}
+	}
+
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < minSheetDate {
+		return sheetDate{}, false
+	}
+
+	date, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return sheetDate{}, false
+	}
+
+	return sheetDate{year: date.Year(), month: date.Month(), day: date.Day()}, true
+}
+
+func sheetCell(rows [][]string, row, column int) string {
+	if row >= len(rows) || column >= len(rows[row]) {
+		return ""
+	}
+
+	return strings.TrimSpace(rows[row][column])
+}
+
+func sheetCellName(row, column int) string {
+	name, _ := excelize.CoordinatesToCellName(column+1, row+1)
+	return name
+}
+
+// sheetHeader returns the index of the first row containing lesson dates and the index of its first date column
+func sheetHeader(rows [][]string) (int, int, bool) {
+	for y, row := range rows {
+		for x := 1; x < len(row); x++ {
+			if _, ok := parseSheetDate(row[x]); ok {
+				return y, x, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// sheetLessons maps every date column of the header to a lesson, columns with the same date
+// are matched with lessons of this day in the order they start
+func sheetLessons(rows [][]string, header, firstColumn int, lessons []entities.Lesson, preview *entities.ImportPreview) map[int]entities.Lesson {
+	columns := map[int]entities.Lesson{}
+	used := map[time.Time]int{}
+
+	for x := firstColumn; x < len(rows[header]); x++ {
+		date, ok := parseSheetDate(rows[header][x])
+		if !ok {
+			continue
+		}
+
+		var candidates []entities.Lesson
+		for _, lesson := range lessons {
+			if date.matches(lesson.StartDate) {
+				candidates = append(candidates, lesson)
+			}
+		}
+
+		if len(candidates) == 0 {
+			preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(header, x), Error: "no lesson on this date"})
+			continue
+		}
+
+		day := datetime.ToDateWithoutTime(candidates[0].StartDate)
+		for _, candidate := range candidates[1:] {
+			if !datetime.ToDateWithoutTime(candidate.StartDate).Equal(day) {
+				preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(header, x), Error: "ambiguous date, specify the year"})
+				day = time.Time{}
+				break
+			}
+		}
+
+		if day.IsZero() {
+			continue
+		}
+
+		if used[day] >= len(candidates) {
+			preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(header, x), Error: "no lesson on this date"})
+			continue
+		}
+
+		columns[x] = candidates[used[day]]
+		used[day]++
+	}
+
+	return columns
+}
+
+func sheetStudents(students []entities.Student) map[string][]entities.Student {
+	names := map[string][]entities.Student{}
+	for _, student := range students {
+		name := strings.ToLower(strings.Join(strings.Fields(student.Name), " "))
+		names[name] = append(names[name], student)
+	}
+
+	return names
+}
+
+// buildImportPreview compares a sheet laid out like the journal (students as rows, lesson dates as columns)
+// with the lessons and returns marks and absences that are missing in the journal,
+// students are expected to have decrypted names
+func buildImportPreview(rows [][]string, students []entities.Student, lessons []entities.Lesson, absenceMark string, validMark func(lesson entities.Lesson, mark string) bool) entities.ImportPreview {
+	preview := entities.ImportPreview{
+		Marks:    []entities.ImportMark{},
+		Absences: []entities.ImportAbsence{},
+		Errors:   []entities.ImportError{},
+	}
+
+	header, firstColumn, ok := sheetHeader(rows)
+	if !ok {
+		preview.Errors = append(preview.Errors, entities.ImportError{Error: "no row with lesson dates"})
+		return preview
+	}
+
+	nameColumn := firstColumn - 1
+	columns := sheetLessons(rows, header, firstColumn, lessons, &preview)
+	names := sheetStudents(students)
+
+	for y := header + 1; y < len(rows); y++ {
+		name := sheetCell(rows, y, nameColumn)
+		if name == "" {
+			continue
+		}
+
+		matched := names[strings.ToLower(strings.Join(strings.Fields(name), " "))]
+		if len(matched) != 1 {
+			message := "unknown student"
+			if len(matched) > 1 {
+				message = "ambiguous student"
+			}
+
+			preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(y, nameColumn), Error: message})
+			continue
+		}
+
+		student := matched[0]
+		for x := firstColumn; x < len(rows[y]); x++ {
+			lesson, ok := columns[x]
+			if !ok {
+				continue
+			}
+
+			existing := map[string]int{}
+			for _, mark := range studentMarks(lesson.Marks, student.ID) {
+				existing[mark.Mark]++
+			}
+			absent := len(studentAbsences(lesson.Absences, student.ID)) != 0
+
+			tokens := strings.FieldsFunc(sheetCell(rows, y, x), func(r rune) bool {
+				return r == ' ' || r == ',' || r == ';'
+			})
+			for _, token := range tokens {
+				if absenceMark != "" && strings.HasPrefix(token, absenceMark) {
+					var lateness *int
+					if rest := strings.TrimPrefix(token, absenceMark); rest != "" {
+						minutes, err := strconv.Atoi(rest)
+						if err != nil || minutes <= 0 {
+							preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(y, x), Error: "not valid absence " + token})
+							continue
+						}
+
+						lateness = &minutes
+					}
+
+					if absent {
+						continue
+					}
+
+					absent = true
+					preview.Absences = append(preview.Absences, entities.ImportAbsence{
+						StudentID: student.ID,
+						Student:   name,
+						LessonID:  lesson.Id,
+						Date:      lesson.StartDate,
+						Time:      lateness,
+					})
+					continue
+				}
+
+				if !validMark(lesson, token) {
+					preview.Errors = append(preview.Errors, entities.ImportError{Cell: sheetCellName(y, x), Error: "not valid mark " + token})
+					continue
+				}
+
+				if existing[token] > 0 {
+					existing[token]--
+					continue
+				}
+
+				preview.Marks = append(preview.Marks, entities.ImportMark{
+					StudentID: student.ID,
+					Student:   name,
+					LessonID:  lesson.Id,
+					Date:      lesson.StartDate,
+					Mark:      token,
+				})
+			}
+		}
+	}
+
+	return preview
+}
+
+func (j *controller) buildImportPreview(ctx context.Context, user auth.User, group, subject, teacher string, file io.Reader) (entities.ImportPreview, error) {
+	if group == "" || subject == "" || teacher == "" {
+		return entities.ImportPreview{}, NotValidParams
+	}
+
+	options, err := j.journal.BuildAvailableOptions(ctx, user)
+	if err != nil {
+		return entities.ImportPreview{}, err
+	}
+
+	editable := false
+	for _, option := range options {
+		if option.Group == group && option.Subject == subject && option.Teacher == teacher && option.Editable {
+			editable = true
+			break
+		}
+	}
+
+	if !editable {
+		return entities.ImportPreview{}, ErrNoPermission
+	}
+
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return entities.ImportPreview{}, errors.Wrap(NotValidParams, "file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	rows, err := f.GetRows(f.GetSheetList()[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return entities.ImportPreview{}, errors.Wrap(NotValidParams, "file")
+	}
+
+	studyPlace, err := j.repository.GetStudyPlaceByID(ctx, user.StudyPlaceInfo.ID)
+	if err != nil {
+		return entities.ImportPreview{}, err
+	}
+
+	students, err := j.repository.GetStudents(ctx, user.StudyPlaceInfo.ID, group)
+	if err != nil {
+		return entities.ImportPreview{}, err
+	}
+
+	for i := range students {
+		students[i].Name = j.encrypt.DecryptString(students[i].Name)
+	}
+
+	lessons, err := j.repository.GetSubjectLessons(ctx, user.StudyPlaceInfo.ID, group, subject, teacher)
+	if err != nil {
+		return entities.ImportPreview{}, err
+	}
+
+	validMarks := map[primitive.ObjectID]map[string]bool{}
+	validMark := func(lesson entities.Lesson, mark string) bool {
+		if validMarks[lesson.Id] == nil {
+			validMarks[lesson.Id] = map[string]bool{}
+		}
+
+		valid, ok := validMarks[lesson.Id][mark]
+		if !ok {
+			valid = j.checkMarkExistence(ctx, dtos.AddMarkDTO{Mark: mark, LessonID: lesson.Id}, user.StudyPlaceInfo.ID)
+			validMarks[lesson.Id][mark] = valid
+		}
+
+		return valid
+	}
+
+	return buildImportPreview(rows, students, lessons, studyPlace.AbsenceMark, validMark), nil
+}
+
+func (j *controller) PreviewJournalImport(ctx context.Context, user auth.User, group, subject, teacher string, file io.Reader) (entities.ImportPreview, error) {
+	return j.buildImportPreview(ctx, user, group, subject, teacher, file)
+}
+
+func (j *controller) ImportJournal(ctx context.Context, user auth.User, group, subject, teacher string, file io.Reader) (entities.ImportResult, error) {
+	preview, err := j.buildImportPreview(ctx, user, group, subject, teacher, file)
+	if err != nil {
+		return entities.ImportResult{}, err
+	}
+
+	if len(preview.Errors) != 0 {
+		return entities.ImportResult{}, errors.Wrap(NotValidParams, preview.Errors[0].Cell+" "+preview.Errors[0].Error)
+	}
+
+	// the whole file is imported in one transaction, a failed import leaves the journal unchanged
+	// and cells which already have the imported marks are skipped by the preview when it is retried
+	var result entities.ImportResult
+	err = j.apps.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		result = entities.ImportResult{
+			Marks:    []entities.Mark{},
+			Absences: []entities.Absence{},
+		}
+
+		if len(preview.Marks) != 0 {
+			marks := make([]dtos.AddMarkDTO, len(preview.Marks))
+			for i, mark := range preview.Marks {
+				marks[i] = dtos.AddMarkDTO{Mark: mark.Mark, StudentID: mark.StudentID, LessonID: mark.LessonID}
+			}
+
+			if result.Marks, err = j.AddMarks(ctx, marks, user); err != nil {
+				return err
+			}
+		}
+
+		if len(preview.Absences) != 0 {
+			absences := make([]dtos.AddAbsencesDTO, len(preview.Absences))
+			for i, absence := range preview.Absences {
+				absences[i] = dtos.AddAbsencesDTO{Time: absence.Time, StudentID: absence.StudentID, LessonID: absence.LessonID}
+			}
+
+			if result.Absences, err = j.AddAbsences(ctx, absences, user); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entities.ImportResult{}, err
+	}
+
+	return result, nil
+}
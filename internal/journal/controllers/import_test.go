@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestBuildImportPreview(t *testing.T) {
+	student := entities.Student{ID: primitive.NewObjectID(), Name: "John Smith"}
+	first := entities.Lesson{Id: primitive.NewObjectID(), StartDate: time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)}
+	second := entities.Lesson{Id: primitive.NewObjectID(), StartDate: time.Date(2023, 1, 10, 10, 0, 0, 0, time.UTC)}
+	third := entities.Lesson{
+		Id:        primitive.NewObjectID(),
+		StartDate: time.Date(2023, 1, 11, 8, 0, 0, 0, time.UTC),
+		Marks:     []entities.Mark{{Mark: "5", StudentID: student.ID}},
+	}
+
+	rows := [][]string{
+		{"Group"},
+		{"", "10.01", "10.01", "11.01.2023", "12.01"},
+		{"john  smith", "5 4", "n5", "5", ""},
+		{"Unknown", "5"},
+		{"John Smith", "", "", "", "x"},
+	}
+
+	validMark := func(lesson entities.Lesson, mark string) bool {
+		return mark == "5" || mark == "4"
+	}
+
+	preview := buildImportPreview(rows, []entities.Student{student}, []entities.Lesson{first, second, third}, "n", validMark)
+
+	assert.Equal(t, len(preview.Marks), 2)
+	assert.Equal(t, preview.Marks[0].LessonID, first.Id)
+	assert.Equal(t, preview.Marks[0].Mark, "5")
+	assert.Equal(t, preview.Marks[1].Mark, "4")
+
+	assert.Equal(t, len(preview.Absences), 1)
+	assert.Equal(t, preview.Absences[0].LessonID, second.Id)
+	assert.Equal(t, *preview.Absences[0].Time, 5)
+
+	assert.Equal(t, preview.Errors, []entities.ImportError{
+		{Cell: "E2", Error: "no lesson on this date"},
+		{Cell: "A4", Error: "unknown student"},
+	})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/exp/slices"
 	"strconv"
@@ -20,15 +21,22 @@ type Journal interface {
 	BuildAvailableOptions(ctx context.Context, user auth.User) ([]entities.AvailableOption, error)
 	BuildSubjectsJournal(ctx context.Context, group string, subject string, teacher string, user auth.User) (entities.Journal, error)
 	BuildStudentsJournal(ctx context.Context, user auth.User) (entities.Journal, error)
+	BuildChildJournal(ctx context.Context, user auth.User, childID string) (entities.Journal, error)
+
+	GetChild(ctx context.Context, user auth.User, childID string) (entities.Student, error)
+
+	InvalidateGroup(ctx context.Context, studyPlaceID primitive.ObjectID, group string)
+	InvalidateStudyPlace(ctx context.Context, studyPlaceID primitive.ObjectID)
 }
 
 type journal struct {
 	repository repositories.Repository
+	cache      repositories.Cache
 	encrypt    encryption.Encryption
 }
 
-func NewJournalController(repository repositories.Repository, encrypt encryption.Encryption) Journal {
-	return &journal{repository: repository, encrypt: encrypt}
+func NewJournalController(repository repositories.Repository, cache repositories.Cache, encrypt encryption.Encryption) Journal {
+	return &journal{repository: repository, cache: cache, encrypt: encrypt}
 }
 
 func (c *journal) rowAverageMark(row entities.Row) float32 {
@@ -209,8 +217,9 @@ func (c *journal) proceedJournal(j *entities.Journal) {
 
 func (c *journal) getCellByLessonAndUserID(ctx context.Context, userID primitive.ObjectID, lesson entities.Lesson) (entities.Cell, error) {
 	cell := entities.Cell{
-		Id:   lesson.Id,
-		Type: []string{lesson.Type},
+		Id:      lesson.Id,
+		Version: lesson.Version,
+		Type:    []string{lesson.Type},
 	}
 
 	for _, mark := range lesson.Marks {
@@ -240,11 +249,13 @@ func (c *journal) getRowInfo(ctx context.Context, userID primitive.ObjectID, les
 		return 0, nil, "", err
 	}
 
-	cells, dates, err := c.repository.GetJournalRowWithDates(ctx, userID, lesson.Subject, lesson.Teacher, lesson.Group, lesson.StudyPlaceId)
+	lessons, err := c.repository.GetSubjectLessons(ctx, lesson.StudyPlaceId, lesson.Group, lesson.Subject, lesson.Teacher)
 	if err != nil {
 		return 0, nil, "", err
 	}
 
+	cells, dates := buildRowCells(lessons, userID)
+
 	row := entities.Row{
 		Cells: make([]*entities.Cell, len(cells)),
 	}
@@ -312,7 +323,7 @@ func (c *journal) BuildAvailableOptions(ctx context.Context, user auth.User) ([]
 		}
 	}
 
-	teacherOptions, err := c.repository.GetAvailableOptions(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.RoleName, slices.Contains(user.StudyPlaceInfo.Permissions, "editJournal"))
+	teacherOptions, err := c.repository.GetAvailableOptions(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.RoleName, user.HasPermission(auth.PermissionEditJournal))
 	if err != nil {
 		return nil, err
 	}
@@ -323,7 +334,7 @@ func (c *journal) BuildAvailableOptions(ctx context.Context, user auth.User) ([]
 		appendOptions(tuitionOptions)
 	}
 
-	if utils.HasPermission(user, "viewJournals") {
+	if utils.HasPermission(user, auth.PermissionViewJournals) {
 		adminOptions, err := c.repository.GetAllAvailableOptions(ctx, user.StudyPlaceInfo.ID, false)
 		if err == nil {
 			appendOptions(adminOptions)
@@ -360,11 +371,12 @@ func (c *journal) BuildSubjectsJournal(ctx context.Context, group string, subjec
 		return entities.Journal{}, ErrNoPermission
 	}
 
-	journal, err := c.repository.GetJournal(ctx, *option, user.StudyPlaceInfo.ID)
+	journal, err := c.getSubjectsJournal(ctx, *option, user.StudyPlaceInfo.ID)
 	if err != nil {
 		return entities.Journal{}, err
 	}
 
+	journal.Info.Editable = option.Editable
 	for i := range journal.Rows {
 		journal.Rows[i].Title = c.encrypt.DecryptString(journal.Rows[i].Title)
 	}
@@ -373,20 +385,114 @@ func (c *journal) BuildSubjectsJournal(ctx context.Context, group string, subjec
 		return el1.Title < el2.Title
 	})
 
+	c.proceedJournal(&journal)
+	return journal, nil
+}
+
+func (c *journal) BuildStudentsJournal(ctx context.Context, user auth.User) (entities.Journal, error) {
+	journal, err := c.getStudentsJournal(ctx, user.Id, user.StudyPlaceInfo.RoleName, user.StudyPlaceInfo.ID)
 	if err != nil {
 		return entities.Journal{}, err
 	}
 
+	hideTeacherComments(&journal)
+
 	c.proceedJournal(&journal)
 	return journal, nil
 }
 
-func (c *journal) BuildStudentsJournal(ctx context.Context, user auth.User) (entities.Journal, error) {
-	journal, err := c.repository.GetStudentJournal(ctx, user.Id, user.StudyPlaceInfo.RoleName, user.StudyPlaceInfo.ID)
+// GetChild returns a student linked to the guardian
+func (c *journal) GetChild(ctx context.Context, user auth.User, childID string) (entities.Student, error) {
+	id, err := primitive.ObjectIDFromHex(childID)
+	if err != nil {
+		return entities.Student{}, errors.Wrap(NotValidParams, "childID")
+	}
+
+	if user.StudyPlaceInfo.Role != auth.GuardianRole || !slices.Contains(user.Children, id) {
+		return entities.Student{}, ErrNoPermission
+	}
+
+	child, err := c.repository.GetStudentByID(ctx, user.StudyPlaceInfo.ID, id)
+	if err != nil {
+		return entities.Student{}, err
+	}
+
+	child.Name = c.encrypt.DecryptString(child.Name)
+	return child, nil
+}
+
+func (c *journal) BuildChildJournal(ctx context.Context, user auth.User, childID string) (entities.Journal, error) {
+	child, err := c.GetChild(ctx, user, childID)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	journal, err := c.getStudentsJournal(ctx, child.ID, child.Group, user.StudyPlaceInfo.ID)
 	if err != nil {
 		return entities.Journal{}, err
 	}
 
+	hideTeacherComments(&journal)
+
 	c.proceedJournal(&journal)
 	return journal, nil
 }
+
+func (c *journal) getSubjectsJournal(ctx context.Context, option entities.AvailableOption, studyPlaceID primitive.ObjectID) (entities.Journal, error) {
+	key := repositories.SubjectJournalKey(option.Subject, option.Teacher)
+	journal, cacheKey, ok := c.cache.GetJournal(ctx, studyPlaceID, option.Group, key)
+	if ok {
+		return journal, nil
+	}
+
+	studyPlace, err := c.repository.GetStudyPlaceByID(ctx, studyPlaceID)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	students, err := c.repository.GetStudents(ctx, studyPlaceID, option.Group)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	lessons, err := c.repository.GetSubjectLessons(ctx, studyPlaceID, option.Group, option.Subject, option.Teacher)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	journal = buildSubjectsJournal(option, studyPlace, students, lessons)
+	c.cache.SetJournal(ctx, cacheKey, journal)
+
+	return journal, nil
+}
+
+func (c *journal) getStudentsJournal(ctx context.Context, studentID primitive.ObjectID, group string, studyPlaceID primitive.ObjectID) (entities.Journal, error) {
+	key := repositories.StudentJournalKey(studentID)
+	journal, cacheKey, ok := c.cache.GetJournal(ctx, studyPlaceID, group, key)
+	if ok {
+		return journal, nil
+	}
+
+	studyPlace, err := c.repository.GetStudyPlaceByID(ctx, studyPlaceID)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	lessons, err := c.repository.GetGroupLessons(ctx, studyPlaceID, group)
+	if err != nil {
+		return entities.Journal{}, err
+	}
+
+	journal = buildStudentsJournal(studentID, group, studyPlace, lessons)
+	c.cache.SetJournal(ctx, cacheKey, journal)
+
+	return journal, nil
+}
+
+func (c *journal) InvalidateGroup(ctx context.Context, studyPlaceID primitive.ObjectID, group string) {
+	c.cache.InvalidateGroup(ctx, studyPlaceID, group)
+}
+
+func (c *journal) InvalidateStudyPlace(ctx context.Context, studyPlaceID primitive.ObjectID) {
+	c.cache.InvalidateStudyPlace(ctx, studyPlaceID)
+}
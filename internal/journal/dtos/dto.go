@@ -2,28 +2,35 @@ package dtos
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
 	"time"
 )
 
 type AddMarkDTO struct {
-	Mark      string             `json:"mark"`
-	StudentID primitive.ObjectID `json:"studentID"`
-	LessonID  primitive.ObjectID `json:"lessonId"`
+	Mark              string                     `json:"mark"`
+	Comment           string                     `json:"comment"`
+	CommentVisibility entities.CommentVisibility `json:"commentVisibility"`
+	StudentID         primitive.ObjectID         `json:"studentID"`
+	LessonID          primitive.ObjectID         `json:"lessonId"`
 }
 
 type UpdateMarkDTO struct {
-	ID primitive.ObjectID `json:"id"`
+	ID      primitive.ObjectID `json:"id"`
+	Version int                `json:"version"`
 	AddMarkDTO
 }
 
 type AddAbsencesDTO struct {
-	Time      *int               `json:"time"`
-	StudentID primitive.ObjectID `json:"studentID"`
-	LessonID  primitive.ObjectID `json:"lessonID"`
+	Time              *int                       `json:"time"`
+	Comment           string                     `json:"comment"`
+	CommentVisibility entities.CommentVisibility `json:"commentVisibility"`
+	StudentID         primitive.ObjectID         `json:"studentID"`
+	LessonID          primitive.ObjectID         `json:"lessonID"`
 }
 
 type UpdateAbsencesDTO struct {
-	ID primitive.ObjectID `json:"id"`
+	ID      primitive.ObjectID `json:"id"`
+	Version int                `json:"version"`
 	AddAbsencesDTO
 }
 
@@ -39,3 +46,12 @@ type AbsencesReport struct {
 	StartDate *time.Time `json:"startDate" bson:"startDate"`
 	EndDate   *time.Time `json:"endDate" bson:"endDate"`
 }
+
+type StartCheckInDTO struct {
+	Period int `json:"period"`
+}
+
+type CheckInDTO struct {
+	LessonID primitive.ObjectID `json:"lessonID"`
+	Code     string             `json:"code"`
+}
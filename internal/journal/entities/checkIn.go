@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type CheckInSession struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	LessonID     primitive.ObjectID `json:"lessonID" bson:"lessonID"`
+	Group        string             `json:"group" bson:"group"`
+	Teacher      string             `json:"teacher" bson:"teacher"`
+	Secret       []byte             `json:"-" bson:"secret"`
+	Period       time.Duration      `json:"period" bson:"period"`
+	StartDate    time.Time          `json:"startDate" bson:"startDate"`
+	EndDate      time.Time          `json:"endDate" bson:"endDate"`
+	Closed       bool               `json:"closed" bson:"closed"`
+	CheckIns     []CheckIn          `json:"checkIns" bson:"checkIns"`
+}
+
+// MarshalJSON writes the period in seconds, the way it is passed to start the session
+func (s CheckInSession) MarshalJSON() ([]byte, error) {
+	type session CheckInSession
+	return json.Marshal(struct {
+		session
+		Period int64 `json:"period"`
+	}{session: session(s), Period: int64(s.Period / time.Second)})
+}
+
+type CheckIn struct {
+	StudentID primitive.ObjectID `json:"studentID" bson:"studentID"`
+	Date      time.Time          `json:"date" bson:"date"`
+}
+
+type CheckInCode struct {
+	LessonID  primitive.ObjectID `json:"lessonID"`
+	Code      string             `json:"code"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
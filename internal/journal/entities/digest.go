@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// DigestState is shared by instances sending guardian digests, SentAt is when the last digest was sent
+// and LockedUntil is set while an instance is sending one
+type DigestState struct {
+	ID          string    `bson:"_id"`
+	SentAt      time.Time `bson:"sentAt"`
+	LockedUntil time.Time `bson:"lockedUntil,omitempty"`
+}
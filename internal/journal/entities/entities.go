@@ -35,6 +35,7 @@ type Row struct {
 
 type Cell struct {
 	Id               primitive.ObjectID `json:"id" bson:"_id"`
+	Version          int                `json:"version" bson:"version"`
 	Type             []string           `json:"type" bson:"type"`
 	JournalCellColor string             `json:"journalCellColor" bson:"journalCellColor"`
 	Marks            []Mark             `json:"marks,omitempty" bson:"marks"`
@@ -48,16 +49,26 @@ type AvailableOption struct {
 	Editable bool   `json:"editable"`
 }
 
+type CommentVisibility string
+
+const (
+	TeacherVisibility CommentVisibility = "teacher"
+	StudentVisibility CommentVisibility = "student"
+)
+
 type DeleteMarkID struct {
 	ID primitive.ObjectID `apps:"trackable,collection=Lessons,type=array,nested=marks"`
 }
 
 type Mark struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons,type=array,nested=marks"`
-	Mark         string             `json:"mark" bson:"mark"`
-	StudentID    primitive.ObjectID `json:"studentID" bson:"studentID"`
-	LessonID     primitive.ObjectID `json:"lessonID" bson:"lessonID"`
-	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	ID                primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons,type=array,nested=marks"`
+	Mark              string             `json:"mark" bson:"mark"`
+	Comment           string             `json:"comment,omitempty" bson:"comment,omitempty"`
+	CommentVisibility CommentVisibility  `json:"commentVisibility,omitempty" bson:"commentVisibility,omitempty"`
+	Version           int                `json:"version" bson:"version"`
+	StudentID         primitive.ObjectID `json:"studentID" bson:"studentID"`
+	LessonID          primitive.ObjectID `json:"lessonID" bson:"lessonID"`
+	StudyPlaceID      primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
 }
 
 type DeleteAbsenceID struct {
@@ -65,11 +76,14 @@ type DeleteAbsenceID struct {
 }
 
 type Absence struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons,type=array,nested=absences"`
-	Time         *int               `json:"time" bson:"time"`
-	StudentID    primitive.ObjectID `json:"studentID" bson:"studentID"`
-	LessonID     primitive.ObjectID `json:"lessonID" bson:"lessonID"`
-	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	ID                primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons,type=array,nested=absences"`
+	Time              *int               `json:"time" bson:"time"`
+	Comment           string             `json:"comment,omitempty" bson:"comment,omitempty"`
+	CommentVisibility CommentVisibility  `json:"commentVisibility,omitempty" bson:"commentVisibility,omitempty"`
+	Version           int                `json:"version" bson:"version"`
+	StudentID         primitive.ObjectID `json:"studentID" bson:"studentID"`
+	LessonID          primitive.ObjectID `json:"lessonID" bson:"lessonID"`
+	StudyPlaceID      primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
 }
 
 type MarkAmount struct {
@@ -83,6 +97,7 @@ type DeleteLessonID struct {
 
 type Lesson struct {
 	Id               primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons"`
+	Version          int                `json:"version" bson:"version"`
 	StudyPlaceId     primitive.ObjectID `json:"studyPlaceId" bson:"studyPlaceId"`
 	PrimaryColor     string             `json:"primaryColor" bson:"primaryColor"`
 	JournalCellColor string             `json:"journalCellColor" bson:"journalCellColor"`
@@ -107,3 +122,9 @@ type GeneratedTable struct {
 	Titles []string   `json:"titles" bson:"titles"`
 	Rows   [][]string `json:"rows" bson:"rows"`
 }
+
+type Student struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id"`
+	Name  string             `json:"name" bson:"name"`
+	Group string             `json:"group,omitempty" bson:"roleName,omitempty"`
+}
@@ -1,8 +1,45 @@
 package entities
 
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
 type CellResponse struct {
 	Cell       Cell           `json:"cell"`
 	Average    float32        `json:"average"`
 	MarkAmount map[string]int `json:"markAmount"`
 	RowColor   string         `json:"rowColor"`
 }
+
+type ImportMark struct {
+	StudentID primitive.ObjectID `json:"studentID"`
+	Student   string             `json:"student"`
+	LessonID  primitive.ObjectID `json:"lessonID"`
+	Date      time.Time          `json:"date"`
+	Mark      string             `json:"mark"`
+}
+
+type ImportAbsence struct {
+	StudentID primitive.ObjectID `json:"studentID"`
+	Student   string             `json:"student"`
+	LessonID  primitive.ObjectID `json:"lessonID"`
+	Date      time.Time          `json:"date"`
+	Time      *int               `json:"time"`
+}
+
+type ImportError struct {
+	Cell  string `json:"cell"`
+	Error string `json:"error"`
+}
+
+type ImportPreview struct {
+	Marks    []ImportMark    `json:"marks"`
+	Absences []ImportAbsence `json:"absences"`
+	Errors   []ImportError   `json:"errors"`
+}
+
+type ImportResult struct {
+	Marks    []Mark    `json:"marks"`
+	Absences []Absence `json:"absences"`
+}
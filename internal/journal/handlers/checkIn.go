@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	auth "studyum/internal/auth/handlers"
+	"studyum/internal/journal/controllers"
+	"studyum/internal/journal/dtos"
+)
+
+type CheckIn struct {
+	auth.Middleware
+
+	controller controllers.CheckIn
+
+	Group *gin.RouterGroup
+}
+
+func NewCheckIn(middleware auth.Middleware, controller controllers.CheckIn, group *gin.RouterGroup) *CheckIn {
+	h := &CheckIn{Middleware: middleware, controller: controller, Group: group}
+
+	group.POST("", h.MemberAuth(), h.CheckIn)
+
+	session := group.Group("/:lessonID", h.MemberAuth("editJournal"))
+	{
+		session.POST("", h.StartCheckIn)
+		session.GET("/code", h.GetCheckInCode)
+		session.DELETE("", h.CloseCheckIn)
+	}
+
+	return h
+}
+
+// StartCheckIn godoc
+// @Param lessonID path string true "Lesson ID"
+// @Router /check-in/{lessonID} [post]
+func (h *CheckIn) StartCheckIn(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var dto dtos.StartCheckInDTO
+	if err := ctx.ShouldBindJSON(&dto); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, err := h.controller.StartCheckIn(ctx, user, ctx.Param("lessonID"), dto)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// GetCheckInCode godoc
+// @Param lessonID path string true "Lesson ID"
+// @Router /check-in/{lessonID}/code [get]
+func (h *CheckIn) GetCheckInCode(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	code, err := h.controller.GetCheckInCode(ctx, user, ctx.Param("lessonID"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, code)
+}
+
+// CloseCheckIn godoc
+// @Param lessonID path string true "Lesson ID"
+// @Router /check-in/{lessonID} [delete]
+func (h *CheckIn) CloseCheckIn(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	absences, err := h.controller.CloseCheckIn(ctx, user, ctx.Param("lessonID"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, absences)
+}
+
+// CheckIn godoc
+// @Router /check-in [post]
+func (h *CheckIn) CheckIn(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var dto dtos.CheckInDTO
+	if err := ctx.BindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.controller.CheckIn(ctx, user, dto); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"studyum/grpc/journal/protojournal"
+	auth "studyum/internal/auth/handlers"
+	"studyum/internal/journal/controllers"
+	"studyum/internal/journal/dtos"
+	"studyum/internal/journal/entities"
+)
+
+// Grpc serves journals to applications, calls are authenticated by auth.Middleware GrpcInterceptor
+type Grpc struct {
+	auth.Middleware
+
+	controller        controllers.Controller
+	journalController controllers.Journal
+}
+
+func NewGrpc(middleware auth.Middleware, controller controllers.Controller, journal controllers.Journal, grpcServer *grpc.Server) *Grpc {
+	h := &Grpc{Middleware: middleware, controller: controller, journalController: journal}
+
+	protojournal.RegisterJournalServer(grpcServer, h)
+
+	h.GrpcMemberAuth("/Journal/GetJournal")
+
+	h.GrpcMemberAuth("/Journal/AddMark", "editJournal")
+	h.GrpcMemberAuth("/Journal/UpdateMark", "editJournal")
+	h.GrpcMemberAuth("/Journal/DeleteMark", "editJournal")
+
+	h.GrpcMemberAuth("/Journal/AddAbsence", "editJournal")
+	h.GrpcMemberAuth("/Journal/UpdateAbsence", "editJournal")
+	h.GrpcMemberAuth("/Journal/DeleteAbsence", "editJournal")
+
+	return h
+}
+
+func (h *Grpc) GetJournal(ctx context.Context, request *protojournal.JournalRequest) (*protojournal.JournalResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	journal, err := h.journalController.BuildSubjectsJournal(ctx, request.Group, request.Subject, request.Teacher, user)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*protojournal.JournalRow, 0, len(journal.Rows))
+	for _, row := range journal.Rows {
+		cells := make([]*protojournal.JournalCell, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			if cell == nil {
+				cells = append(cells, &protojournal.JournalCell{})
+				continue
+			}
+
+			cells = append(cells, cellToProto(*cell))
+		}
+
+		rows = append(rows, &protojournal.JournalRow{
+			Id:                 row.ID,
+			Title:              row.Title,
+			Cells:              cells,
+			AverageMark:        row.AverageMark,
+			NumericMarksSum:    int32(row.NumericMarksSum),
+			NumericMarksAmount: int32(row.NumericMarksLength),
+			AbsencesAmount:     int32(row.AbsencesAmount),
+			AbsencesTime:       int32(row.AbsencesTime),
+			MarksAmount:        marksAmountToProto(row.MarksAmount),
+			Color:              row.Color,
+		})
+	}
+
+	dates := make([]*protojournal.JournalLesson, 0, len(journal.Dates))
+	for _, lesson := range journal.Dates {
+		dates = append(dates, &protojournal.JournalLesson{
+			Id:               lesson.Id.Hex(),
+			Version:          int32(lesson.Version),
+			PrimaryColor:     lesson.PrimaryColor,
+			SecondaryColor:   lesson.SecondaryColor,
+			JournalCellColor: lesson.JournalCellColor,
+			Type:             lesson.Type,
+			StartDate:        timestamppb.New(lesson.StartDate),
+			EndDate:          timestamppb.New(lesson.EndDate),
+			LessonIndex:      int32(lesson.LessonIndex),
+			Subject:          lesson.Subject,
+			Group:            lesson.Group,
+			Teacher:          lesson.Teacher,
+			Room:             lesson.Room,
+			Title:            lesson.Title,
+			Homework:         lesson.Homework,
+			Description:      lesson.Description,
+		})
+	}
+
+	return &protojournal.JournalResponse{
+		Info: &protojournal.JournalInfo{
+			Editable:     journal.Info.Editable,
+			StudyPlaceID: journal.Info.StudyPlace.Id.Hex(),
+			Group:        journal.Info.Group,
+			Teacher:      journal.Info.Teacher,
+			Subject:      journal.Info.Subject,
+		},
+		Rows:  rows,
+		Dates: dates,
+	}, nil
+}
+
+func (h *Grpc) AddMark(ctx context.Context, request *protojournal.AddMarkRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	mark, err := addMarkDTO(request.Mark, request.Comment, request.CommentVisibility, request.StudentID, request.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cellResponseToProto(h.controller.AddMark(ctx, mark, user))
+}
+
+func (h *Grpc) UpdateMark(ctx context.Context, request *protojournal.UpdateMarkRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	mark, err := addMarkDTO(request.Mark, request.Comment, request.CommentVisibility, request.StudentID, request.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := objectID(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return cellResponseToProto(h.controller.UpdateMark(ctx, user, dtos.UpdateMarkDTO{ID: id, Version: int(request.Version), AddMarkDTO: mark}))
+}
+
+func (h *Grpc) DeleteMark(ctx context.Context, request *protojournal.DeleteCellRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+	return cellResponseToProto(h.controller.DeleteMark(ctx, user, request.Id, int(request.Version)))
+}
+
+func (h *Grpc) AddAbsence(ctx context.Context, request *protojournal.AddAbsenceRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	absence, err := addAbsenceDTO(request.Time, request.Comment, request.CommentVisibility, request.StudentID, request.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cellResponseToProto(h.controller.AddAbsence(ctx, absence, user))
+}
+
+func (h *Grpc) UpdateAbsence(ctx context.Context, request *protojournal.UpdateAbsenceRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	absence, err := addAbsenceDTO(request.Time, request.Comment, request.CommentVisibility, request.StudentID, request.LessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := objectID(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return cellResponseToProto(h.controller.UpdateAbsence(ctx, user, dtos.UpdateAbsencesDTO{ID: id, Version: int(request.Version), AddAbsencesDTO: absence}))
+}
+
+func (h *Grpc) DeleteAbsence(ctx context.Context, request *protojournal.DeleteCellRequest) (*protojournal.CellResponse, error) {
+	user := h.GetGrpcUser(ctx)
+	return cellResponseToProto(h.controller.DeleteAbsence(ctx, user, request.Id, int(request.Version)))
+}
+
+func objectID(hex string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, errors.Wrap(controllers.NotValidParams, "id")
+	}
+
+	return id, nil
+}
+
+func addMarkDTO(mark, comment, visibility, studentID, lessonID string) (dtos.AddMarkDTO, error) {
+	student, err := objectID(studentID)
+	if err != nil {
+		return dtos.AddMarkDTO{}, err
+	}
+
+	lesson, err := objectID(lessonID)
+	if err != nil {
+		return dtos.AddMarkDTO{}, err
+	}
+
+	return dtos.AddMarkDTO{
+		Mark:              mark,
+		Comment:           comment,
+		CommentVisibility: entities.CommentVisibility(visibility),
+		StudentID:         student,
+		LessonID:          lesson,
+	}, nil
+}
+
+func addAbsenceDTO(time *int32, comment, visibility, studentID, lessonID string) (dtos.AddAbsencesDTO, error) {
+	student, err := objectID(studentID)
+	if err != nil {
+		return dtos.AddAbsencesDTO{}, err
+	}
+
+	lesson, err := objectID(lessonID)
+	if err != nil {
+		return dtos.AddAbsencesDTO{}, err
+	}
+
+	absence := dtos.AddAbsencesDTO{
+		Comment:           comment,
+		CommentVisibility: entities.CommentVisibility(visibility),
+		StudentID:         student,
+		LessonID:          lesson,
+	}
+
+	if time != nil {
+		t := int(*time)
+		absence.Time = &t
+	}
+
+	return absence, nil
+}
+
+// cellResponseToProto passes the error through, version conflicts are returned without the actual cell
+func cellResponseToProto(response entities.CellResponse, err error) (*protojournal.CellResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &protojournal.CellResponse{
+		Cell:       cellToProto(response.Cell),
+		Average:    response.Average,
+		MarkAmount: marksAmountToProto(response.MarkAmount),
+		RowColor:   response.RowColor,
+	}, nil
+}
+
+func cellToProto(cell entities.Cell) *protojournal.JournalCell {
+	marks := make([]*protojournal.Mark, 0, len(cell.Marks))
+	for _, mark := range cell.Marks {
+		marks = append(marks, &protojournal.Mark{
+			Id:                mark.ID.Hex(),
+			Mark:              mark.Mark,
+			Comment:           mark.Comment,
+			CommentVisibility: string(mark.CommentVisibility),
+			Version:           int32(mark.Version),
+			StudentID:         mark.StudentID.Hex(),
+			LessonID:          mark.LessonID.Hex(),
+			StudyPlaceID:      mark.StudyPlaceID.Hex(),
+		})
+	}
+
+	absences := make([]*protojournal.Absence, 0, len(cell.Absences))
+	for _, absence := range cell.Absences {
+		a := &protojournal.Absence{
+			Id:                absence.ID.Hex(),
+			Comment:           absence.Comment,
+			CommentVisibility: string(absence.CommentVisibility),
+			Version:           int32(absence.Version),
+			StudentID:         absence.StudentID.Hex(),
+			LessonID:          absence.LessonID.Hex(),
+			StudyPlaceID:      absence.StudyPlaceID.Hex(),
+		}
+		if absence.Time != nil {
+			t := int32(*absence.Time)
+			a.Time = &t
+		}
+
+		absences = append(absences, a)
+	}
+
+	return &protojournal.JournalCell{
+		Id:               cell.Id.Hex(),
+		Version:          int32(cell.Version),
+		Type:             cell.Type,
+		JournalCellColor: cell.JournalCellColor,
+		Marks:            marks,
+		Absences:         absences,
+	}
+}
+
+func marksAmountToProto(amounts map[string]int) []*protojournal.MarkAmount {
+	response := make([]*protojournal.MarkAmount, 0, len(amounts))
+	for mark, amount := range amounts {
+		response = append(response, &protojournal.MarkAmount{Mark: mark, Amount: int32(amount)})
+	}
+
+	slices.SortFunc(response, func(a, b *protojournal.MarkAmount) bool {
+		return a.Mark < b.Mark
+	})
+
+	return response
+}
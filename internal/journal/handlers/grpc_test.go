@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"studyum/grpc/journal/protojournal"
+	authEntities "studyum/internal/auth/entities"
+	auth "studyum/internal/auth/handlers"
+	"studyum/internal/auth/handlers/grpctest"
+	"studyum/internal/journal/controllers"
+	"studyum/internal/journal/entities"
+	"testing"
+)
+
+type testController struct {
+	controllers.Controller
+}
+
+func (c testController) DeleteMark(context.Context, authEntities.User, string, int) (entities.CellResponse, error) {
+	return entities.CellResponse{}, nil
+}
+
+func TestGrpcAuth(t *testing.T) {
+	users := grpctest.Users{
+		"student": {StudyPlaceInfo: authEntities.UserStudyPlaceInfo{Accepted: true}},
+		"teacher": {StudyPlaceInfo: authEntities.UserStudyPlaceInfo{Accepted: true, Permissions: []string{authEntities.PermissionEditJournal}}},
+	}
+	conn := grpctest.Serve(t, users, func(server *grpc.Server, middleware auth.Middleware) {
+		NewGrpc(middleware, testController{}, nil, server)
+	})
+	client := protojournal.NewJournalClient(conn)
+
+	_, err := client.GetJournal(context.Background(), &protojournal.JournalRequest{})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	_, err = client.DeleteMark(grpctest.Context("student"), &protojournal.DeleteCellRequest{})
+	assert.Equal(t, status.Code(err), codes.PermissionDenied)
+
+	_, err = client.DeleteMark(grpctest.Context("teacher"), &protojournal.DeleteCellRequest{})
+	assert.Equal(t, err, nil)
+}
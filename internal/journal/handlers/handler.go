@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"net/http"
+	"strconv"
 	auth "studyum/internal/auth/handlers"
 	"studyum/internal/journal/controllers"
 	"studyum/internal/journal/dtos"
@@ -16,6 +18,10 @@ type Handler interface {
 
 	GetJournal(ctx *gin.Context)
 	GetUserJournal(ctx *gin.Context)
+	GetChildJournal(ctx *gin.Context)
+
+	PreviewJournalImport(ctx *gin.Context)
+	ImportJournal(ctx *gin.Context)
 
 	AddMarks(ctx *gin.Context)
 	AddMark(ctx *gin.Context)
@@ -49,6 +55,13 @@ func NewJournalHandler(middleware auth.Middleware, controller controllers.Contro
 	group.GET("/options", h.MemberAuth(), h.GetJournalAvailableOptions)
 	group.GET("/:group/:subject/:teacher", h.MemberAuth(), h.GetJournal)
 	group.GET("", h.MemberAuth(), h.GetUserJournal)
+	group.GET("/children/:id", h.MemberAuth(), h.GetChildJournal)
+
+	journalImport := group.Group("/import/:group/:subject/:teacher", h.MemberAuth("editJournal"))
+	{
+		journalImport.POST("/preview", h.PreviewJournalImport)
+		journalImport.POST("", h.ImportJournal)
+	}
 
 	//todo change endpoint to marks
 	mark := group.Group("/mark", h.MemberAuth("editJournal"))
@@ -159,6 +172,83 @@ func (j *handler) GetUserJournal(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, journal)
 }
 
+// GetChildJournal godoc
+// @Param id path string true "Child ID"
+// @Router /children/{id} [get]
+func (j *handler) GetChildJournal(ctx *gin.Context) {
+	user := j.GetUser(ctx)
+
+	journal, err := j.journalController.BuildChildJournal(ctx, user, ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, journal)
+}
+
+// PreviewJournalImport godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Param teacher path string true "Teacher"
+// @Param file formData file true "Journal xlsx"
+// @Router /import/{group}/{subject}/{teacher}/preview [post]
+func (j *handler) PreviewJournalImport(ctx *gin.Context) {
+	user := j.GetUser(ctx)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	preview, err := j.controller.PreviewJournalImport(ctx, user, ctx.Param("group"), ctx.Param("subject"), ctx.Param("teacher"), reader)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preview)
+}
+
+// ImportJournal godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Param teacher path string true "Teacher"
+// @Param file formData file true "Journal xlsx"
+// @Router /import/{group}/{subject}/{teacher} [post]
+func (j *handler) ImportJournal(ctx *gin.Context) {
+	user := j.GetUser(ctx)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	result, err := j.controller.ImportJournal(ctx, user, ctx.Param("group"), ctx.Param("subject"), ctx.Param("teacher"), reader)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
 // AddMarks godoc
 // @Router /mark/list [post]
 func (j *handler) AddMarks(ctx *gin.Context) {
@@ -211,6 +301,10 @@ func (j *handler) UpdateMark(ctx *gin.Context) {
 	}
 
 	cellResponse, err := j.controller.UpdateMark(ctx, user, mark)
+	if errors.Is(err, controllers.ErrConflict) {
+		ctx.JSON(http.StatusConflict, cellResponse)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -221,13 +315,24 @@ func (j *handler) UpdateMark(ctx *gin.Context) {
 
 // DeleteMark godoc
 // @Param id path string true "Mark ID"
+// @Param version query int false "Mark version, may be omitted only for marks without a version"
 // @Router /mark/{id} [delete]
 func (j *handler) DeleteMark(ctx *gin.Context) {
 	user := j.GetUser(ctx)
 
 	markId := ctx.Param("id")
 
-	cellResponse, err := j.controller.DeleteMark(ctx, user, markId)
+	version, err := strconv.Atoi(ctx.DefaultQuery("version", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cellResponse, err := j.controller.DeleteMark(ctx, user, markId, version)
+	if errors.Is(err, controllers.ErrConflict) {
+		ctx.JSON(http.StatusConflict, cellResponse)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -288,6 +393,10 @@ func (j *handler) UpdateAbsence(ctx *gin.Context) {
 	}
 
 	cellResponse, err := j.controller.UpdateAbsence(ctx, user, absences)
+	if errors.Is(err, controllers.ErrConflict) {
+		ctx.JSON(http.StatusConflict, cellResponse)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -298,12 +407,24 @@ func (j *handler) UpdateAbsence(ctx *gin.Context) {
 
 // DeleteAbsence godoc
 // @Param id path string true "Absence ID"
+// @Param version query int false "Absence version, may be omitted only for absences without a version"
 // @Router /absences/{id} [delete]
 func (j *handler) DeleteAbsence(ctx *gin.Context) {
 	user := j.GetUser(ctx)
 
 	absencesID := ctx.Param("id")
-	cellResponse, err := j.controller.DeleteAbsence(ctx, user, absencesID)
+
+	version, err := strconv.Atoi(ctx.DefaultQuery("version", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cellResponse, err := j.controller.DeleteAbsence(ctx, user, absencesID, version)
+	if errors.Is(err, controllers.ErrConflict) {
+		ctx.JSON(http.StatusConflict, cellResponse)
+		return
+	}
 	if err != nil {
 		_ = ctx.Error(err)
 		return
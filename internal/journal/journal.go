@@ -2,7 +2,9 @@ package journal
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
 	apps "studyum/internal/apps/controllers"
 	auth "studyum/internal/auth/handlers"
 	"studyum/internal/journal/controllers"
@@ -10,23 +12,40 @@ import (
 	"studyum/internal/journal/handlers/swagger"
 	"studyum/internal/journal/repositories"
 	"studyum/pkg/encryption"
+	"studyum/pkg/firebase"
+	"studyum/pkg/mail"
+	"time"
 )
 
 // @BasePath /api/journal
 
 //go:generate swag init --instanceName journal -o handlers/swagger -g journal.go -ot go,yaml
-func New(core *gin.RouterGroup, auth auth.Middleware, apps apps.Controller, encrypt encryption.Encryption, db *mongo.Database) handlers.Handler {
+func New(core *gin.RouterGroup, grpcServer *grpc.Server, auth auth.Middleware, apps apps.Controller, encrypt encryption.Encryption, mailer mail.Mail, firebase firebase.Firebase, db *mongo.Database, redisClient *redis.Client) (handlers.Handler, controllers.Journal, controllers.Digest, controllers.CheckIn) {
 	swagger.SwaggerInfojournal.BasePath = "/api/journal"
 
 	users := db.Collection("Users")
+	signUpCodes := db.Collection("SignUpCodes")
 	lessons := db.Collection("Lessons")
 	studyPlaces := db.Collection("StudyPlaces")
 
-	repository := repositories.NewJournalRepository(users, lessons, studyPlaces)
+	repository := repositories.NewJournalRepository(users, signUpCodes, lessons, studyPlaces)
 
-	queryController := controllers.NewJournalController(repository, encrypt)
+	cache := repositories.NewMemoryCache(time.Minute * 10)
+	if redisClient != nil {
+		cache = repositories.NewRedisCache(redisClient, time.Minute*10)
+	}
+
+	queryController := controllers.NewJournalController(repository, cache, encrypt)
 	controller := controllers.NewController(queryController, repository, encrypt, apps)
 
+	checkInRepository := repositories.NewCheckInRepository(db.Collection("CheckInSessions"))
+	checkInController := controllers.NewCheckInController(checkInRepository, repository, controller, apps)
+
+	digestRepository := repositories.NewDigestRepository(db.Collection("Digests"))
+	digestController := controllers.NewDigestController(repository, digestRepository, encrypt, mailer, firebase)
+
 	handler := handlers.NewJournalHandler(auth, controller, queryController, core)
-	return handler
+	handlers.NewCheckIn(auth, checkInController, core.Group("/check-in"))
+	handlers.NewGrpc(auth, controller, queryController, grpcServer)
+	return handler, queryController, digestController, checkInController
 }
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
+)
+
+// Cache keeps built journals until a mark, absence or lesson of their group changes.
+// Journals are stored raw: titles are still encrypted and colors are not calculated yet.
+// GetJournal returns the cache key of the current generation even on a miss, a journal built after the miss
+// is set under that key, so an invalidation while it is being built is not overwritten with stale data.
+type Cache interface {
+	GetJournal(ctx context.Context, studyPlaceID primitive.ObjectID, group string, key string) (entities.Journal, string, bool)
+	SetJournal(ctx context.Context, cacheKey string, journal entities.Journal)
+
+	InvalidateGroup(ctx context.Context, studyPlaceID primitive.ObjectID, group string)
+	InvalidateStudyPlace(ctx context.Context, studyPlaceID primitive.ObjectID)
+}
+
+func SubjectJournalKey(subject, teacher string) string {
+	return "subject:" + subject + ":" + teacher
+}
+
+func StudentJournalKey(studentID primitive.ObjectID) string {
+	return "student:" + studentID.Hex()
+}
+
+func studyPlacePrefix(studyPlaceID primitive.ObjectID, studyPlaceGeneration string) string {
+	return "journal:" + studyPlaceID.Hex() + ":" + studyPlaceGeneration + ":"
+}
+
+func groupPrefix(studyPlaceID primitive.ObjectID, studyPlaceGeneration string, group string, groupGeneration string) string {
+	return studyPlacePrefix(studyPlaceID, studyPlaceGeneration) + group + ":" + groupGeneration + ":"
+}
+
+func cacheKey(studyPlaceID primitive.ObjectID, studyPlaceGeneration string, group string, groupGeneration string, key string) string {
+	return groupPrefix(studyPlaceID, studyPlaceGeneration, group, groupGeneration) + key
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheSweep(t *testing.T) {
+	cache := NewMemoryCache(time.Millisecond * 20).(*memoryCache)
+	ctx := context.Background()
+	studyPlaceID := primitive.NewObjectID()
+
+	_, key, _ := cache.GetJournal(ctx, studyPlaceID, "group", "old")
+	cache.SetJournal(ctx, key, entities.Journal{})
+
+	_, _, ok := cache.GetJournal(ctx, studyPlaceID, "group", "old")
+	assert.Equal(t, ok, true)
+
+	time.Sleep(time.Millisecond * 30)
+
+	_, _, ok = cache.GetJournal(ctx, studyPlaceID, "group", "old")
+	assert.Equal(t, ok, false)
+
+	// the next journal set after the ttl removes expired entries
+	_, key, _ = cache.GetJournal(ctx, studyPlaceID, "other", "new")
+	cache.SetJournal(ctx, key, entities.Journal{})
+
+	assert.Equal(t, len(cache.entries), 1)
+
+	_, _, ok = cache.GetJournal(ctx, studyPlaceID, "other", "new")
+	assert.Equal(t, ok, true)
+}
+
+func TestCacheInvalidationWhileBuilding(t *testing.T) {
+	server := miniredis.RunT(t)
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(time.Minute),
+		"redis":  NewRedisCache(redis.NewClient(&redis.Options{Addr: server.Addr()}), time.Minute),
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			studyPlaceID := primitive.NewObjectID()
+			stale := entities.Journal{Info: entities.Info{Group: "stale"}}
+
+			// a journal is built from the data read before the group is invalidated
+			_, key, ok := cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			assert.Equal(t, ok, false)
+			cache.InvalidateGroup(ctx, studyPlaceID, "group")
+			cache.SetJournal(ctx, key, stale)
+
+			_, _, ok = cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			assert.Equal(t, ok, false)
+
+			_, key, _ = cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			cache.InvalidateStudyPlace(ctx, studyPlaceID)
+			cache.SetJournal(ctx, key, stale)
+
+			_, _, ok = cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			assert.Equal(t, ok, false)
+
+			_, key, _ = cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			cache.SetJournal(ctx, key, stale)
+
+			journal, _, ok := cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			assert.Equal(t, ok, true)
+			assert.Equal(t, journal.Info.Group, "stale")
+
+			cache.InvalidateGroup(ctx, studyPlaceID, "group")
+			_, _, ok = cache.GetJournal(ctx, studyPlaceID, "group", "student")
+			assert.Equal(t, ok, false)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/journal/entities"
+	"time"
+)
+
+type CheckInRepository interface {
+	AddCheckInSession(ctx context.Context, session entities.CheckInSession) error
+	GetOpenCheckInSession(ctx context.Context, lessonID primitive.ObjectID) (entities.CheckInSession, error)
+	GetExpiredCheckInSessions(ctx context.Context, now time.Time) ([]entities.CheckInSession, error)
+	AddCheckIn(ctx context.Context, sessionID primitive.ObjectID, checkIn entities.CheckIn) error
+	CloseCheckInSession(ctx context.Context, sessionID primitive.ObjectID) (entities.CheckInSession, error)
+}
+
+type checkInRepository struct {
+	sessions *mongo.Collection
+}
+
+func NewCheckInRepository(sessions *mongo.Collection) CheckInRepository {
+	r := &checkInRepository{sessions: sessions}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (r *checkInRepository) createIndexes(ctx context.Context) {
+	_, err := r.sessions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "lessonID", Value: 1}, {Key: "closed", Value: 1}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating check-in indexes: " + err.Error())
+	}
+
+	_, err = r.sessions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "closed", Value: 1}, {Key: "endDate", Value: 1}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating check-in indexes: " + err.Error())
+	}
+}
+
+func (r *checkInRepository) AddCheckInSession(ctx context.Context, session entities.CheckInSession) error {
+	_, err := r.sessions.InsertOne(ctx, session)
+	return err
+}
+
+func (r *checkInRepository) GetOpenCheckInSession(ctx context.Context, lessonID primitive.ObjectID) (session entities.CheckInSession, err error) {
+	err = r.sessions.FindOne(ctx, bson.M{"lessonID": lessonID, "closed": false}).Decode(&session)
+	return
+}
+
+// GetExpiredCheckInSessions returns open sessions of lessons which have ended by the time now
+func (r *checkInRepository) GetExpiredCheckInSessions(ctx context.Context, now time.Time) ([]entities.CheckInSession, error) {
+	cursor, err := r.sessions.Find(ctx, bson.M{"closed": false, "endDate": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []entities.CheckInSession
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *checkInRepository) AddCheckIn(ctx context.Context, sessionID primitive.ObjectID, checkIn entities.CheckIn) error {
+	_, err := r.sessions.UpdateOne(ctx,
+		bson.M{"_id": sessionID, "closed": false, "checkIns.studentID": bson.M{"$ne": checkIn.StudentID}},
+		bson.M{"$push": bson.M{"checkIns": checkIn}},
+	)
+	return err
+}
+
+func (r *checkInRepository) CloseCheckInSession(ctx context.Context, sessionID primitive.ObjectID) (session entities.CheckInSession, err error) {
+	opt := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = r.sessions.FindOneAndUpdate(ctx, bson.M{"_id": sessionID, "closed": false}, bson.M{"$set": bson.M{"closed": true}}, opt).Decode(&session)
+	return
+}
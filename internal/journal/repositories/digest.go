@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/journal/entities"
+	"time"
+)
+
+const guardianDigestID = "guardians"
+
+var ErrDigestLocked = errors.New("digest is sent by another instance")
+
+type DigestRepository interface {
+	LockDigest(ctx context.Context, now time.Time, lockedUntil time.Time) (entities.DigestState, error)
+	UnlockDigest(ctx context.Context, sentAt time.Time) error
+}
+
+type digestRepository struct {
+	digests *mongo.Collection
+}
+
+func NewDigestRepository(digests *mongo.Collection) DigestRepository {
+	return &digestRepository{digests: digests}
+}
+
+// LockDigest locks the digest until lockedUntil and returns its state,
+// it returns ErrDigestLocked if the lock of another instance has not expired by the time now
+func (r *digestRepository) LockDigest(ctx context.Context, now time.Time, lockedUntil time.Time) (state entities.DigestState, err error) {
+	err = r.digests.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": guardianDigestID, "lockedUntil": bson.M{"$not": bson.M{"$gt": now}}},
+		bson.M{"$set": bson.M{"lockedUntil": lockedUntil}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&state)
+	if mongo.IsDuplicateKeyError(err) {
+		return entities.DigestState{}, ErrDigestLocked
+	}
+
+	return
+}
+
+// UnlockDigest releases the lock and saves when the last digest was sent
+func (r *digestRepository) UnlockDigest(ctx context.Context, sentAt time.Time) error {
+	_, err := r.digests.UpdateByID(ctx, guardianDigestID, bson.M{
+		"$set":   bson.M{"sentAt": sentAt},
+		"$unset": bson.M{"lockedUntil": ""},
+	})
+	return err
+}
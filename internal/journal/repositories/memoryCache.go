@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"strings"
+	"studyum/internal/journal/entities"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	data   []byte
+	expire time.Time
+}
+
+// memoryCache bumps generations that are part of every key like redisCache does
+// and drops the entries of the old generation right away. Expired entries are dropped
+// once per ttl while a journal is set, so journals which are never requested again don't stay in memory
+type memoryCache struct {
+	mutex sync.RWMutex
+
+	ttl     time.Duration
+	sweepAt time.Time
+	entries map[string]memoryEntry
+
+	studyPlaceGenerations map[primitive.ObjectID]int
+	groupGenerations      map[primitive.ObjectID]map[string]int
+}
+
+func NewMemoryCache(ttl time.Duration) Cache {
+	return &memoryCache{
+		ttl:                   ttl,
+		entries:               map[string]memoryEntry{},
+		studyPlaceGenerations: map[primitive.ObjectID]int{},
+		groupGenerations:      map[primitive.ObjectID]map[string]int{},
+	}
+}
+
+// groupPrefix returns the key prefix of the current generation of the group, the caller holds the lock
+func (c *memoryCache) groupPrefix(studyPlaceID primitive.ObjectID, group string) string {
+	studyPlaceGeneration := strconv.Itoa(c.studyPlaceGenerations[studyPlaceID])
+	groupGeneration := strconv.Itoa(c.groupGenerations[studyPlaceID][group])
+
+	return groupPrefix(studyPlaceID, studyPlaceGeneration, group, groupGeneration)
+}
+
+func (c *memoryCache) GetJournal(_ context.Context, studyPlaceID primitive.ObjectID, group string, key string) (entities.Journal, string, bool) {
+	c.mutex.RLock()
+	cacheKey := c.groupPrefix(studyPlaceID, group) + key
+	entry, ok := c.entries[cacheKey]
+	c.mutex.RUnlock()
+
+	if !ok || time.Now().After(entry.expire) {
+		return entities.Journal{}, cacheKey, false
+	}
+
+	var journal entities.Journal
+	if err := json.Unmarshal(entry.data, &journal); err != nil {
+		return entities.Journal{}, cacheKey, false
+	}
+
+	return journal, cacheKey, true
+}
+
+func (c *memoryCache) SetJournal(_ context.Context, cacheKey string, journal entities.Journal) {
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if now.After(c.sweepAt) {
+		c.sweep(now)
+		c.sweepAt = now.Add(c.ttl)
+	}
+
+	c.entries[cacheKey] = memoryEntry{data: data, expire: now.Add(c.ttl)}
+}
+
+// sweep removes expired entries, the caller holds the lock
+func (c *memoryCache) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expire) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// deletePrefix removes entries with keys starting with the prefix, the caller holds the lock
+func (c *memoryCache) deletePrefix(prefix string) {
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *memoryCache) InvalidateGroup(_ context.Context, studyPlaceID primitive.ObjectID, group string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.deletePrefix(c.groupPrefix(studyPlaceID, group))
+
+	if c.groupGenerations[studyPlaceID] == nil {
+		c.groupGenerations[studyPlaceID] = map[string]int{}
+	}
+	c.groupGenerations[studyPlaceID][group]++
+}
+
+func (c *memoryCache) InvalidateStudyPlace(_ context.Context, studyPlaceID primitive.ObjectID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.deletePrefix(studyPlacePrefix(studyPlaceID, strconv.Itoa(c.studyPlaceGenerations[studyPlaceID])))
+	c.studyPlaceGenerations[studyPlaceID]++
+}
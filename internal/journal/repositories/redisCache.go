@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	r "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/journal/entities"
+	"time"
+)
+
+// redisCache does not delete keys on invalidation, it bumps generation counters
+// that are part of every key instead, so stale journals just expire with ttl
+type redisCache struct {
+	client *r.Client
+	ttl    time.Duration
+}
+
+func NewRedisCache(client *r.Client, ttl time.Duration) Cache {
+	return &redisCache{client: client, ttl: ttl}
+}
+
+func (c *redisCache) studyPlaceGenerationKey(studyPlaceID primitive.ObjectID) string {
+	return "journal:generation:" + studyPlaceID.Hex()
+}
+
+func (c *redisCache) groupGenerationKey(studyPlaceID primitive.ObjectID, group string) string {
+	return "journal:generation:" + studyPlaceID.Hex() + ":" + group
+}
+
+func (c *redisCache) key(ctx context.Context, studyPlaceID primitive.ObjectID, group string, key string) (string, error) {
+	generations, err := c.client.MGet(ctx, c.studyPlaceGenerationKey(studyPlaceID), c.groupGenerationKey(studyPlaceID, group)).Result()
+	if err != nil {
+		return "", err
+	}
+
+	studyPlaceGeneration, _ := generations[0].(string)
+	groupGeneration, _ := generations[1].(string)
+
+	return cacheKey(studyPlaceID, studyPlaceGeneration, group, groupGeneration, key), nil
+}
+
+func (c *redisCache) GetJournal(ctx context.Context, studyPlaceID primitive.ObjectID, group string, key string) (entities.Journal, string, bool) {
+	redisKey, err := c.key(ctx, studyPlaceID, group, key)
+	if err != nil {
+		return entities.Journal{}, "", false
+	}
+
+	data, err := c.client.Get(ctx, redisKey).Bytes()
+	if err != nil {
+		return entities.Journal{}, redisKey, false
+	}
+
+	var journal entities.Journal
+	if err = json.Unmarshal(data, &journal); err != nil {
+		return entities.Journal{}, redisKey, false
+	}
+
+	return journal, redisKey, true
+}
+
+func (c *redisCache) SetJournal(ctx context.Context, redisKey string, journal entities.Journal) {
+	if redisKey == "" {
+		return
+	}
+
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return
+	}
+
+	if err = c.client.Set(ctx, redisKey, data, c.ttl).Err(); err != nil {
+		logrus.Warningln("Error caching journal: " + err.Error())
+	}
+}
+
+func (c *redisCache) InvalidateGroup(ctx context.Context, studyPlaceID primitive.ObjectID, group string) {
+	if err := c.client.Incr(ctx, c.groupGenerationKey(studyPlaceID, group)).Err(); err != nil {
+		logrus.Errorln("Error invalidating journal cache: " + err.Error())
+	}
+}
+
+func (c *redisCache) InvalidateStudyPlace(ctx context.Context, studyPlaceID primitive.ObjectID) {
+	if err := c.client.Incr(ctx, c.studyPlaceGenerationKey(studyPlaceID)).Err(); err != nil {
+		logrus.Errorln("Error invalidating journal cache: " + err.Error())
+	}
+}
@@ -2,9 +2,12 @@ package repositories
 
 import (
 	"context"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	auth "studyum/internal/auth/entities"
 	general "studyum/internal/general/entities"
 	"studyum/internal/journal/entities"
 	"studyum/pkg/hMongo"
@@ -16,17 +19,21 @@ type Repository interface {
 	AddMarks(ctx context.Context, marks []entities.Mark, teacher string) error
 	AddMark(ctx context.Context, mark entities.Mark, teacher string) error
 	UpdateMark(ctx context.Context, mark entities.Mark, teacher string) error
-	DeleteMarkByID(ctx context.Context, id primitive.ObjectID, teacher string) error
+	DeleteMarkByID(ctx context.Context, id primitive.ObjectID, version int, teacher string) error
 
 	GetAllAvailableOptions(ctx context.Context, id primitive.ObjectID, editable bool) ([]entities.AvailableOption, error)
 	GetAvailableOptions(ctx context.Context, id primitive.ObjectID, teacher string, editable bool) ([]entities.AvailableOption, error)
 	GetAvailableTuitionOptions(ctx context.Context, id primitive.ObjectID, name string, editable bool) ([]entities.AvailableOption, error)
 
-	GetStudentJournal(ctx context.Context, userId primitive.ObjectID, group string, studyPlaceId primitive.ObjectID) (entities.Journal, error)
-	GetJournal(ctx context.Context, option entities.AvailableOption, studyPlaceId primitive.ObjectID) (entities.Journal, error)
+	GetStudents(ctx context.Context, studyPlaceID primitive.ObjectID, group string) ([]entities.Student, error)
+	GetStudentByID(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) (entities.Student, error)
+	GetGuardians(ctx context.Context) ([]auth.User, error)
+	GetStudentLessonsSince(ctx context.Context, studentID primitive.ObjectID, group string, since time.Time) ([]entities.Lesson, error)
+	GetGroupLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group string) ([]entities.Lesson, error)
+	GetSubjectLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject, teacher string) ([]entities.Lesson, error)
+	GetReportLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, lessonType string, from, to *time.Time) ([]entities.Lesson, error)
 
 	GetLessonByID(ctx context.Context, id primitive.ObjectID) (entities.Lesson, error)
-	GetLessons(ctx context.Context, userId primitive.ObjectID, group, teacher, subject string, studyPlaceId primitive.ObjectID) ([]entities.Lesson, error)
 
 	GetStudyPlaceByID(ctx context.Context, id primitive.ObjectID) (general.StudyPlace, error)
 
@@ -34,375 +41,140 @@ type Repository interface {
 	AddAbsences(ctx context.Context, absences []entities.Absence, teacher string) error
 	AddAbsence(ctx context.Context, absence entities.Absence, teacher string) error
 	UpdateAbsence(ctx context.Context, absence entities.Absence, teacher string) error
-	DeleteAbsenceByID(ctx context.Context, id primitive.ObjectID, teacher string) error
-
-	GenerateMarksReport(ctx context.Context, group string, lessonType string, mark string, from, to *time.Time, studyPlaceId primitive.ObjectID) (entities.GeneratedTable, error)
-	GenerateAbsencesReport(ctx context.Context, group string, from, to *time.Time, id primitive.ObjectID) (entities.GeneratedTable, error)
-
-	GetJournalRowWithDates(ctx context.Context, userID primitive.ObjectID, subject, teacher, group string, studyPlaceId primitive.ObjectID) ([]*entities.Cell, []time.Time, error)
+	DeleteAbsenceByID(ctx context.Context, id primitive.ObjectID, version int, teacher string) error
 }
 
 type repository struct {
 	users       *mongo.Collection
+	signUpCodes *mongo.Collection
 	lessons     *mongo.Collection
 	studyPlaces *mongo.Collection
 }
 
-func NewJournalRepository(users *mongo.Collection, lessons *mongo.Collection, studyPlaces *mongo.Collection) Repository {
-	return &repository{users: users, lessons: lessons, studyPlaces: studyPlaces}
-}
+func NewJournalRepository(users *mongo.Collection, signUpCodes *mongo.Collection, lessons *mongo.Collection, studyPlaces *mongo.Collection) Repository {
+	r := &repository{users: users, signUpCodes: signUpCodes, lessons: lessons, studyPlaces: studyPlaces}
+	r.createIndexes(context.Background())
 
-func (j *repository) GenerateMarksReport(ctx context.Context, group string, lessonType string, mark string, from, to *time.Time, studyPlaceId primitive.ObjectID) (entities.GeneratedTable, error) {
-	var lessonMatcher = bson.M{
-		"group":        group,
-		"studyPlaceId": studyPlaceId,
-		"type":         lessonType,
-	}
+	return r
+}
 
-	if from != nil {
-		lessonMatcher["startDate"] = bson.M{"$gte": from}
+func (j *repository) createIndexes(ctx context.Context) {
+	_, err := j.lessons.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "studyPlaceId", Value: 1}, {Key: "group", Value: 1}, {Key: "startDate", Value: 1}}},
+		{Keys: bson.D{{Key: "studyPlaceId", Value: 1}, {Key: "group", Value: 1}, {Key: "subject", Value: 1}, {Key: "teacher", Value: 1}, {Key: "startDate", Value: 1}}},
+		{Keys: bson.D{{Key: "marks._id", Value: 1}}},
+		{Keys: bson.D{{Key: "absences._id", Value: 1}}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating journal indexes: " + err.Error())
 	}
+}
 
-	if to != nil {
-		lessonMatcher["startDate"] = bson.M{"$lte": to}
+func (j *repository) findLessons(ctx context.Context, filter bson.M) ([]entities.Lesson, error) {
+	opt := options.Find().SetSort(bson.D{{Key: "startDate", Value: 1}})
+	cursor, err := j.lessons.Find(ctx, filter, opt)
+	if err != nil {
+		return nil, err
 	}
 
-	if from != nil && to != nil {
-		lessonMatcher["startDate"] = bson.M{"$gte": from, "$lte": to}
+	var lessons []entities.Lesson
+	if err = cursor.All(ctx, &lessons); err != nil {
+		return nil, err
 	}
 
-	var cursor, err = j.users.Aggregate(ctx, bson.A{
-		bson.M{
-			"$group": bson.M{"_id": nil, "user": bson.M{"$push": "$$ROOT"}},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user._id":          1,
-				"user.name":         1,
-				"user.role":         1,
-				"user.roleName":     1,
-				"user.studyPlaceID": 1,
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "SignUpCodes",
-				"pipeline": bson.A{
-					bson.M{
-						"$project": bson.M{
-							"name":         1,
-							"role":         1,
-							"roleName":     1,
-							"studyPlaceID": 1,
-						},
-					},
-				},
-				"as": "codeUsers",
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user": bson.M{
-					"$filter": bson.M{
-						"input": bson.M{"$concatArrays": bson.A{"$codeUsers", "$user"}},
-						"as":    "user",
-						"cond":  bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$user.role", "group"}}, bson.M{"$eq": bson.A{"$$user.roleName", group}}, bson.M{"$eq": bson.A{"$$user.studyPlaceID", studyPlaceId}}}},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "Lessons",
-				"let":  bson.M{"userID": "$_id"},
-				"pipeline": bson.A{
-					bson.M{
-						"$match": lessonMatcher,
-					},
-				},
-				"as": "lessons",
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "StudyPlaces",
-				"pipeline": bson.A{
-					bson.M{"$match": bson.M{
-						"_id": studyPlaceId,
-					}},
-				},
-				"as": "studyPlace",
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons": bson.M{
-					"$sortArray": bson.M{
-						"input":  "$lessons",
-						"sortBy": bson.M{"startDate": 1},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$unwind": "$user",
-		},
-		bson.M{
-			"$unwind": "$lessons",
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.marks": hMongo.Filter("lessons.marks", hMongo.AEq("$$marks.studentID", "$user._id")),
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.marks": hMongo.Filter("lessons.marks", hMongo.AEq("$$marks.mark", mark)),
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id": bson.M{
-					"user":    "$user",
-					"subject": "$lessons.subject",
-				},
-				"title":   bson.M{"$first": "$user.name"},
-				"subject": bson.M{"$first": "$lessons.subject"},
-				"marks":   bson.M{"$push": "$lessons.marks"},
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id":  nil,
-				"list": bson.M{"$push": "$$ROOT"},
-			},
-		},
-		bson.M{
-			"$replaceRoot": bson.D{
-				{"newRoot", bson.D{hMongo.Func(`function (list) {
-					const groupBy = function (xs, key) {
-						return xs.reduce(function (rv, x) {
-							(rv[x[key]] = rv[x[key]] || []).push(x);
-							return rv;
-						}, {});
-					};
-                    
-    				list.forEach(el => {
-                        el.totalLen = el.marks.length
-                        el.marksLen = el.marks.flatMap(l => l ?? []).length
-                                               
-                        el.text = (el.totalLen - el.marksLen).toString() + "/" + el.totalLen.toString()
-                        
-                        delete el._id
-                        delete el.marks
-                    })
-                    
-                    list = groupBy(list, "title")
-                    list = Object.entries(list).map(entry => entry[1].sort((a, b) => a.subject > b.subject))
-                    list.forEach(el => el[0].temp = el.reduce((r, e) => {
-                        r.totalLessonsLen += e.totalLen
-                        r.totalMarksLen += e.marksLen
-                        return r
-                    }, {totalLessonsLen: 0, totalMarksLen: 0}))
-
-                  	let titles = list[0].map(el => el.subject)
-                  	titles.unshift("")
-                  	titles.push("")
-                  	
-                  	list = list.map(el => {
-                          let temp = el[0].temp
-                          let text = (temp.totalLessonsLen - temp.totalMarksLen).toString() + "/" + temp.totalLessonsLen.toString()
-                          return [el[0].title, ...el.map(v => v.text), text]
-                    })
-                                                           
-   					return {titles: titles, rows: list}
-				}`, "$list")}},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"_id":  0,
-				"list": 0,
-			},
-		},
-	})
+	return lessons, nil
+}
 
+func (j *repository) findStudents(ctx context.Context, collection *mongo.Collection, filter bson.M) ([]entities.Student, error) {
+	opt := options.Find().SetProjection(bson.M{"_id": 1, "name": 1})
+	cursor, err := collection.Find(ctx, filter, opt)
 	if err != nil {
-		return entities.GeneratedTable{}, err
+		return nil, err
 	}
 
-	if !cursor.Next(ctx) {
-		return entities.GeneratedTable{}, nil
-	}
-	var table entities.GeneratedTable
-	if err = cursor.Decode(&table); err != nil {
-		return entities.GeneratedTable{}, err
+	var students []entities.Student
+	if err = cursor.All(ctx, &students); err != nil {
+		return nil, err
 	}
 
-	return table, nil
+	return students, nil
 }
 
-func (j *repository) GenerateAbsencesReport(ctx context.Context, group string, from, to *time.Time, studyPlaceId primitive.ObjectID) (entities.GeneratedTable, error) {
-	var lessonMatcher = bson.M{
-		"group":        group,
-		"studyPlaceId": studyPlaceId,
+func (j *repository) GetStudents(ctx context.Context, studyPlaceID primitive.ObjectID, group string) ([]entities.Student, error) {
+	filter := bson.M{"role": "group", "roleName": group, "studyPlaceID": studyPlaceID}
+
+	codeStudents, err := j.findStudents(ctx, j.signUpCodes, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if from != nil {
-		lessonMatcher["startDate"] = bson.M{"$gte": from}
+	students, err := j.findStudents(ctx, j.users, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if to != nil {
-		lessonMatcher["startDate"] = bson.M{"$lte": to}
+	return append(codeStudents, students...), nil
+}
+
+func (j *repository) GetStudentByID(ctx context.Context, studyPlaceID primitive.ObjectID, id primitive.ObjectID) (student entities.Student, err error) {
+	filter := bson.M{"_id": id, "role": "group", "studyPlaceID": studyPlaceID}
+	opt := options.FindOne().SetProjection(bson.M{"_id": 1, "name": 1, "roleName": 1})
+
+	err = j.users.FindOne(ctx, filter, opt).Decode(&student)
+	return
+}
+
+func (j *repository) GetGuardians(ctx context.Context) ([]auth.User, error) {
+	cursor, err := j.users.Find(ctx, bson.M{"children.0": bson.M{"$exists": true}})
+	if err != nil {
+		return nil, err
 	}
 
-	if from != nil && to != nil {
-		lessonMatcher["startDate"] = bson.M{"$gte": from, "$lte": to}
+	var guardians []auth.User
+	if err = cursor.All(ctx, &guardians); err != nil {
+		return nil, err
 	}
 
-	var cursor, err = j.users.Aggregate(ctx, bson.A{
-		bson.M{
-			"$group": bson.M{"_id": nil, "user": bson.M{"$push": "$$ROOT"}},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user._id":          1,
-				"user.name":         1,
-				"user.role":         1,
-				"user.roleName":     1,
-				"user.studyPlaceID": 1,
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "SignUpCodes",
-				"pipeline": bson.A{
-					bson.M{
-						"$project": bson.M{
-							"name":         1,
-							"role":         1,
-							"roleName":     1,
-							"studyPlaceID": 1,
-						},
-					},
-				},
-				"as": "codeUsers",
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user": bson.M{
-					"$filter": bson.M{
-						"input": bson.M{"$concatArrays": bson.A{"$codeUsers", "$user"}},
-						"as":    "user",
-						"cond":  bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$user.role", "group"}}, bson.M{"$eq": bson.A{"$$user.roleName", group}}, bson.M{"$eq": bson.A{"$$user.studyPlaceID", studyPlaceId}}}},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "Lessons",
-				"let":  bson.M{"userID": "$_id"},
-				"pipeline": bson.A{
-					bson.M{"$match": lessonMatcher},
-				},
-				"as": "lessons",
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "StudyPlaces",
-				"pipeline": bson.A{
-					bson.M{"$match": bson.M{
-						"_id": studyPlaceId,
-					}},
-				},
-				"as": "studyPlace",
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons": bson.M{
-					"$sortArray": bson.M{
-						"input":  "$lessons",
-						"sortBy": bson.M{"startDate": 1},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$unwind": "$user",
-		},
-		bson.M{
-			"$unwind": "$lessons",
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.absences": hMongo.Filter("lessons.absences", hMongo.AEq("$$absences.studentID", "$user._id")),
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.absences": hMongo.Filter("lessons.absences", hMongo.AEq("$$absences.time", nil)),
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id": bson.M{
-					"user": "$user",
-					"date": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$lessons.startDate"}},
-				},
-				"title":    bson.M{"$first": "$user.name"},
-				"date":     bson.M{"$first": "$lessons.startDate"},
-				"absences": bson.M{"$sum": bson.M{"$cond": bson.M{"if": bson.M{"$isArray": "$lessons.absences"}, "then": bson.M{"$size": "$lessons.absences"}, "else": 0}}}},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id":  nil,
-				"list": bson.M{"$push": "$$ROOT"},
-			},
-		},
-		bson.M{
-			"$replaceRoot": bson.D{
-				{"newRoot", bson.D{hMongo.Func(`function (list) {
-					const groupBy = function (xs, key) {
-						return xs.reduce(function (rv, x) {
-							(rv[x[key]] = rv[x[key]] || []).push(x);
-							return rv;
-						}, {});
-					};
-                    
-                    list = groupBy(list, "title")
-                    list = Object.entries(list).map(entry => entry[1].sort((a, b) => a.date > b.date))
-
-                    let titles = list[0].map(el => el.date.getDate() + "." + (el.date.getMonth() + 1))
-                  	titles.unshift("")
-                  	titles.push("")
-                    
-                    list = list.map(el => {
-                          return [el[0].title, ...el.map(v => v.absences === 0 ? "" : v.absences.toString()), ""]
-                    })
-                    
-					return {titles: titles, rows: list}
-				}`, "$list")}},
-			},
+	return guardians, nil
+}
+
+func (j *repository) GetStudentLessonsSince(ctx context.Context, studentID primitive.ObjectID, group string, since time.Time) ([]entities.Lesson, error) {
+	matcher := bson.M{"studentID": studentID, "_id": bson.M{"$gte": primitive.NewObjectIDFromTimestamp(since)}}
+	return j.findLessons(ctx, bson.M{
+		"group": group,
+		"$or": bson.A{
+			bson.M{"marks": bson.M{"$elemMatch": matcher}},
+			bson.M{"absences": bson.M{"$elemMatch": matcher}},
 		},
 	})
+}
 
-	if err != nil {
-		return entities.GeneratedTable{}, err
+func (j *repository) GetGroupLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group string) ([]entities.Lesson, error) {
+	return j.findLessons(ctx, bson.M{"studyPlaceId": studyPlaceID, "group": group})
+}
+
+func (j *repository) GetSubjectLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject, teacher string) ([]entities.Lesson, error) {
+	return j.findLessons(ctx, bson.M{"studyPlaceId": studyPlaceID, "group": group, "subject": subject, "teacher": teacher})
+}
+
+func (j *repository) GetReportLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, lessonType string, from, to *time.Time) ([]entities.Lesson, error) {
+	filter := bson.M{"studyPlaceId": studyPlaceID, "group": group}
+	if lessonType != "" {
+		filter["type"] = lessonType
 	}
 
-	if !cursor.Next(ctx) {
-		return entities.GeneratedTable{}, nil
+	date := bson.M{}
+	if from != nil {
+		date["$gte"] = from
+	}
+	if to != nil {
+		date["$lte"] = to
 	}
-	var table entities.GeneratedTable
-	if err = cursor.Decode(&table); err != nil {
-		return entities.GeneratedTable{}, err
+	if len(date) != 0 {
+		filter["startDate"] = date
 	}
 
-	return table, nil
+	return j.findLessons(ctx, filter)
 }
 
 func (j *repository) getAvailableOptions(ctx context.Context, matcher bson.M, editable bool) ([]entities.AvailableOption, error) {
@@ -418,50 +190,6 @@ func (j *repository) getAvailableOptions(ctx context.Context, matcher bson.M, ed
 			"subject": bson.M{"$first": "$subject"},
 			"group":   bson.M{"$first": "$group"}},
 		},
-		bson.M{"$lookup": bson.M{
-			"from": "Users",
-			"let":  bson.M{"group": "$group"},
-			"pipeline": bson.A{
-				bson.M{
-					"$group": bson.M{"_id": nil, "user": bson.M{"$push": "$$ROOT"}},
-				},
-				bson.M{
-					"$lookup": bson.M{
-						"from": "SignUpCodes",
-						"pipeline": bson.A{
-							bson.M{
-								"$project": bson.M{
-									"name":         1,
-									"role":         1,
-									"roleName":     1,
-									"studyPlaceID": 1,
-								},
-							},
-						},
-						"as": "codeUsers",
-					},
-				},
-				bson.M{
-					"$project": bson.M{
-						"len": bson.M{"$size": bson.M{
-							"$filter": bson.M{
-								"input": bson.M{"$concatArrays": bson.A{"$codeUsers", "$user"}},
-								"as":    "user",
-								"cond": bson.M{
-									"$and": bson.A{
-										bson.M{"$eq": bson.A{"$$user.roleName", "$$group"}},
-									},
-								},
-							},
-						}},
-					},
-				},
-			},
-			"as": "user",
-		}},
-		bson.M{"$addFields": bson.M{"temp": bson.M{"$first": "$user"}}},
-		bson.M{"$match": bson.M{"temp.len": bson.M{"$gt": 0}}},
-		bson.M{"$addFields": bson.M{"editable": editable}},
 		bson.M{"$sort": bson.M{"group": 1, "subject": 1, "teacher": 1}},
 	})
 	if err != nil {
@@ -473,363 +201,52 @@ func (j *repository) getAvailableOptions(ctx context.Context, matcher bson.M, ed
 		return nil, err
 	}
 
-	return options, nil
-}
-
-func (j *repository) GetAllAvailableOptions(ctx context.Context, id primitive.ObjectID, editable bool) ([]entities.AvailableOption, error) {
-	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id}, editable)
-}
-
-func (j *repository) GetAvailableOptions(ctx context.Context, id primitive.ObjectID, teacher string, editable bool) ([]entities.AvailableOption, error) {
-	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id, "teacher": teacher}, editable)
-}
+	groups := make([]string, 0, len(options))
+	for _, option := range options {
+		groups = append(groups, option.Group)
+	}
 
-func (j *repository) GetAvailableTuitionOptions(ctx context.Context, id primitive.ObjectID, group string, editable bool) ([]entities.AvailableOption, error) {
-	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id, "group": group}, editable)
-}
+	filter := bson.M{"roleName": bson.M{"$in": groups}}
+	codeGroups, err := j.signUpCodes.Distinct(ctx, "roleName", filter)
+	if err != nil {
+		return nil, err
+	}
 
-func (j *repository) GetStudentJournal(ctx context.Context, userId primitive.ObjectID, group string, studyPlaceId primitive.ObjectID) (entities.Journal, error) {
-	cursor, err := j.lessons.Aggregate(ctx, bson.A{
-		bson.M{"$match": bson.M{"group": group, "studyPlaceId": studyPlaceId}},
-		bson.M{"$addFields": bson.M{
-			"marks":    hMongo.Filter("marks", hMongo.AEq("$$marks.studentID", userId)),
-			"absences": hMongo.Filter("absences", hMongo.AEq("$$absences.studentID", userId)),
-		}},
-		bson.M{
-			"$lookup": bson.M{
-				"from":     "StudyPlaces",
-				"pipeline": bson.A{bson.M{"$match": bson.M{"_id": studyPlaceId}}},
-				"as":       "studyPlace",
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id":        nil,
-				"studyPlace": bson.M{"$first": bson.M{"$first": "$studyPlace"}},
-				"lessons":    bson.M{"$push": "$$ROOT"},
-				"dates":      bson.M{"$addToSet": bson.M{"$toDate": bson.M{"$dateToString": bson.M{"date": "$startDate", "format": "%m/%d/%Y"}}}},
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"dates": bson.M{
-					"$sortArray": bson.M{
-						"input":  "$dates",
-						"sortBy": 1,
-					},
-				},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"lessons.studyPlace": 0,
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"rows": bson.M{
-					"$function": bson.M{
-						// language=JavaScript
-						"body": `function (studyPlace, lessons, dates) {
-                        const groupBy = function (xs, key) {
-                            return xs.reduce(function (rv, x) {
-                                //TODO sort
-                                (rv[x[key]] = rv[x[key]] || []).push(x);
-                                return rv;
-                            }, {});
-                        };
-
-                        let groupedLessons = groupBy(lessons, 'subject')
-
-                        for (const [key, value] of Object.entries(groupedLessons)) {
-                            groupedLessons[key] = value.sort((a, b) => a.startDate - b.startDate)
-                        }
-
-                        let rows = []
-                        for (const [key, value] of Object.entries(groupedLessons)) {
-                            rows.unshift({title: key, cells: [], info: {}})
-
-                            let added = 0
-                            for (let i = 0; i < value.length; i++) {
-                                let startTime = new Date(value[i].startDate.toDateString()).getTime()
-                                if (i > 0 && new Date(value[i - 1].startDate.toDateString()).getTime() === startTime) {
-									rows[0].cells.at(-1)
-									let prevLesson = rows[0].cells.at(-1)
-
-									if (value[i].journalCellColor != studyPlace.journalColors.general && prevLesson.journalCellColor == studyPlace.journalColors.general) {
-										prevLesson.journalCellColor = value[i].journalCellColor
-									}
-
-									prevLesson.marks = prevLesson.marks?.concat(value[i].marks ?? []) ?? value[i].marks
-									prevLesson.absences = prevLesson.absences?.concat(value[i].absences ?? []) ?? value[i].absences
-									prevLesson.type.push(value[i].type)
-									added--
-									continue
-                                }
-                                while (dates[i + added].getTime() !== startTime) {
-                                    rows[0].cells.push(null)
-                                    added++
-                                }
-                                rows[0].cells.push({
-                                 _id: value[i]._id,
-                                 type: [value[i].type],
-                                 journalCellColor: value[i].journalCellColor,
-                                 marks: value[i].marks,
-                                 absences: value[i].absences
-                                })
-                            }
-
-                            for (let i = added + value.length; i < dates.length; i++) {
-                                rows[0].cells.push(null)
-                            }
-                        }
-
-                        return rows.sort((a, b) => a.title > b.title)
-                    }`,
-						"args": bson.A{"$studyPlace", "$lessons", "$dates"},
-						"lang": "js",
-					},
-				},
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"info": bson.M{
-					"editable":   false,
-					"studyPlace": "$studyPlace",
-					"group":      group,
-					"teacher":    "",
-					"subject":    "",
-					"time":       time.Now(),
-				},
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"dates": bson.M{
-					"$map": bson.M{
-						"input": "$dates",
-						"as":    "date",
-						"in": bson.M{
-							"startDate": "$$date",
-							"endDate":   "$$date",
-						},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"lessons":    0,
-				"studyPlace": 0,
-			},
-		},
-	})
+	userGroups, err := j.users.Distinct(ctx, "roleName", filter)
 	if err != nil {
-		return entities.Journal{}, err
+		return nil, err
 	}
 
-	if !cursor.Next(ctx) {
-		return entities.Journal{
-			Info: entities.Info{
-				Editable:   false,
-				StudyPlace: general.StudyPlace{},
-				Group:      group,
-			},
-		}, nil
+	withStudents := make(map[string]bool, len(codeGroups)+len(userGroups))
+	for _, group := range append(codeGroups, userGroups...) {
+		if name, ok := group.(string); ok {
+			withStudents[name] = true
+		}
 	}
-	var journal entities.Journal
-	if err = cursor.Decode(&journal); err != nil {
-		return entities.Journal{}, err
+
+	filtered := make([]entities.AvailableOption, 0, len(options))
+	for _, option := range options {
+		if !withStudents[option.Group] {
+			continue
+		}
+
+		option.Editable = editable
+		filtered = append(filtered, option)
 	}
 
-	return journal, nil
+	return filtered, nil
 }
 
-func (j *repository) GetJournal(ctx context.Context, option entities.AvailableOption, studyPlaceId primitive.ObjectID) (entities.Journal, error) {
-	var cursor, err = j.users.Aggregate(ctx, bson.A{
-		bson.M{
-			"$group": bson.M{"_id": nil, "user": bson.M{"$push": "$$ROOT"}},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user._id":          1,
-				"user.name":         1,
-				"user.role":         1,
-				"user.roleName":     1,
-				"user.studyPlaceID": 1,
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "SignUpCodes",
-				"pipeline": bson.A{
-					bson.M{
-						"$project": bson.M{
-							"name":         1,
-							"role":         1,
-							"roleName":     1,
-							"studyPlaceID": 1,
-						},
-					},
-				},
-				"as": "codeUsers",
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"user": bson.M{
-					"$filter": bson.M{
-						"input": bson.M{"$concatArrays": bson.A{"$codeUsers", "$user"}},
-						"as":    "user",
-						"cond":  bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$user.role", "group"}}, bson.M{"$eq": bson.A{"$$user.roleName", option.Group}}, bson.M{"$eq": bson.A{"$$user.studyPlaceID", studyPlaceId}}}},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "Lessons",
-				"let":  bson.M{"userID": "$_id"},
-				"pipeline": bson.A{
-					bson.M{
-						"$match": bson.M{
-							"subject":      option.Subject,
-							"group":        option.Group,
-							"teacher":      option.Teacher,
-							"studyPlaceId": studyPlaceId,
-						},
-					},
-				},
-				"as": "lessons",
-			},
-		},
-		bson.M{
-			"$lookup": bson.M{
-				"from": "StudyPlaces",
-				"pipeline": bson.A{
-					bson.M{"$match": bson.M{
-						"_id": studyPlaceId,
-					}},
-				},
-				"as": "studyPlace",
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons": bson.M{
-					"$sortArray": bson.M{
-						"input":  "$lessons",
-						"sortBy": bson.M{"startDate": 1},
-					},
-				},
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"dates": "$lessons",
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"dates.marks":            0,
-				"dates.absences":         0,
-				"dates.journalCellColor": 0,
-				"dates.studyPlace":       0,
-			},
-		},
-		bson.M{
-			"$unwind": "$user",
-		},
-		bson.M{
-			"$unwind": "$lessons",
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.marks":    hMongo.Filter("lessons.marks", hMongo.AEq("$$marks.studentID", "$user._id")),
-				"lessons.absences": hMongo.Filter("lessons.absences", hMongo.AEq("$$absences.studentID", "$user._id")),
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"lessons.type": bson.A{"$lessons.type"},
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id": bson.M{
-					"_id":   "$user._id",
-					"title": "$user.name",
-				},
-				"dates":      bson.M{"$first": "$dates"},
-				"cells":      bson.M{"$push": "$lessons"},
-				"studyPlace": bson.M{"$first": bson.M{"$first": "$studyPlace"}},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"dates": "$dates",
-				"row": bson.M{
-					"_id":   "$_id._id",
-					"title": "$_id.title",
-					"cells": "$cells",
-				},
-				"studyPlace": "$studyPlace",
-			},
-		},
-		bson.M{
-			"$group": bson.M{
-				"_id":        nil,
-				"dates":      bson.M{"$first": "$dates"},
-				"rows":       bson.M{"$push": "$row"},
-				"studyPlace": bson.M{"$first": "$studyPlace"},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"rows.studyPlace": 0,
-			},
-		},
-		bson.M{
-			"$addFields": bson.M{
-				"info": bson.M{
-					"editable":   option.Editable,
-					"studyPlace": "$studyPlace",
-					"subject":    option.Subject,
-					"group":      option.Group,
-					"teacher":    option.Teacher,
-					"time":       time.Now(),
-				},
-			},
-		},
-		bson.M{
-			"$project": bson.M{
-				"studyPlace": 0,
-			},
-		},
-	})
-
-	if err != nil {
-		return entities.Journal{}, err
-	}
+func (j *repository) GetAllAvailableOptions(ctx context.Context, id primitive.ObjectID, editable bool) ([]entities.AvailableOption, error) {
+	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id}, editable)
+}
 
-	if !cursor.Next(ctx) {
-		return entities.Journal{
-			Info: entities.Info{
-				Editable:   option.Editable,
-				StudyPlace: general.StudyPlace{},
-				Group:      option.Subject,
-				Teacher:    option.Group,
-				Subject:    option.Teacher,
-			},
-		}, nil
-	}
-	var journal entities.Journal
-	if err = cursor.Decode(&journal); err != nil {
-		return entities.Journal{}, err
-	}
+func (j *repository) GetAvailableOptions(ctx context.Context, id primitive.ObjectID, teacher string, editable bool) ([]entities.AvailableOption, error) {
+	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id, "teacher": teacher}, editable)
+}
 
-	return journal, nil
+func (j *repository) GetAvailableTuitionOptions(ctx context.Context, id primitive.ObjectID, group string, editable bool) ([]entities.AvailableOption, error) {
+	return j.getAvailableOptions(ctx, bson.M{"studyPlaceId": id, "group": group}, editable)
 }
 
 func (j *repository) GetLessonByID(ctx context.Context, id primitive.ObjectID) (lesson entities.Lesson, err error) {
@@ -842,34 +259,21 @@ func (j *repository) GetStudyPlaceByID(ctx context.Context, id primitive.ObjectI
 	return
 }
 
-func (j *repository) GetLessons(ctx context.Context, userId primitive.ObjectID, group, teacher, subject string, studyPlaceId primitive.ObjectID) ([]entities.Lesson, error) {
-	lessonsCursor, err := j.lessons.Aggregate(ctx, mongo.Pipeline{
-		bson.D{{"$lookup", bson.M{
-			"from":         "Marks",
-			"localField":   "_id",
-			"foreignField": "lessonId",
-			"pipeline": mongo.Pipeline{
-				bson.D{{"$match", bson.M{"studentID": userId}}},
-			},
-			"as": "marks",
-		}}},
-		bson.D{{"$match", bson.M{"group": group, "teacher": teacher, "subject": subject, "studyPlaceId": studyPlaceId}}},
-		bson.D{{"$sort", bson.M{"date": 1}}},
-	})
-	if err != nil {
-		return nil, err
-	}
+// incLessonVersion is an update pipeline stage bumping the lesson version
+var incLessonVersion = bson.M{"$set": bson.M{"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}}}
 
-	var marks []entities.Lesson
-	if err = lessonsCursor.All(ctx, &marks); err != nil {
-		return nil, err
+// versionFilter matches an array element by id and version, version 0 matches only elements
+// written before versions were added, so a stale client can't skip the check
+func versionFilter(array string, id primitive.ObjectID, version int) bson.M {
+	if version == 0 {
+		return bson.M{array: bson.M{"$elemMatch": bson.M{"_id": id, "version": bson.M{"$in": bson.A{nil, 0}}}}}
 	}
 
-	return marks, nil
+	return bson.M{array: bson.M{"$elemMatch": bson.M{"_id": id, "version": version}}}
 }
 
 func (j *repository) AddMarks(ctx context.Context, marks []entities.Mark, teacher string) error {
-	if _, err := j.lessons.UpdateOne(ctx, bson.M{"_id": marks[0].LessonID, "teacher": teacher}, hMongo.PushArray("marks", marks)); err != nil {
+	if _, err := j.lessons.UpdateOne(ctx, bson.M{"_id": marks[0].LessonID, "teacher": teacher}, append(hMongo.PushArray("marks", marks), incLessonVersion)); err != nil {
 		return err
 	}
 
@@ -877,30 +281,52 @@ func (j *repository) AddMarks(ctx context.Context, marks []entities.Mark, teache
 }
 
 func (j *repository) AddMark(ctx context.Context, mark entities.Mark, teacher string) error {
-	_, err := j.lessons.UpdateOne(ctx, bson.M{"_id": mark.LessonID, "teacher": teacher}, hMongo.Push("marks", mark))
+	_, err := j.lessons.UpdateOne(ctx, bson.M{"_id": mark.LessonID, "teacher": teacher}, append(hMongo.Push("marks", mark), incLessonVersion))
 	return err
 }
 
 func (j *repository) UpdateMark(ctx context.Context, mark entities.Mark, teacher string) error {
-	if _, err := j.lessons.UpdateOne(ctx,
-		bson.M{"_id": mark.LessonID, "teacher": teacher, "marks._id": mark.ID},
-		bson.M{"$set": bson.M{
-			"marks.$.lessonID":  mark.LessonID,
-			"marks.$.studentID": mark.StudentID,
-			"marks.$.mark":      mark.Mark,
-		}},
-	); err != nil {
+	filter := versionFilter("marks", mark.ID, mark.Version)
+	filter["_id"] = mark.LessonID
+	filter["teacher"] = teacher
+
+	result, err := j.lessons.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"marks.$.lessonID":          mark.LessonID,
+			"marks.$.studentID":         mark.StudentID,
+			"marks.$.mark":              mark.Mark,
+			"marks.$.comment":           mark.Comment,
+			"marks.$.commentVisibility": mark.CommentVisibility,
+		},
+		"$inc": bson.M{"marks.$.version": 1, "version": 1},
+	})
+	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
 
-func (j *repository) DeleteMarkByID(ctx context.Context, id primitive.ObjectID, teacher string) error {
-	if _, err := j.lessons.UpdateOne(ctx, bson.M{"teacher": teacher, "marks._id": id}, bson.M{"$pull": bson.M{"marks": bson.M{"_id": id}}}); err != nil {
+func (j *repository) DeleteMarkByID(ctx context.Context, id primitive.ObjectID, version int, teacher string) error {
+	filter := versionFilter("marks", id, version)
+	filter["teacher"] = teacher
+
+	result, err := j.lessons.UpdateOne(ctx, filter, bson.M{
+		"$pull": bson.M{"marks": bson.M{"_id": id}},
+		"$inc":  bson.M{"version": 1},
+	})
+	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
 
@@ -948,7 +374,7 @@ func (j *repository) AddAbsences(ctx context.Context, absences []entities.Absenc
 		ids[i] = absence.StudentID
 	}
 
-	if _, err := j.lessons.UpdateOne(ctx, bson.M{"_id": absences[0].LessonID, "teacher": teacher, "absences.$.studentID": bson.M{"$nin": ids}}, hMongo.PushArray("absences", absences)); err != nil {
+	if _, err := j.lessons.UpdateOne(ctx, bson.M{"_id": absences[0].LessonID, "teacher": teacher, "absences.$.studentID": bson.M{"$nin": ids}}, append(hMongo.PushArray("absences", absences), incLessonVersion)); err != nil {
 		return err
 	}
 
@@ -956,67 +382,52 @@ func (j *repository) AddAbsences(ctx context.Context, absences []entities.Absenc
 }
 
 func (j *repository) AddAbsence(ctx context.Context, absence entities.Absence, teacher string) error {
-	_, err := j.lessons.UpdateOne(ctx, bson.M{"_id": absence.LessonID, "teacher": teacher, "absences.studentID": bson.M{"$nin": bson.A{absence.StudentID}}}, hMongo.Push("absences", absence))
+	_, err := j.lessons.UpdateOne(ctx, bson.M{"_id": absence.LessonID, "teacher": teacher, "absences.studentID": bson.M{"$nin": bson.A{absence.StudentID}}}, append(hMongo.Push("absences", absence), incLessonVersion))
 	return err
 }
 
 func (j *repository) UpdateAbsence(ctx context.Context, absence entities.Absence, teacher string) error {
-	if _, err := j.lessons.UpdateOne(ctx, bson.M{"_id": absence.LessonID, "teacher": teacher, "absences._id": absence.ID}, bson.M{"$set": bson.M{"absences.$": absence}}); err != nil {
+	filter := versionFilter("absences", absence.ID, absence.Version)
+	filter["_id"] = absence.LessonID
+	filter["teacher"] = teacher
+
+	result, err := j.lessons.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"absences.$.time":              absence.Time,
+			"absences.$.comment":           absence.Comment,
+			"absences.$.commentVisibility": absence.CommentVisibility,
+			"absences.$.studentID":         absence.StudentID,
+			"absences.$.lessonID":          absence.LessonID,
+			"absences.$.studyPlaceID":      absence.StudyPlaceID,
+		},
+		"$inc": bson.M{"absences.$.version": 1, "version": 1},
+	})
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (j *repository) DeleteAbsenceByID(ctx context.Context, id primitive.ObjectID, teacher string) error {
-	if _, err := j.lessons.UpdateOne(ctx, bson.M{"teacher": teacher, "absences._id": id}, bson.M{"$pull": bson.M{"absences": bson.M{"_id": id}}}); err != nil {
-		return err
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
 	}
 
 	return nil
 }
 
-func (j *repository) GetJournalRowWithDates(ctx context.Context, userID primitive.ObjectID, subject, teacher, group string, studyPlaceId primitive.ObjectID) ([]*entities.Cell, []time.Time, error) {
-	rowCursor, err := j.lessons.Aggregate(ctx, bson.A{
-		bson.M{"$match": bson.M{"group": group, "subject": subject, "teacher": teacher, "studyPlaceId": studyPlaceId}},
-		bson.M{"$addFields": bson.M{
-			"marks": bson.M{"$filter": bson.M{
-				"input": "$marks",
-				"as":    "mark",
-				"cond":  bson.M{"$eq": bson.A{"$$mark.studentID", userID}},
-			}},
-			"absences": bson.M{"$filter": bson.M{
-				"input": "$absences",
-				"as":    "absence",
-				"cond":  bson.M{"$eq": bson.A{"$$absence.studentID", userID}},
-			}},
-		}},
-		bson.M{"$sort": bson.M{"startDate": 1}},
-		bson.M{"$group": bson.M{
-			"_id":   nil,
-			"dates": bson.M{"$push": "$startDate"},
-			"cells": bson.M{"$push": bson.M{
-				"id":       "$_id",
-				"type":     bson.A{"$type"},
-				"marks":    "$marks",
-				"absences": "$absences",
-			}},
-		}},
+func (j *repository) DeleteAbsenceByID(ctx context.Context, id primitive.ObjectID, version int, teacher string) error {
+	filter := versionFilter("absences", id, version)
+	filter["teacher"] = teacher
+
+	result, err := j.lessons.UpdateOne(ctx, filter, bson.M{
+		"$pull": bson.M{"absences": bson.M{"_id": id}},
+		"$inc":  bson.M{"version": 1},
 	})
 	if err != nil {
-		return nil, nil, err
-	}
-	if !rowCursor.Next(ctx) {
-		return nil, nil, mongo.ErrNoDocuments
+		return err
 	}
 
-	var res struct {
-		Cells []*entities.Cell `bson:"cells"`
-		Dates []time.Time      `bson:"dates"`
-	}
-	if err = rowCursor.Decode(&res); err != nil {
-		return nil, nil, err
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
 	}
 
-	return res.Cells, res.Dates, nil
+	return nil
 }
@@ -3,11 +3,13 @@ package controllers
 import (
 	"context"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	apps "studyum/internal/apps/controllers"
 	auth "studyum/internal/auth/entities"
 	"studyum/internal/general/controllers"
 	general "studyum/internal/general/entities"
+	journal "studyum/internal/journal/controllers"
 	journalEntities "studyum/internal/journal/entities"
 	"studyum/internal/schedule/controllers/validators"
 	dto2 "studyum/internal/schedule/dto"
@@ -18,10 +20,12 @@ import (
 )
 
 var NotValidParams = errors.New("not valid params")
+var ErrNoPermission = errors.New("no permission")
 
 type Controller interface {
 	GetSchedule(ctx context.Context, user auth.User, studyPlaceID string, role string, roleName string, startDate, endDate time.Time) (entities.Schedule, error)
 	GetUserSchedule(ctx context.Context, user auth.User, startDate, endDate time.Time) (entities.Schedule, error)
+	GetChildSchedule(ctx context.Context, user auth.User, childID string, startDate, endDate time.Time) (entities.Schedule, error)
 
 	GetGeneralSchedule(ctx context.Context, user auth.User, studyPlaceID string, role string, roleName string, startDate, endDate time.Time) (entities.Schedule, error)
 	GetGeneralUserSchedule(ctx context.Context, user auth.User, startDate, endDate time.Time) (entities.Schedule, error)
@@ -48,13 +52,51 @@ type controller struct {
 	repository repositories.Repository
 
 	generalController controllers.Controller
+	journal           journal.Journal
+	curriculum        Curriculum
 
 	apps      apps.Controller
 	validator validators.Validator
 }
 
-func NewScheduleController(repository repositories.Repository, generalController controllers.Controller, apps apps.Controller, validator validators.Validator) Controller {
-	return &controller{apps: apps, validator: validator, repository: repository, generalController: generalController}
+func NewScheduleController(repository repositories.Repository, generalController controllers.Controller, journal journal.Journal, curriculum Curriculum, apps apps.Controller, validator validators.Validator) Controller {
+	return &controller{apps: apps, validator: validator, repository: repository, generalController: generalController, journal: journal, curriculum: curriculum}
+}
+
+// assignTopics updates curriculum topics of every group subject met in lessons
+func (s *controller) assignTopics(ctx context.Context, lessons ...entities.Lesson) {
+	assigned := make(map[[2]string]bool)
+	for _, lesson := range lessons {
+		key := [2]string{lesson.Group, lesson.Subject}
+		if assigned[key] {
+			continue
+		}
+
+		assigned[key] = true
+		if err := s.curriculum.AssignTopics(ctx, lesson.StudyPlaceId, lesson.Group, lesson.Subject); err != nil {
+			logrus.Warningln("Error assigning curriculum topics: " + err.Error())
+		}
+	}
+}
+
+// authorize checks the user permission covers teachers and groups of every lesson
+func (s *controller) authorize(user auth.User, permission string, resources ...auth.Resource) error {
+	for _, resource := range resources {
+		if !user.Can(permission, resource) {
+			return errors.Wrap(ErrNoPermission, resource.Group+" "+resource.Teacher)
+		}
+	}
+
+	return nil
+}
+
+// authorizeAll checks the user permission is not limited by scopes, it is required for study place wide changes
+func (s *controller) authorizeAll(user auth.User, permission string) error {
+	if !user.HasGlobalPermission(permission) {
+		return ErrNoPermission
+	}
+
+	return nil
 }
 
 func (s *controller) scheduleDated(start, end time.Time) (time.Time, time.Time) {
@@ -94,6 +136,16 @@ func (s *controller) GetUserSchedule(ctx context.Context, user auth.User, startD
 	return s.repository.GetSchedule(ctx, user.StudyPlaceInfo.ID, user.StudyPlaceInfo.Role, user.StudyPlaceInfo.RoleName, startDate, endDate, false, false)
 }
 
+func (s *controller) GetChildSchedule(ctx context.Context, user auth.User, childID string, startDate, endDate time.Time) (entities.Schedule, error) {
+	child, err := s.journal.GetChild(ctx, user, childID)
+	if err != nil {
+		return entities.Schedule{}, err
+	}
+
+	startDate, endDate = s.scheduleDated(startDate, endDate)
+	return s.repository.GetSchedule(ctx, user.StudyPlaceInfo.ID, "group", child.Group, startDate, endDate, false, false)
+}
+
 func (s *controller) GetGeneralSchedule(ctx context.Context, user auth.User, studyPlaceIDHex string, role string, roleName string, startDate, endDate time.Time) (entities.Schedule, error) {
 	if role == "" || roleName == "" {
 		return entities.Schedule{}, NotValidParams
@@ -140,6 +192,10 @@ func (s *controller) AddGeneralLessons(ctx context.Context, user auth.User, less
 			return nil, err
 		}
 
+		if err := s.authorize(user, auth.PermissionEditSchedule, auth.Resource{Teacher: lessonDTO.Teacher, Group: lessonDTO.Group}); err != nil {
+			return nil, err
+		}
+
 		lesson := entities.GeneralLesson{
 			Id:             primitive.NewObjectID(),
 			StudyPlaceId:   user.StudyPlaceInfo.ID,
@@ -172,6 +228,11 @@ func (s *controller) AddLessons(ctx context.Context, user auth.User, lessonsDTO
 			return nil, err
 		}
 
+		// the lessons replace every lesson of the group between the dates
+		if err := s.authorize(user, auth.PermissionEditSchedule, auth.Resource{Group: lessonDTO.Group}); err != nil {
+			return nil, err
+		}
+
 		lesson := entities.Lesson{
 			Id:             primitive.NewObjectID(),
 			StudyPlaceId:   user.StudyPlaceInfo.ID,
@@ -195,14 +256,32 @@ func (s *controller) AddLessons(ctx context.Context, user auth.User, lessonsDTO
 			continue
 		}
 
-		s.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddLesson", lesson)
 		lessons = append(lessons, lesson)
 	}
 
-	if err := s.repository.AddLessons(ctx, lessons); err != nil {
+	err := s.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.AddLessons(ctx, lessons); err != nil {
+			return err
+		}
+
+		for _, lesson := range lessons {
+			if err := s.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddLesson", lesson); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	for _, lesson := range lessons {
+		s.journal.InvalidateGroup(ctx, lesson.StudyPlaceId, lesson.Group)
+	}
+
+	s.assignTopics(ctx, lessons...)
+
 	return lessons, nil
 }
 
@@ -211,6 +290,10 @@ func (s *controller) AddLesson(ctx context.Context, addDTO dto2.AddLessonDTO, us
 		return entities.Lesson{}, err
 	}
 
+	if err := s.authorize(user, auth.PermissionEditSchedule, auth.Resource{Teacher: addDTO.Teacher, Group: addDTO.Group}); err != nil {
+		return entities.Lesson{}, err
+	}
+
 	lesson := entities.Lesson{
 		Id:             primitive.NewObjectID(),
 		StudyPlaceId:   user.StudyPlaceInfo.ID,
@@ -226,11 +309,19 @@ func (s *controller) AddLesson(ctx context.Context, addDTO dto2.AddLessonDTO, us
 		Room:           addDTO.Room,
 	}
 
-	if err := s.repository.AddLesson(ctx, lesson); err != nil {
+	err := s.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.AddLesson(ctx, lesson); err != nil {
+			return err
+		}
+
+		return s.apps.Event(ctx, user.StudyPlaceInfo.ID, "AddLesson", lesson)
+	})
+	if err != nil {
 		return entities.Lesson{}, err
 	}
 
-	s.apps.AsyncEvent(user.StudyPlaceInfo.ID, "AddLesson", lesson)
+	s.journal.InvalidateGroup(ctx, lesson.StudyPlaceId, lesson.Group)
+	s.assignTopics(ctx, lesson)
 
 	return lesson, nil
 }
@@ -258,6 +349,15 @@ func (s *controller) UpdateLesson(ctx context.Context, updateDTO dto2.UpdateLess
 		Description:    updateDTO.Description,
 	}
 
+	oldLesson, err := s.repository.GetLessonByID(ctx, lesson.Id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.authorizeUpdate(user, oldLesson, lesson); err != nil {
+		return err
+	}
+
 	err, studyPlace := s.repository.GetStudyPlaceByID(ctx, user.StudyPlaceInfo.ID, false)
 	if err != nil {
 		return err
@@ -279,17 +379,65 @@ func (s *controller) UpdateLesson(ctx context.Context, updateDTO dto2.UpdateLess
 		marks[len(lessonType.Marks)+i] = markType.Mark
 	}
 
-	if err = s.repository.FilterLessonMarks(ctx, lesson.Id, marks); err != nil {
-		return err
+	// a manually changed title detaches the lesson from the curriculum
+	if oldLesson.Title == lesson.Title && oldLesson.Subject == lesson.Subject && oldLesson.Group == lesson.Group {
+		lesson.TopicID = oldLesson.TopicID
 	}
 
-	err = s.repository.UpdateLesson(ctx, lesson)
+	err = s.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.FilterLessonMarks(ctx, lesson.Id, marks); err != nil {
+			return err
+		}
+
+		if err := s.repository.UpdateLesson(ctx, lesson); err != nil {
+			return err
+		}
 
-	s.apps.AsyncEvent(user.StudyPlaceInfo.ID, "UpdateLesson", lesson)
+		return s.apps.Event(ctx, user.StudyPlaceInfo.ID, "UpdateLesson", lesson)
+	})
+	s.journal.InvalidateGroup(ctx, oldLesson.StudyPlaceId, oldLesson.Group)
+	s.journal.InvalidateGroup(ctx, lesson.StudyPlaceId, lesson.Group)
+	if err == nil {
+		s.assignTopics(ctx, oldLesson, lesson)
+	}
 
 	return err
 }
 
+// authorizeUpdate allows journal editors of the lesson to change its info,
+// moving the lesson or changing its teacher, group or subject needs editSchedule for both lesson versions
+func (s *controller) authorizeUpdate(user auth.User, oldLesson, lesson entities.Lesson) error {
+	if oldLesson.StudyPlaceId != user.StudyPlaceInfo.ID {
+		return ErrNoPermission
+	}
+
+	oldResource := auth.Resource{Teacher: oldLesson.Teacher, Group: oldLesson.Group}
+	newResource := auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}
+
+	if scheduleChanged(oldLesson, lesson) {
+		return s.authorize(user, auth.PermissionEditSchedule, oldResource, newResource)
+	}
+
+	if user.Can(auth.PermissionEditJournal, oldResource) {
+		return nil
+	}
+
+	return s.authorize(user, auth.PermissionEditSchedule, oldResource)
+}
+
+// scheduleChanged reports whether the update touches schedule fields rather than the lesson info
+func scheduleChanged(oldLesson, lesson entities.Lesson) bool {
+	return !oldLesson.StartDate.Equal(lesson.StartDate) ||
+		!oldLesson.EndDate.Equal(lesson.EndDate) ||
+		oldLesson.LessonIndex != lesson.LessonIndex ||
+		oldLesson.Subject != lesson.Subject ||
+		oldLesson.Group != lesson.Group ||
+		oldLesson.Teacher != lesson.Teacher ||
+		oldLesson.Room != lesson.Room ||
+		oldLesson.PrimaryColor != lesson.PrimaryColor ||
+		oldLesson.SecondaryColor != lesson.SecondaryColor
+}
+
 func (s *controller) DeleteLesson(ctx context.Context, idHex string, user auth.User) error {
 	id, err := primitive.ObjectIDFromHex(idHex)
 	if err != nil {
@@ -301,12 +449,23 @@ func (s *controller) DeleteLesson(ctx context.Context, idHex string, user auth.U
 		return err
 	}
 
-	s.apps.Event(user.StudyPlaceInfo.ID, "RemoveLesson", lesson)
+	if err = s.authorize(user, auth.PermissionEditSchedule, auth.Resource{Teacher: lesson.Teacher, Group: lesson.Group}); err != nil {
+		return err
+	}
+
+	err = s.apps.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.apps.Event(ctx, user.StudyPlaceInfo.ID, "RemoveLesson", lesson); err != nil {
+			return err
+		}
 
-	if err = s.repository.DeleteLesson(ctx, id, user.StudyPlaceInfo.ID); err != nil {
+		return s.repository.DeleteLesson(ctx, id, user.StudyPlaceInfo.ID)
+	})
+	if err != nil {
 		return err
 	}
 
+	s.journal.InvalidateGroup(ctx, lesson.StudyPlaceId, lesson.Group)
+	s.assignTopics(ctx, lesson)
 	return nil
 }
 
@@ -317,12 +476,59 @@ func (s *controller) GetLessonsByDateAndID(ctx context.Context, user auth.User,
 	}
 
 	if user.StudyPlaceInfo.Role == "group" {
-		return s.repository.GetFullLessonsByIDAndDate(ctx, user.Id, id)
+		lessons, err := s.repository.GetFullLessonsByIDAndDate(ctx, user.Id, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range lessons {
+			hideTeacherComments(&lessons[i])
+		}
+
+		return lessons, nil
 	}
 
 	return s.repository.GetFullLessonsByIDAndDate(ctx, user.Id, id)
 }
 
+func hideTeacherComments(lesson *entities.Lesson) {
+	for i := range lesson.Marks {
+		if lesson.Marks[i].CommentVisibility == journalEntities.TeacherVisibility {
+			lesson.Marks[i].Comment = ""
+			lesson.Marks[i].CommentVisibility = ""
+		}
+	}
+
+	for i := range lesson.Absences {
+		if lesson.Absences[i].CommentVisibility == journalEntities.TeacherVisibility {
+			lesson.Absences[i].Comment = ""
+			lesson.Absences[i].CommentVisibility = ""
+		}
+	}
+}
+
+// studentLesson leaves only marks and absences of the student and hides teacher comments
+func studentLesson(lesson entities.Lesson, studentID primitive.ObjectID) entities.Lesson {
+	var marks []journalEntities.Mark
+	for _, mark := range lesson.Marks {
+		if mark.StudentID == studentID {
+			marks = append(marks, mark)
+		}
+	}
+	lesson.Marks = marks
+
+	var absences []journalEntities.Absence
+	for _, absence := range lesson.Absences {
+		if absence.StudentID == studentID {
+			absences = append(absences, absence)
+		}
+	}
+	lesson.Absences = absences
+
+	hideTeacherComments(&lesson)
+	return lesson
+}
+
 func (s *controller) GetLessonByID(ctx context.Context, user auth.User, idHex string) (entities.Lesson, error) {
 	id, err := primitive.ObjectIDFromHex(idHex)
 	if err != nil {
@@ -331,19 +537,11 @@ func (s *controller) GetLessonByID(ctx context.Context, user auth.User, idHex st
 
 	if user.StudyPlaceInfo.Role == "group" {
 		lesson, err := s.repository.GetFullLessonByID(ctx, id)
-		var marks []journalEntities.Mark
-		for _, mark := range lesson.Marks {
-			if mark.StudentID == user.Id {
-				marks = append(marks, mark)
-			}
-		}
-		lesson.Marks = marks
-
 		if err != nil {
 			return entities.Lesson{}, err
 		}
 
-		return lesson, nil
+		return studentLesson(lesson, user.Id), nil
 	}
 
 	lesson, err := s.repository.GetLessonByID(ctx, id)
@@ -359,10 +557,23 @@ func (s *controller) RemoveLessonBetweenDates(ctx context.Context, user auth.Use
 		return errors.Wrap(validators.ValidationError, "start time is after end time")
 	}
 
-	return s.repository.RemoveLessonBetweenDates(ctx, date1, date2, user.StudyPlaceInfo.ID)
+	if err := s.authorizeAll(user, auth.PermissionEditSchedule); err != nil {
+		return err
+	}
+
+	if err := s.repository.RemoveLessonBetweenDates(ctx, date1, date2, user.StudyPlaceInfo.ID); err != nil {
+		return err
+	}
+
+	s.journal.InvalidateStudyPlace(ctx, user.StudyPlaceInfo.ID)
+	return nil
 }
 
 func (s *controller) SaveCurrentScheduleAsGeneral(ctx context.Context, user auth.User, role string, roleName string) error {
+	if err := s.authorizeAll(user, auth.PermissionEditSchedule); err != nil {
+		return err
+	}
+
 	startDate, endDate := s.scheduleDated(time.Time{}, time.Time{})
 	schedule, err := s.repository.GetSchedule(ctx, user.StudyPlaceInfo.ID, role, roleName, startDate, endDate, false, false)
 	if err != nil {
@@ -407,6 +618,10 @@ func (s *controller) SaveCurrentScheduleAsGeneral(ctx context.Context, user auth
 }
 
 func (s *controller) SaveGeneralScheduleAsCurrent(ctx context.Context, user auth.User, date time.Time) error {
+	if err := s.authorizeAll(user, auth.PermissionEditSchedule); err != nil {
+		return err
+	}
+
 	startDayDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
 	err, studyPlace := s.generalController.GetStudyPlaceByID(ctx, user.StudyPlaceInfo.ID, false)
@@ -458,5 +673,12 @@ func (s *controller) SaveGeneralScheduleAsCurrent(ctx context.Context, user auth
 	if err = s.repository.RemoveLessonBetweenDates(ctx, startDayDate, startDayDate.AddDate(0, 0, 1), user.StudyPlaceInfo.ID); err != nil {
 		return err
 	}
-	return s.repository.AddLessons(ctx, lessons)
+
+	if err = s.repository.AddLessons(ctx, lessons); err != nil {
+		return err
+	}
+
+	s.journal.InvalidateStudyPlace(ctx, user.StudyPlaceInfo.ID)
+	s.assignTopics(ctx, lessons...)
+	return nil
 }
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	auth "studyum/internal/auth/entities"
+	journal "studyum/internal/journal/entities"
+	"studyum/internal/schedule/entities"
+	"testing"
+)
+
+func TestAuthorizeUpdate(t *testing.T) {
+	s := &controller{}
+	studyPlaceID := primitive.NewObjectID()
+
+	teacher := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{
+		ID:          studyPlaceID,
+		RoleName:    "Ivanov",
+		Permissions: []string{"editJournal:teacher=self"},
+	}}
+	scheduler := auth.User{StudyPlaceInfo: auth.UserStudyPlaceInfo{
+		ID:          studyPlaceID,
+		Permissions: []string{"editSchedule:group=95T"},
+	}}
+
+	lesson := entities.Lesson{StudyPlaceId: studyPlaceID, Group: "95T", Teacher: "Ivanov", Subject: "Math"}
+	homework := lesson
+	homework.Homework = "Ex. 1"
+	moved := lesson
+	moved.Group = "96T"
+	foreign := lesson
+	foreign.Teacher = "Petrov"
+	foreignHomework := foreign
+	foreignHomework.Homework = "Ex. 1"
+
+	assert.Equal(t, s.authorizeUpdate(teacher, lesson, homework), nil)
+	assert.Equal(t, errors.Is(s.authorizeUpdate(teacher, foreign, foreignHomework), ErrNoPermission), true)
+	assert.Equal(t, errors.Is(s.authorizeUpdate(teacher, lesson, moved), ErrNoPermission), true)
+
+	assert.Equal(t, s.authorizeUpdate(scheduler, foreign, foreignHomework), nil)
+	assert.Equal(t, s.authorizeUpdate(scheduler, lesson, foreign), nil)
+	assert.Equal(t, errors.Is(s.authorizeUpdate(scheduler, lesson, moved), ErrNoPermission), true)
+
+	other := lesson
+	other.StudyPlaceId = primitive.NewObjectID()
+	assert.Equal(t, s.authorizeUpdate(scheduler, other, other), ErrNoPermission)
+}
+
+func TestStudentLesson(t *testing.T) {
+	student := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	lesson := entities.Lesson{
+		Marks: []journal.Mark{
+			{Mark: "5", StudentID: student, Comment: "well done", CommentVisibility: journal.StudentVisibility},
+			{Mark: "4", StudentID: student, Comment: "copied", CommentVisibility: journal.TeacherVisibility},
+			{Mark: "3", StudentID: other},
+		},
+		Absences: []journal.Absence{
+			{StudentID: student, Comment: "ill", CommentVisibility: journal.TeacherVisibility},
+			{StudentID: other, Comment: "late"},
+		},
+	}
+
+	lesson = studentLesson(lesson, student)
+	assert.Equal(t, len(lesson.Marks), 2)
+	assert.Equal(t, lesson.Marks[0].Comment, "well done")
+	assert.Equal(t, lesson.Marks[1].Comment, "")
+	assert.Equal(t, len(lesson.Absences), 1)
+	assert.Equal(t, lesson.Absences[0].StudentID, student)
+	assert.Equal(t, lesson.Absences[0].Comment, "")
+}
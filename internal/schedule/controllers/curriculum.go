@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"io"
+	"strconv"
+	"strings"
+	auth "studyum/internal/auth/entities"
+	journal "studyum/internal/journal/controllers"
+	"studyum/internal/schedule/dto"
+	"studyum/internal/schedule/entities"
+	"studyum/internal/schedule/repositories"
+	"time"
+)
+
+type Curriculum interface {
+	GetCurriculum(ctx context.Context, user auth.User, group, subject string) (entities.Curriculum, error)
+	UpdateCurriculum(ctx context.Context, user auth.User, group, subject string, data dto.UpdateCurriculumDTO) (entities.Curriculum, error)
+	ImportCurriculum(ctx context.Context, user auth.User, group, subject string, file io.Reader) (entities.Curriculum, error)
+
+	GetCurriculumReport(ctx context.Context, user auth.User, group, subject string) (entities.CurriculumReport, error)
+
+	AssignTopics(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) error
+}
+
+type curriculum struct {
+	repository repositories.CurriculumRepository
+	journal    journal.Journal
+}
+
+func NewCurriculumController(repository repositories.CurriculumRepository, journal journal.Journal) Curriculum {
+	return &curriculum{repository: repository, journal: journal}
+}
+
+func (c *curriculum) getCurriculum(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) (entities.Curriculum, error) {
+	plan, err := c.repository.GetCurriculum(ctx, studyPlaceID, group, subject)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entities.Curriculum{StudyPlaceID: studyPlaceID, Group: group, Subject: subject, Topics: []entities.Topic{}}, nil
+	}
+
+	return plan, err
+}
+
+func (c *curriculum) GetCurriculum(ctx context.Context, user auth.User, group, subject string) (entities.Curriculum, error) {
+	return c.getCurriculum(ctx, user.StudyPlaceInfo.ID, group, subject)
+}
+
+func (c *curriculum) UpdateCurriculum(ctx context.Context, user auth.User, group, subject string, data dto.UpdateCurriculumDTO) (entities.Curriculum, error) {
+	if !user.Can(auth.PermissionEditSchedule, auth.Resource{Group: group}) {
+		return entities.Curriculum{}, ErrNoPermission
+	}
+
+	topics := make([]entities.Topic, len(data.Topics))
+	for i, topic := range data.Topics {
+		topics[i] = entities.Topic{Title: topic.Title, Hours: topic.Hours, Type: topic.Type}
+	}
+
+	return c.saveCurriculum(ctx, user.StudyPlaceInfo.ID, group, subject, topics)
+}
+
+// ImportCurriculum replaces the curriculum with topics from the first sheet of the xlsx file,
+// columns are title, planned hours and an optional lesson type
+func (c *curriculum) ImportCurriculum(ctx context.Context, user auth.User, group, subject string, file io.Reader) (entities.Curriculum, error) {
+	if !user.Can(auth.PermissionEditSchedule, auth.Resource{Group: group}) {
+		return entities.Curriculum{}, ErrNoPermission
+	}
+
+	f, err := excelize.OpenReader(file)
+	if err != nil {
+		return entities.Curriculum{}, errors.Wrap(NotValidParams, "file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	rows, err := f.GetRows(f.GetSheetList()[0])
+	if err != nil {
+		return entities.Curriculum{}, errors.Wrap(NotValidParams, "file")
+	}
+
+	topics, err := parseCurriculumRows(rows)
+	if err != nil {
+		return entities.Curriculum{}, err
+	}
+
+	return c.saveCurriculum(ctx, user.StudyPlaceInfo.ID, group, subject, topics)
+}
+
+func (c *curriculum) saveCurriculum(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string, topics []entities.Topic) (entities.Curriculum, error) {
+	plan, err := c.getCurriculum(ctx, studyPlaceID, group, subject)
+	if err != nil {
+		return entities.Curriculum{}, err
+	}
+
+	if plan.ID.IsZero() {
+		plan.ID = primitive.NewObjectID()
+	}
+
+	// keep ids of unchanged topics so delivered lessons stay linked to them
+	existing := make(map[string]primitive.ObjectID, len(plan.Topics))
+	for _, topic := range plan.Topics {
+		existing[topic.Title] = topic.ID
+	}
+
+	for i := range topics {
+		if id, ok := existing[topics[i].Title]; ok {
+			topics[i].ID = id
+			delete(existing, topics[i].Title)
+			continue
+		}
+
+		topics[i].ID = primitive.NewObjectID()
+	}
+
+	plan.Topics = topics
+	if err = c.repository.SaveCurriculum(ctx, plan); err != nil {
+		return entities.Curriculum{}, err
+	}
+
+	if err = c.AssignTopics(ctx, studyPlaceID, group, subject); err != nil {
+		return entities.Curriculum{}, err
+	}
+
+	return plan, nil
+}
+
+func (c *curriculum) GetCurriculumReport(ctx context.Context, user auth.User, group, subject string) (entities.CurriculumReport, error) {
+	plan, err := c.getCurriculum(ctx, user.StudyPlaceInfo.ID, group, subject)
+	if err != nil {
+		return entities.CurriculumReport{}, err
+	}
+
+	lessons, err := c.repository.GetSubjectLessons(ctx, user.StudyPlaceInfo.ID, group, subject)
+	if err != nil {
+		return entities.CurriculumReport{}, err
+	}
+
+	return buildCurriculumReport(plan, lessons, time.Now()), nil
+}
+
+// AssignTopics sets curriculum topics as titles of upcoming lessons of the subject
+func (c *curriculum) AssignTopics(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) error {
+	if subject == "" {
+		return nil
+	}
+
+	plan, err := c.repository.GetCurriculum(ctx, studyPlaceID, group, subject)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lessons, err := c.repository.GetSubjectLessons(ctx, studyPlaceID, group, subject)
+	if err != nil {
+		return err
+	}
+
+	changed := planTopics(plan.Topics, lessons, time.Now())
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err = c.repository.SetLessonTopics(ctx, changed); err != nil {
+		return err
+	}
+
+	c.journal.InvalidateGroup(ctx, studyPlaceID, group)
+	return nil
+}
+
+func parseCurriculumRows(rows [][]string) ([]entities.Topic, error) {
+	topics := make([]entities.Topic, 0, len(rows))
+	for i, row := range rows {
+		if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+
+		hours := 1
+		if len(row) > 1 && strings.TrimSpace(row[1]) != "" {
+			value, err := strconv.Atoi(strings.TrimSpace(row[1]))
+			if err != nil || value < 1 {
+				// the first row may be a header
+				if i == 0 {
+					continue
+				}
+
+				return nil, errors.Wrap(NotValidParams, "row "+strconv.Itoa(i+1))
+			}
+
+			hours = value
+		}
+
+		topic := entities.Topic{Title: strings.TrimSpace(row[0]), Hours: hours}
+		if len(row) > 2 {
+			topic.Type = strings.TrimSpace(row[2])
+		}
+
+		topics = append(topics, topic)
+	}
+
+	return topics, nil
+}
+
+// planTopics returns upcoming lessons which topic has changed,
+// every lesson covers one planned hour and lessons with manually typed titles are skipped
+func planTopics(topics []entities.Topic, lessons []entities.Lesson, now time.Time) []entities.Lesson {
+	delivered := make(map[primitive.ObjectID]int)
+	for _, lesson := range lessons {
+		if lesson.StartDate.Before(now) && !lesson.TopicID.IsZero() {
+			delivered[lesson.TopicID]++
+		}
+	}
+
+	slots := make([]entities.Topic, 0, len(lessons))
+	for _, topic := range topics {
+		for i := delivered[topic.ID]; i < topic.Hours; i++ {
+			slots = append(slots, topic)
+		}
+	}
+
+	changed := make([]entities.Lesson, 0)
+	for _, lesson := range lessons {
+		if lesson.StartDate.Before(now) || (lesson.Title != "" && lesson.TopicID.IsZero()) {
+			continue
+		}
+
+		var topic entities.Topic
+		for i, slot := range slots {
+			if slot.Type == "" || slot.Type == lesson.Type {
+				topic = slot
+				slots = append(slots[:i], slots[i+1:]...)
+				break
+			}
+		}
+
+		if topic.ID == lesson.TopicID && topic.Title == lesson.Title {
+			continue
+		}
+
+		lesson.TopicID = topic.ID
+		lesson.Title = topic.Title
+		changed = append(changed, lesson)
+	}
+
+	return changed
+}
+
+func buildCurriculumReport(plan entities.Curriculum, lessons []entities.Lesson, now time.Time) entities.CurriculumReport {
+	report := entities.CurriculumReport{
+		Group:   plan.Group,
+		Subject: plan.Subject,
+		Topics:  make([]entities.TopicReport, len(plan.Topics)),
+	}
+
+	indexes := make(map[primitive.ObjectID]int, len(plan.Topics))
+	for i, topic := range plan.Topics {
+		indexes[topic.ID] = i
+		report.Topics[i] = entities.TopicReport{Topic: topic, Planned: topic.Hours}
+	}
+
+	for _, lesson := range lessons {
+		i, ok := indexes[lesson.TopicID]
+		if !ok {
+			continue
+		}
+
+		if lesson.StartDate.Before(now) {
+			report.Topics[i].Delivered++
+		} else {
+			report.Topics[i].Scheduled++
+		}
+	}
+
+	return report
+}
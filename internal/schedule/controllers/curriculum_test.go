@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"studyum/internal/schedule/entities"
+	"testing"
+	"time"
+)
+
+func TestPlanTopics(t *testing.T) {
+	now := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	intro := entities.Topic{ID: primitive.NewObjectID(), Title: "Intro", Hours: 2}
+	lab := entities.Topic{ID: primitive.NewObjectID(), Title: "Lab", Hours: 1, Type: "Practice"}
+	sets := entities.Topic{ID: primitive.NewObjectID(), Title: "Sets", Hours: 1}
+
+	lessons := []entities.Lesson{
+		{Id: primitive.NewObjectID(), StartDate: now.AddDate(0, 0, -1), Title: "Intro", TopicID: intro.ID},
+		{Id: primitive.NewObjectID(), StartDate: now.AddDate(0, 0, 1), Type: "Practice"},
+		{Id: primitive.NewObjectID(), StartDate: now.AddDate(0, 0, 2), Type: "Lecture", Title: "Manual"},
+		{Id: primitive.NewObjectID(), StartDate: now.AddDate(0, 0, 3), Type: "Lecture"},
+		{Id: primitive.NewObjectID(), StartDate: now.AddDate(0, 0, 4), Type: "Lecture", Title: "Old", TopicID: primitive.NewObjectID()},
+	}
+
+	changed := planTopics([]entities.Topic{intro, lab, sets}, lessons, now)
+
+	assert.Equal(t, len(changed), 3)
+	assert.Equal(t, changed[0].Id, lessons[1].Id)
+	assert.Equal(t, changed[0].Title, "Intro")
+	assert.Equal(t, changed[1].Id, lessons[3].Id)
+	assert.Equal(t, changed[1].Title, "Sets")
+	assert.Equal(t, changed[2].Id, lessons[4].Id)
+	assert.Equal(t, changed[2].Title, "")
+	assert.Equal(t, changed[2].TopicID.IsZero(), true)
+}
+
+func TestBuildCurriculumReport(t *testing.T) {
+	now := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	topic := entities.Topic{ID: primitive.NewObjectID(), Title: "Intro", Hours: 3}
+
+	lessons := []entities.Lesson{
+		{StartDate: now.AddDate(0, 0, -1), TopicID: topic.ID},
+		{StartDate: now.AddDate(0, 0, 1), TopicID: topic.ID},
+		{StartDate: now.AddDate(0, 0, 2)},
+	}
+
+	report := buildCurriculumReport(entities.Curriculum{Topics: []entities.Topic{topic}}, lessons, now)
+
+	assert.Equal(t, report.Topics, []entities.TopicReport{{Topic: topic, Planned: 3, Scheduled: 1, Delivered: 1}})
+}
+
+func TestParseCurriculumRows(t *testing.T) {
+	topics, err := parseCurriculumRows([][]string{{"Topic", "Hours", "Type"}, {"Intro", "2"}, {}, {"Lab", "", "Practice"}})
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, topics, []entities.Topic{{Title: "Intro", Hours: 2}, {Title: "Lab", Hours: 1, Type: "Practice"}})
+
+	_, err = parseCurriculumRows([][]string{{"Intro", "2"}, {"Lab", "x"}})
+	assert.NotEqual(t, err, nil)
+}
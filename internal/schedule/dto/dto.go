@@ -39,3 +39,13 @@ type UpdateLessonDTO struct {
 	Homework    string             `json:"homework"`
 	Description string             `json:"description"`
 }
+
+type TopicDTO struct {
+	Title string `json:"title" binding:"req"`
+	Hours int    `json:"hours" binding:"min=1"`
+	Type  string `json:"type"`
+}
+
+type UpdateCurriculumDTO struct {
+	Topics []TopicDTO `json:"topics" binding:"dive"`
+}
@@ -0,0 +1,31 @@
+package entities
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Curriculum struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
+	Group        string             `json:"group" bson:"group"`
+	Subject      string             `json:"subject" bson:"subject"`
+	Topics       []Topic            `json:"topics" bson:"topics"`
+}
+
+type Topic struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id"`
+	Title string             `json:"title" bson:"title"`
+	Hours int                `json:"hours" bson:"hours"`
+	Type  string             `json:"type" bson:"type"`
+}
+
+type TopicReport struct {
+	Topic     Topic `json:"topic"`
+	Planned   int   `json:"planned"`
+	Scheduled int   `json:"scheduled"`
+	Delivered int   `json:"delivered"`
+}
+
+type CurriculumReport struct {
+	Group   string        `json:"group"`
+	Subject string        `json:"subject"`
+	Topics  []TopicReport `json:"topics"`
+}
@@ -17,6 +17,7 @@ type DeleteLessonID struct {
 
 type Lesson struct {
 	Id               primitive.ObjectID `json:"id" bson:"_id" apps:"trackable,collection=Lessons"`
+	Version          int                `json:"version" bson:"version"`
 	StudyPlaceId     primitive.ObjectID `json:"studyPlaceId" bson:"studyPlaceId"`
 	PrimaryColor     string             `json:"primaryColor" bson:"primaryColor"`
 	JournalCellColor string             `json:"journalCellColor" bson:"journalCellColor"`
@@ -32,6 +33,7 @@ type Lesson struct {
 	Teacher          string             `json:"teacher" bson:"teacher"`
 	Room             string             `json:"room" bson:"room"`
 	Title            string             `json:"title" bson:"title"`
+	TopicID          primitive.ObjectID `json:"topicID" bson:"topicID,omitempty"`
 	Homework         string             `json:"homework" bson:"homework"`
 	Description      string             `json:"description" bson:"description"`
 	IsGeneral        bool               `json:"isGeneral" bson:"isGeneral"`
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	auth "studyum/internal/auth/handlers"
+	"studyum/internal/schedule/controllers"
+	"studyum/internal/schedule/dto"
+)
+
+type Curriculum struct {
+	auth.Middleware
+
+	controller controllers.Curriculum
+
+	Group *gin.RouterGroup
+}
+
+func NewCurriculum(middleware auth.Middleware, controller controllers.Curriculum, group *gin.RouterGroup) *Curriculum {
+	h := &Curriculum{Middleware: middleware, controller: controller, Group: group}
+
+	group.GET(":group/:subject", h.MemberAuth(), h.GetCurriculum)
+	group.GET(":group/:subject/report", h.MemberAuth(), h.GetCurriculumReport)
+	group.PUT(":group/:subject", h.MemberAuth("editSchedule"), h.UpdateCurriculum)
+	group.POST(":group/:subject/import", h.MemberAuth("editSchedule"), h.ImportCurriculum)
+
+	return h
+}
+
+// GetCurriculum godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Router /curriculum/{group}/{subject} [get]
+func (h *Curriculum) GetCurriculum(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	curriculum, err := h.controller.GetCurriculum(ctx, user, ctx.Param("group"), ctx.Param("subject"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, curriculum)
+}
+
+// GetCurriculumReport godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Router /curriculum/{group}/{subject}/report [get]
+func (h *Curriculum) GetCurriculumReport(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	report, err := h.controller.GetCurriculumReport(ctx, user, ctx.Param("group"), ctx.Param("subject"))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// UpdateCurriculum godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Router /curriculum/{group}/{subject} [put]
+func (h *Curriculum) UpdateCurriculum(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	var data dto.UpdateCurriculumDTO
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	curriculum, err := h.controller.UpdateCurriculum(ctx, user, ctx.Param("group"), ctx.Param("subject"), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, curriculum)
+}
+
+// ImportCurriculum godoc
+// @Param group path string true "Group"
+// @Param subject path string true "Subject"
+// @Param file formData file true "Curriculum xlsx"
+// @Router /curriculum/{group}/{subject}/import [post]
+func (h *Curriculum) ImportCurriculum(ctx *gin.Context) {
+	user := h.GetUser(ctx)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	curriculum, err := h.controller.ImportCurriculum(ctx, user, ctx.Param("group"), ctx.Param("subject"), reader)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, curriculum)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"studyum/grpc/schedule/protoschedule"
+	auth "studyum/internal/auth/handlers"
+	journal "studyum/internal/journal/entities"
+	"studyum/internal/schedule/controllers"
+	"studyum/internal/schedule/entities"
+	"time"
+)
+
+// Grpc serves the schedule to applications, calls are authenticated by auth.Middleware GrpcInterceptor
+type Grpc struct {
+	auth.Middleware
+
+	controller controllers.Controller
+}
+
+func NewGrpc(middleware auth.Middleware, controller controllers.Controller, grpcServer *grpc.Server) *Grpc {
+	h := &Grpc{Middleware: middleware, controller: controller}
+
+	protoschedule.RegisterScheduleServer(grpcServer, h)
+
+	h.GrpcMemberAuth("/Schedule/GetSchedule")
+	h.GrpcMemberAuth("/Schedule/GetLesson")
+
+	return h
+}
+
+func (h *Grpc) GetSchedule(ctx context.Context, request *protoschedule.ScheduleRequest) (*protoschedule.ScheduleResponse, error) {
+	user := h.GetGrpcUser(ctx)
+
+	schedule, err := h.controller.GetSchedule(ctx, user, request.StudyPlaceID, request.Type, request.Name, timeOf(request.StartDate), timeOf(request.EndDate))
+	if err != nil {
+		return nil, err
+	}
+
+	lessons := make([]*protoschedule.Lesson, 0, len(schedule.Lessons))
+	for _, lesson := range schedule.Lessons {
+		lessons = append(lessons, lessonToProto(lesson))
+	}
+
+	return &protoschedule.ScheduleResponse{
+		Info: &protoschedule.ScheduleInfo{
+			StudyPlaceID: schedule.Info.StudyPlaceID.Hex(),
+			Role:         schedule.Info.Role,
+			RoleName:     schedule.Info.RoleName,
+			StartDate:    timestamppb.New(schedule.Info.StartDate),
+			EndDate:      timestamppb.New(schedule.Info.EndDate),
+		},
+		Lessons: lessons,
+	}, nil
+}
+
+func (h *Grpc) GetLesson(ctx context.Context, request *protoschedule.LessonRequest) (*protoschedule.Lesson, error) {
+	user := h.GetGrpcUser(ctx)
+
+	lesson, err := h.controller.GetLessonByID(ctx, user, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return lessonToProto(lesson), nil
+}
+
+// timeOf returns the zero time for missing timestamps, as the http handlers do for missing dates
+func timeOf(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+
+	return timestamp.AsTime()
+}
+
+func lessonToProto(lesson entities.Lesson) *protoschedule.Lesson {
+	marks := make([]*protoschedule.LessonMark, 0, len(lesson.Marks))
+	for _, mark := range lesson.Marks {
+		marks = append(marks, markToProto(mark))
+	}
+
+	absences := make([]*protoschedule.LessonAbsence, 0, len(lesson.Absences))
+	for _, absence := range lesson.Absences {
+		absences = append(absences, absenceToProto(absence))
+	}
+
+	var topicID string
+	if !lesson.TopicID.IsZero() {
+		topicID = lesson.TopicID.Hex()
+	}
+
+	return &protoschedule.Lesson{
+		Id:               lesson.Id.Hex(),
+		Version:          int32(lesson.Version),
+		StudyPlaceID:     lesson.StudyPlaceId.Hex(),
+		PrimaryColor:     lesson.PrimaryColor,
+		SecondaryColor:   lesson.SecondaryColor,
+		JournalCellColor: lesson.JournalCellColor,
+		Type:             lesson.Type,
+		StartDate:        timestamppb.New(lesson.StartDate),
+		EndDate:          timestamppb.New(lesson.EndDate),
+		LessonIndex:      int32(lesson.LessonIndex),
+		Subject:          lesson.Subject,
+		Group:            lesson.Group,
+		Teacher:          lesson.Teacher,
+		Room:             lesson.Room,
+		Title:            lesson.Title,
+		TopicID:          topicID,
+		Homework:         lesson.Homework,
+		Description:      lesson.Description,
+		IsGeneral:        lesson.IsGeneral,
+		Marks:            marks,
+		Absences:         absences,
+	}
+}
+
+func markToProto(mark journal.Mark) *protoschedule.LessonMark {
+	return &protoschedule.LessonMark{
+		Id:                mark.ID.Hex(),
+		Mark:              mark.Mark,
+		Comment:           mark.Comment,
+		CommentVisibility: string(mark.CommentVisibility),
+		Version:           int32(mark.Version),
+		StudentID:         mark.StudentID.Hex(),
+	}
+}
+
+func absenceToProto(absence journal.Absence) *protoschedule.LessonAbsence {
+	response := &protoschedule.LessonAbsence{
+		Id:                absence.ID.Hex(),
+		Comment:           absence.Comment,
+		CommentVisibility: string(absence.CommentVisibility),
+		Version:           int32(absence.Version),
+		StudentID:         absence.StudentID.Hex(),
+	}
+
+	if absence.Time != nil {
+		t := int32(*absence.Time)
+		response.Time = &t
+	}
+
+	return response
+}
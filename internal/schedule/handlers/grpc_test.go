@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"studyum/grpc/schedule/protoschedule"
+	authEntities "studyum/internal/auth/entities"
+	auth "studyum/internal/auth/handlers"
+	"studyum/internal/auth/handlers/grpctest"
+	"studyum/internal/schedule/controllers"
+	"studyum/internal/schedule/entities"
+	"testing"
+)
+
+type testController struct {
+	controllers.Controller
+}
+
+func (c testController) GetLessonByID(_ context.Context, _ authEntities.User, _ string) (entities.Lesson, error) {
+	return entities.Lesson{Subject: "Math"}, nil
+}
+
+func TestGrpcAuth(t *testing.T) {
+	users := grpctest.Users{
+		"applicant": {},
+		"student":   {StudyPlaceInfo: authEntities.UserStudyPlaceInfo{Accepted: true}},
+	}
+	conn := grpctest.Serve(t, users, func(server *grpc.Server, middleware auth.Middleware) {
+		NewGrpc(middleware, testController{}, server)
+	})
+	client := protoschedule.NewScheduleClient(conn)
+
+	_, err := client.GetSchedule(context.Background(), &protoschedule.ScheduleRequest{})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	_, err = client.GetLesson(grpctest.Context("applicant"), &protoschedule.LessonRequest{})
+	assert.Equal(t, status.Code(err), codes.PermissionDenied)
+
+	lesson, err := client.GetLesson(grpctest.Context("student"), &protoschedule.LessonRequest{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, lesson.Subject, "Math")
+}
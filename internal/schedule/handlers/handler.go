@@ -12,6 +12,7 @@ import (
 type Handler interface {
 	GetSchedule(ctx *gin.Context)
 	GetUserSchedule(ctx *gin.Context)
+	GetChildSchedule(ctx *gin.Context)
 
 	GetGeneralSchedule(ctx *gin.Context)
 	GetGeneralUserSchedule(ctx *gin.Context)
@@ -44,6 +45,7 @@ func NewScheduleHandler(middleware auth.Middleware, controller controllers.Contr
 
 	group.GET(":type/:name", h.TryAuth(), h.GetSchedule)
 	group.GET("", h.MemberAuth(), h.GetUserSchedule)
+	group.GET("children/:id", h.MemberAuth(), h.GetChildSchedule)
 
 	group.GET("general/:type/:name", h.MemberAuth(), h.GetGeneralSchedule)
 	group.GET("general", h.MemberAuth(), h.GetGeneralUserSchedule)
@@ -53,7 +55,7 @@ func NewScheduleHandler(middleware auth.Middleware, controller controllers.Contr
 	group.GET("lessons/:id", h.MemberAuth(), h.GetLessonByID) //todo change endpoint to :id
 	group.POST("/list", h.MemberAuth("editSchedule"), h.AddLessons)
 	group.POST("", h.MemberAuth("editSchedule"), h.AddLesson)
-	group.PUT("", h.MemberAuth("editJournal"), h.UpdateLesson)
+	group.PUT("", h.MemberAuth(), h.UpdateLesson)
 	group.DELETE(":id", h.MemberAuth("editSchedule"), h.DeleteLesson)
 	group.DELETE("between/:startDate/:endDate", h.MemberAuth("editSchedule"), h.RemoveLessonsBetweenDates)
 
@@ -109,6 +111,26 @@ func (s *handler) GetUserSchedule(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, schedule)
 }
 
+// GetChildSchedule godoc
+// @Param id path string true "Child ID"
+// @Router /children/{id} [get]
+func (s *handler) GetChildSchedule(ctx *gin.Context) {
+	user := s.GetUser(ctx)
+
+	startDateStr := ctx.Query("startDate")
+	startDate, _ := time.Parse(time.RFC3339, startDateStr)
+	endDateStr := ctx.Query("endDate")
+	endDate, _ := time.Parse(time.RFC3339, endDateStr)
+
+	schedule, err := s.controller.GetChildSchedule(ctx, user, ctx.Param("id"), startDate, endDate)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
 // GetGeneralSchedule godoc
 // @Param type path string true "Type"
 // @Param name path string true "RoleName"
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/internal/schedule/entities"
+)
+
+type CurriculumRepository interface {
+	GetCurriculum(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) (entities.Curriculum, error)
+	SaveCurriculum(ctx context.Context, curriculum entities.Curriculum) error
+
+	GetSubjectLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) ([]entities.Lesson, error)
+	SetLessonTopics(ctx context.Context, lessons []entities.Lesson) error
+}
+
+type curriculumRepository struct {
+	curricula *mongo.Collection
+	lessons   *mongo.Collection
+}
+
+func NewCurriculumRepository(curricula *mongo.Collection, lessons *mongo.Collection) CurriculumRepository {
+	return &curriculumRepository{curricula: curricula, lessons: lessons}
+}
+
+func (r *curriculumRepository) GetCurriculum(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) (curriculum entities.Curriculum, err error) {
+	err = r.curricula.FindOne(ctx, bson.M{"studyPlaceID": studyPlaceID, "group": group, "subject": subject}).Decode(&curriculum)
+	return
+}
+
+func (r *curriculumRepository) SaveCurriculum(ctx context.Context, curriculum entities.Curriculum) error {
+	filter := bson.M{"studyPlaceID": curriculum.StudyPlaceID, "group": curriculum.Group, "subject": curriculum.Subject}
+	update := bson.M{"$set": bson.M{"topics": curriculum.Topics}, "$setOnInsert": bson.M{"_id": curriculum.ID}}
+
+	_, err := r.curricula.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *curriculumRepository) GetSubjectLessons(ctx context.Context, studyPlaceID primitive.ObjectID, group, subject string) ([]entities.Lesson, error) {
+	opt := options.Find().
+		SetSort(bson.D{{Key: "startDate", Value: 1}, {Key: "lessonIndex", Value: 1}}).
+		SetProjection(bson.M{"marks": 0, "absences": 0})
+
+	cursor, err := r.lessons.Find(ctx, bson.M{"studyPlaceId": studyPlaceID, "group": group, "subject": subject}, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var lessons []entities.Lesson
+	if err = cursor.All(ctx, &lessons); err != nil {
+		return nil, err
+	}
+
+	return lessons, nil
+}
+
+func (r *curriculumRepository) SetLessonTopics(ctx context.Context, lessons []entities.Lesson) error {
+	if len(lessons) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(lessons))
+	for i, lesson := range lessons {
+		update := bson.M{"$set": bson.M{"title": lesson.Title, "topicID": lesson.TopicID}, "$inc": bson.M{"version": 1}}
+		if lesson.TopicID.IsZero() {
+			update = bson.M{"$set": bson.M{"title": lesson.Title}, "$unset": bson.M{"topicID": ""}, "$inc": bson.M{"version": 1}}
+		}
+
+		models[i] = mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": lesson.Id}).SetUpdate(update)
+	}
+
+	_, err := r.lessons.BulkWrite(ctx, models)
+	return err
+}
@@ -253,9 +253,10 @@ func (s *repository) UpdateLesson(ctx context.Context, lesson entities.Lesson) e
 		"teacher":        lesson.Teacher,
 		"room":           lesson.Room,
 		"title":          lesson.Title,
+		"topicID":        lesson.TopicID,
 		"homework":       lesson.Homework,
 		"description":    lesson.Description,
-	}})
+	}, "$inc": bson.M{"version": 1}})
 	return err
 }
 
@@ -302,7 +303,7 @@ func (s *repository) RemoveGeneralLessonsByType(ctx context.Context, studyPlaceI
 }
 
 func (s *repository) FilterLessonMarks(ctx context.Context, lessonID primitive.ObjectID, marks []string) error {
-	_, err := s.lessons.UpdateByID(ctx, lessonID, bson.M{"$pull": bson.M{"marks": bson.M{"mark": bson.M{"$nin": marks}}}})
+	_, err := s.lessons.UpdateByID(ctx, lessonID, bson.M{"$pull": bson.M{"marks": bson.M{"mark": bson.M{"$nin": marks}}}, "$inc": bson.M{"version": 1}})
 	if err != nil && err.Error() == "write exception: write errors: [Cannot apply $pull to a non-array value]" {
 		return nil
 	}
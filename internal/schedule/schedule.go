@@ -4,9 +4,11 @@ import (
 	"github.com/gin-gonic/gin"
 	v "github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
 	apps "studyum/internal/apps/controllers"
 	auth "studyum/internal/auth/handlers"
 	general "studyum/internal/general/controllers"
+	journal "studyum/internal/journal/controllers"
 	"studyum/internal/schedule/controllers"
 	"studyum/internal/schedule/controllers/validators"
 	"studyum/internal/schedule/handlers"
@@ -17,7 +19,7 @@ import (
 // @BasePath /api/schedule
 
 //go:generate swag init --instanceName schedule -o handlers/swagger -g schedule.go -ot go,yaml
-func New(core *gin.RouterGroup, auth auth.Middleware, apps apps.Controller, general general.Controller, db *mongo.Database) handlers.Handler {
+func New(core *gin.RouterGroup, grpcServer *grpc.Server, auth auth.Middleware, apps apps.Controller, general general.Controller, journal journal.Journal, db *mongo.Database) handlers.Handler {
 	swagger.SwaggerInfoschedule.BasePath = "/api/schedule"
 
 	studyPlaces := db.Collection("StudyPlaces")
@@ -27,8 +29,13 @@ func New(core *gin.RouterGroup, auth auth.Middleware, apps apps.Controller, gene
 	repository := repositories.NewScheduleRepository(studyPlaces, lessons, generalLessons)
 
 	validator := validators.NewSchedule(v.New())
-	controller := controllers.NewScheduleController(repository, general, apps, validator)
+	curriculumRepository := repositories.NewCurriculumRepository(db.Collection("Curricula"), lessons)
+	curriculumController := controllers.NewCurriculumController(curriculumRepository, journal)
+
+	controller := controllers.NewScheduleController(repository, general, journal, curriculumController, apps, validator)
 
 	handler := handlers.NewScheduleHandler(auth, controller, core)
+	handlers.NewCurriculum(auth, curriculumController, core.Group("/curriculum"))
+	handlers.NewGrpc(auth, controller, grpcServer)
 	return handler
 }
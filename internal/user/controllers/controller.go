@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
 	"studyum/internal/auth/controllers"
 	"studyum/internal/auth/entities"
 	codes "studyum/internal/codes/controllers"
@@ -15,6 +16,8 @@ import (
 	"studyum/pkg/encryption"
 	"studyum/pkg/hash"
 	entities3 "studyum/pkg/jwt/entities"
+	"studyum/pkg/mail"
+	"studyum/pkg/ratelimit"
 )
 
 type Controller interface {
@@ -22,6 +25,9 @@ type Controller interface {
 	UpdateUser(ctx context.Context, user entities.User, token, ip string, data dto.Edit) (entities.User, entities3.TokenPair, error)
 
 	CreateCode(ctx context.Context, user entities.User, data dto.CreateCode) (entities2.SignUpCode, error)
+	CreateGuardianCode(ctx context.Context, user entities.User, data dto.CreateGuardianCode) (entities2.SignUpCode, error)
+	LinkChildren(ctx context.Context, user entities.User, data dto.LinkChildren) error
+	GetChildren(ctx context.Context, user entities.User) ([]entities2.AcceptUser, error)
 	PutFirebaseTokenByUserID(ctx context.Context, id primitive.ObjectID, firebaseToken string) error
 
 	GetAccept(ctx context.Context, user entities.User) ([]entities2.AcceptUser, error)
@@ -34,18 +40,24 @@ type Controller interface {
 
 	RecoverPassword(ctx context.Context, email string) error
 	ResetPasswordViaCode(ctx context.Context, resetPassword dto.ResetPassword) error
+
+	RequestEmailChange(ctx context.Context, user entities.User, data dto.ChangeEmail) error
+	ConfirmEmailChange(ctx context.Context, user entities.User, token, ip string, data dto.ConfirmEmailChange) (entities.User, entities3.TokenPair, error)
+	RevertEmailChange(ctx context.Context, data dto.RevertEmailChange) error
 }
 
 type controller struct {
 	repository      repositories.Repository
 	codesController codes.Controller
 	jwt             jwt.JWT
+	limiter         ratelimit.Limiter
+	mail            mail.Mail
 
 	encrypt encryption.Encryption
 }
 
-func NewUserController(repository repositories.Repository, codesController codes.Controller, sessionsController jwt.JWT, encrypt encryption.Encryption) Controller {
-	return &controller{repository: repository, codesController: codesController, jwt: sessionsController, encrypt: encrypt}
+func NewUserController(repository repositories.Repository, codesController codes.Controller, sessionsController jwt.JWT, limiter ratelimit.Limiter, mailer mail.Mail, encrypt encryption.Encryption) Controller {
+	return &controller{repository: repository, codesController: codesController, jwt: sessionsController, limiter: limiter, mail: mailer, encrypt: encrypt}
 }
 
 func (u *controller) GetByID(ctx context.Context, idHex string) (entities.User, error) {
@@ -79,8 +91,6 @@ func (u *controller) UpdateUser(ctx context.Context, user entities.User, token,
 	u.encrypt.Decrypt(&user)
 
 	user.Login = data.Login
-	user.Email = data.Email
-	user.VerifiedEmail = user.Email == data.Email
 	user.PictureUrl = data.Picture
 
 	u.encrypt.Encrypt(&user)
@@ -88,20 +98,6 @@ func (u *controller) UpdateUser(ctx context.Context, user entities.User, token,
 		return entities.User{}, entities3.TokenPair{}, err
 	}
 
-	if user.Email != data.Email {
-		code := codesEntities.Code{
-			Type:     codesEntities.Verification,
-			Email:    user.Email,
-			UserID:   user.Id,
-			Subject:  "Confirmation code",
-			To:       user.Login,
-			Filename: "code.html",
-		}
-		if err := u.codesController.Send(ctx, code); err != nil {
-			return entities.User{}, entities3.TokenPair{}, err
-		}
-	}
-
 	if err := u.jwt.RemoveByToken(ctx, token); err != nil {
 		return entities.User{}, entities3.TokenPair{}, err
 	}
@@ -132,7 +128,9 @@ func (u *controller) CreateCode(ctx context.Context, user entities.User, data dt
 		StudyPlaceID: user.StudyPlaceInfo.ID,
 		Role:         data.Role,
 		RoleName:     data.RoleName,
+		Roles:        data.Roles,
 		Password:     password,
+		Children:     data.Children,
 	}
 
 	u.encrypt.Encrypt(&code)
@@ -144,6 +142,75 @@ func (u *controller) CreateCode(ctx context.Context, user entities.User, data dt
 	return code, nil
 }
 
+// CreateGuardianCode creates an invite code for a guardian of the passed students,
+// curators can invite guardians only for students of their tuition group or the groups of the curator permission
+func (u *controller) CreateGuardianCode(ctx context.Context, user entities.User, data dto.CreateGuardianCode) (entities2.SignUpCode, error) {
+	if len(data.Children) == 0 {
+		return entities2.SignUpCode{}, errors.Wrap(controllers.ValidationError, "children")
+	}
+
+	children, err := u.repository.GetUsersByIDs(ctx, user.StudyPlaceInfo.ID, data.Children)
+	if err != nil {
+		return entities2.SignUpCode{}, err
+	}
+
+	if len(children) != len(data.Children) {
+		return entities2.SignUpCode{}, errors.Wrap(controllers.ValidationError, "children")
+	}
+
+	manager := user.HasGlobalPermission(entities.PermissionManageUsers)
+	for _, child := range children {
+		if child.Role != "group" {
+			return entities2.SignUpCode{}, errors.Wrap(controllers.ValidationError, "children")
+		}
+
+		curator := user.StudyPlaceInfo.TuitionGroup != "" && child.RoleName == user.StudyPlaceInfo.TuitionGroup
+		if !manager && !curator && !user.Can(entities.PermissionCurator, entities.Resource{Group: child.RoleName}) {
+			return entities2.SignUpCode{}, controllers.ForbiddenErr
+		}
+	}
+
+	return u.CreateCode(ctx, user, dto.CreateCode{
+		Code:     data.Code,
+		Name:     data.Name,
+		Role:     entities.GuardianRole,
+		Password: data.Password,
+		Children: data.Children,
+	})
+}
+
+// LinkChildren links students of a guardian invite code to an existing guardian account
+func (u *controller) LinkChildren(ctx context.Context, user entities.User, data dto.LinkChildren) error {
+	code, err := u.repository.GetDataByCode(ctx, data.Code)
+	if err != nil {
+		return err
+	}
+
+	if code.Role != entities.GuardianRole || user.StudyPlaceInfo.Role != entities.GuardianRole || code.StudyPlaceID != user.StudyPlaceInfo.ID {
+		return controllers.ForbiddenErr
+	}
+
+	if err = u.repository.AddChildren(ctx, user.Id, code.Children); err != nil {
+		return err
+	}
+
+	return u.repository.RemoveCodeByID(ctx, code.Id)
+}
+
+func (u *controller) GetChildren(ctx context.Context, user entities.User) ([]entities2.AcceptUser, error) {
+	if user.StudyPlaceInfo.Role != entities.GuardianRole || len(user.Children) == 0 {
+		return []entities2.AcceptUser{}, nil
+	}
+
+	children, err := u.repository.GetUsersByIDs(ctx, user.StudyPlaceInfo.ID, user.Children)
+	if err != nil {
+		return nil, err
+	}
+
+	u.encrypt.Decrypt(&children)
+	return children, nil
+}
+
 func (u *controller) GetAccept(ctx context.Context, user entities.User) ([]entities2.AcceptUser, error) {
 	users, err := u.repository.GetAccept(ctx, user.StudyPlaceInfo.ID)
 	if err != nil {
@@ -176,6 +243,10 @@ func (u *controller) DecryptUser(_ context.Context, user entities.User) entities
 }
 
 func (u *controller) RecoverPassword(ctx context.Context, email string) error {
+	if err := u.limiter.Hit(ctx, "email:"+strings.ToLower(email)); err != nil {
+		return err
+	}
+
 	user, err := u.repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		return err
@@ -194,7 +265,7 @@ func (u *controller) RecoverPassword(ctx context.Context, email string) error {
 }
 
 func (u *controller) ResetPasswordViaCode(ctx context.Context, resetPassword dto.ResetPassword) error {
-	code, err := u.codesController.Receive(ctx, codesEntities.PasswordReset, resetPassword.Code)
+	code, err := u.codesController.Receive(ctx, codesEntities.PasswordReset, resetPassword.Email, resetPassword.Code)
 	if err != nil {
 		return err
 	}
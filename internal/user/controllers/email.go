@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/entities"
+	codes "studyum/internal/codes/controllers"
+	codesEntities "studyum/internal/codes/entities"
+	"studyum/internal/user/dto"
+	entities2 "studyum/internal/user/entities"
+	entities3 "studyum/pkg/jwt/entities"
+	"studyum/pkg/mail"
+	"time"
+)
+
+var ErrEmailTaken = errors.New("email is already taken")
+
+// EmailRevertTime is how long the old address can revert a confirmed email change
+const EmailRevertTime = time.Hour * 24 * 7
+
+// emailFree returns ErrEmailTaken if another user has the email
+func (u *controller) emailFree(ctx context.Context, userID primitive.ObjectID, email string) error {
+	owner, err := u.repository.GetUserByEmail(ctx, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if owner.Id != userID {
+		return ErrEmailTaken
+	}
+
+	return nil
+}
+
+// RequestEmailChange sends a confirmation code to the new address, the email is not changed until it is confirmed
+func (u *controller) RequestEmailChange(ctx context.Context, user entities.User, data dto.ChangeEmail) error {
+	if strings.EqualFold(user.Email, data.Email) {
+		return errors.Wrap(controllers.ValidationError, "email is not changed")
+	}
+
+	if err := u.limiter.Hit(ctx, "email:"+strings.ToLower(data.Email)); err != nil {
+		return err
+	}
+
+	if err := u.emailFree(ctx, user.Id, data.Email); err != nil {
+		return err
+	}
+
+	code := codesEntities.Code{
+		Type:     codesEntities.EmailChange,
+		Email:    data.Email,
+		UserID:   user.Id,
+		Subject:  "Email change",
+		To:       user.Login,
+		Filename: "email-change.html",
+	}
+
+	return u.codesController.Send(ctx, code)
+}
+
+// ConfirmEmailChange applies the change with the code sent to the new address
+// and notifies the old address with a link which reverts the change
+func (u *controller) ConfirmEmailChange(ctx context.Context, user entities.User, token, ip string, data dto.ConfirmEmailChange) (entities.User, entities3.TokenPair, error) {
+	code, err := u.codesController.Receive(ctx, codesEntities.EmailChange, data.Email, data.Code)
+	if err != nil {
+		return entities.User{}, entities3.TokenPair{}, err
+	}
+
+	if code.UserID != user.Id {
+		return entities.User{}, entities3.TokenPair{}, controllers.ForbiddenErr
+	}
+
+	if err = u.emailFree(ctx, user.Id, code.Email); err != nil {
+		return entities.User{}, entities3.TokenPair{}, err
+	}
+
+	if user.Email != "" {
+		if err = u.notifyEmailChange(ctx, user, code.Email); err != nil {
+			return entities.User{}, entities3.TokenPair{}, err
+		}
+	}
+
+	if err = u.repository.SetEmail(ctx, user.Id, code.Email); err != nil {
+		return entities.User{}, entities3.TokenPair{}, err
+	}
+
+	if err = u.jwt.RemoveByToken(ctx, token); err != nil {
+		return entities.User{}, entities3.TokenPair{}, err
+	}
+
+	pair, err := u.jwt.Create(ctx, ip, user.Id.Hex())
+	if err != nil {
+		return entities.User{}, entities3.TokenPair{}, err
+	}
+
+	user.Email = code.Email
+	user.VerifiedEmail = true
+
+	u.encrypt.Decrypt(&user)
+	return user, pair, nil
+}
+
+func (u *controller) notifyEmailChange(ctx context.Context, user entities.User, email string) error {
+	token, err := revertToken()
+	if err != nil {
+		return err
+	}
+
+	change := entities2.EmailChange{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.Id,
+		OldEmail:  user.Email,
+		NewEmail:  email,
+		TokenHash: hashRevertToken(token),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(EmailRevertTime),
+	}
+	if err = u.repository.AddEmailChange(ctx, change); err != nil {
+		return err
+	}
+
+	data := mail.Data{"name": user.Login, "email": email, "token": token, "expire": change.ExpiresAt.Format("01-02-2006 15:04")}
+	return u.mail.SendFile(user.Email, "Your email has been changed", "email-changed.html", data)
+}
+
+// RevertEmailChange restores the old address and removes everything a takeover could have added to sign in with:
+// sessions, the password, TOTP, passkeys, linked identities and api tokens created by the user.
+// The owner regains the account with a password reset code sent to the restored address
+// and enrolls the second factors again. It is allowed without authentication as the account may be taken over
+func (u *controller) RevertEmailChange(ctx context.Context, data dto.RevertEmailChange) error {
+	tokenHash := hashRevertToken(data.Token)
+
+	change, err := u.repository.GetEmailChange(ctx, tokenHash, time.Now())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return controllers.ForbiddenErr
+		}
+		return err
+	}
+
+	// the link is kept if the old address is taken, so it can be used once the conflict is resolved
+	if err = u.emailFree(ctx, change.UserID, change.OldEmail); err != nil {
+		return err
+	}
+
+	if change, err = u.repository.RevertEmailChange(ctx, tokenHash, time.Now()); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return controllers.ForbiddenErr
+		}
+		return err
+	}
+
+	if err = u.repository.SetEmail(ctx, change.UserID, change.OldEmail); err != nil {
+		return err
+	}
+
+	if err = u.repository.ResetCredentials(ctx, change.UserID); err != nil {
+		return err
+	}
+
+	if err = u.repository.DeleteAPITokensByCreator(ctx, change.UserID); err != nil {
+		return err
+	}
+
+	if err = u.jwt.RemoveAllSessions(ctx, change.UserID.Hex()); err != nil {
+		return err
+	}
+
+	user, err := u.repository.GetUserByID(ctx, change.UserID)
+	if err != nil {
+		return err
+	}
+
+	code := codesEntities.Code{
+		Type:     codesEntities.PasswordReset,
+		Email:    change.OldEmail,
+		UserID:   change.UserID,
+		Subject:  "Password recovery",
+		To:       user.Login,
+		Filename: "password-reset.html",
+	}
+
+	// a code requested during the codes timeout can't be sent, the user can request it again later
+	if err = u.codesController.Send(ctx, code); err != nil && !errors.Is(err, codes.ErrForbidden) {
+		return err
+	}
+
+	return nil
+}
+
+func revertToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func hashRevertToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/internal/auth/controllers"
+	"studyum/internal/auth/entities"
+	codes "studyum/internal/codes/controllers"
+	codesEntities "studyum/internal/codes/entities"
+	"studyum/internal/user/dto"
+	entities2 "studyum/internal/user/entities"
+	"studyum/internal/user/repositories"
+	"studyum/internal/utils/jwt"
+	"studyum/pkg/encryption"
+	entities3 "studyum/pkg/jwt/entities"
+	"studyum/pkg/mail"
+	"testing"
+	"time"
+)
+
+type testRepository struct {
+	repositories.Repository
+
+	users     map[primitive.ObjectID]entities.User
+	changes   []entities2.EmailChange
+	apiTokens []entities.APIToken
+}
+
+func (r *testRepository) GetUserByID(_ context.Context, id primitive.ObjectID) (entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return entities.User{}, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (r *testRepository) GetUserByEmail(_ context.Context, email string) (entities.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return entities.User{}, mongo.ErrNoDocuments
+}
+
+func (r *testRepository) SetEmail(_ context.Context, id primitive.ObjectID, email string) error {
+	user := r.users[id]
+	user.Email = email
+	user.VerifiedEmail = true
+	r.users[id] = user
+	return nil
+}
+
+func (r *testRepository) ResetCredentials(_ context.Context, id primitive.ObjectID) error {
+	user := r.users[id]
+	user.Password = ""
+	user.TOTP = entities.TOTP{}
+	user.Passkeys = nil
+	user.Identities = nil
+	r.users[id] = user
+	return nil
+}
+
+func (r *testRepository) DeleteAPITokensByCreator(_ context.Context, userID primitive.ObjectID) error {
+	var tokens []entities.APIToken
+	for _, token := range r.apiTokens {
+		if token.CreatedBy != userID {
+			tokens = append(tokens, token)
+		}
+	}
+	r.apiTokens = tokens
+	return nil
+}
+
+func (r *testRepository) AddEmailChange(_ context.Context, change entities2.EmailChange) error {
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *testRepository) GetEmailChange(_ context.Context, tokenHash string, now time.Time) (entities2.EmailChange, error) {
+	for _, change := range r.changes {
+		if change.TokenHash == tokenHash && change.RevertedAt == nil && change.ExpiresAt.After(now) {
+			return change, nil
+		}
+	}
+	return entities2.EmailChange{}, mongo.ErrNoDocuments
+}
+
+func (r *testRepository) RevertEmailChange(_ context.Context, tokenHash string, revertedAt time.Time) (entities2.EmailChange, error) {
+	for i, change := range r.changes {
+		if change.TokenHash == tokenHash && change.RevertedAt == nil && change.ExpiresAt.After(revertedAt) {
+			r.changes[i].RevertedAt = &revertedAt
+			return change, nil
+		}
+	}
+	return entities2.EmailChange{}, mongo.ErrNoDocuments
+}
+
+type testCodes struct {
+	codes.Controller
+
+	sent []codesEntities.Code
+}
+
+func (c *testCodes) Send(_ context.Context, code codesEntities.Code) error {
+	c.sent = append(c.sent, code)
+	return nil
+}
+
+func (c *testCodes) Receive(_ context.Context, codeType codesEntities.CodeType, email string, rawCode string) (codesEntities.Code, error) {
+	for _, code := range c.sent {
+		if code.Type == codeType && code.Email == email && code.Code == rawCode {
+			return code, nil
+		}
+	}
+	return codesEntities.Code{}, codes.ErrForbidden
+}
+
+type testJWT struct {
+	jwt.JWT
+
+	removed []string
+}
+
+func (j *testJWT) Create(_ context.Context, _ string, userID string) (entities3.TokenPair, error) {
+	return entities3.TokenPair{Access: "access", Refresh: userID}, nil
+}
+
+func (j *testJWT) RemoveByToken(_ context.Context, token string) error {
+	j.removed = append(j.removed, token)
+	return nil
+}
+
+func (j *testJWT) RemoveAllSessions(_ context.Context, userID string) error {
+	j.removed = append(j.removed, userID)
+	return nil
+}
+
+type testMail struct {
+	mail.Mail
+
+	sent []mail.Data
+}
+
+func (m *testMail) SendFile(_, _, _ string, data mail.Data) error {
+	m.sent = append(m.sent, data)
+	return nil
+}
+
+func newTestController(users ...entities.User) (*controller, *testRepository, *testCodes, *testJWT, *testMail) {
+	repository := &testRepository{users: map[primitive.ObjectID]entities.User{}}
+	for _, user := range users {
+		repository.users[user.Id] = user
+	}
+
+	codesController, j, mailer := &testCodes{}, &testJWT{}, &testMail{}
+	c := &controller{repository: repository, codesController: codesController, jwt: j, mail: mailer, encrypt: encryption.NewEncryption("0123456789abcdef")}
+
+	return c, repository, codesController, j, mailer
+}
+
+// changeEmail confirms the change of the user email to the new one and returns the revert token sent to the old address
+func changeEmail(t *testing.T, c *controller, codesController *testCodes, mailer *testMail, user entities.User, email string) string {
+	codesController.sent = append(codesController.sent, codesEntities.Code{Type: codesEntities.EmailChange, Email: email, UserID: user.Id, Code: "CODE12"})
+
+	changed, _, err := c.ConfirmEmailChange(context.Background(), user, "token", "ip", dto.ConfirmEmailChange{Email: email, Code: "CODE12"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, changed.Email, email)
+
+	return mailer.sent[len(mailer.sent)-1]["token"]
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	user := entities.User{Id: primitive.NewObjectID(), Login: "user", Email: "old@example.com", VerifiedEmail: true}
+	c, repository, codesController, j, mailer := newTestController(user)
+
+	_, _, err := c.ConfirmEmailChange(context.Background(), user, "token", "ip", dto.ConfirmEmailChange{Email: "new@example.com", Code: "WRONG1"})
+	assert.Equal(t, err, codes.ErrForbidden)
+
+	changeEmail(t, c, codesController, mailer, user, "new@example.com")
+
+	assert.Equal(t, repository.users[user.Id].Email, "new@example.com")
+	assert.Equal(t, len(repository.changes), 1)
+	assert.Equal(t, repository.changes[0].OldEmail, "old@example.com")
+	assert.Equal(t, mailer.sent[0]["email"], "new@example.com")
+	assert.Equal(t, j.removed, []string{"token"})
+
+	// codes sent to another user can't be used
+	other := entities.User{Id: primitive.NewObjectID(), Email: "other@example.com"}
+	codesController.sent = append(codesController.sent, codesEntities.Code{Type: codesEntities.EmailChange, Email: "taken@example.com", UserID: user.Id, Code: "CODE34"})
+	_, _, err = c.ConfirmEmailChange(context.Background(), other, "token", "ip", dto.ConfirmEmailChange{Email: "taken@example.com", Code: "CODE34"})
+	assert.Equal(t, err, controllers.ForbiddenErr)
+}
+
+func TestRevertEmailChange(t *testing.T) {
+	user := entities.User{Id: primitive.NewObjectID(), Login: "user", Email: "old@example.com", Password: "hash", VerifiedEmail: true}
+	c, repository, codesController, j, mailer := newTestController(user)
+
+	token := changeEmail(t, c, codesController, mailer, user, "new@example.com")
+
+	// credentials added after the takeover
+	taken := repository.users[user.Id]
+	taken.TOTP = entities.TOTP{Enabled: true, Secret: "secret"}
+	taken.Passkeys = []entities.Passkey{{ID: []byte("passkey")}}
+	taken.Identities = []entities.OAuth2Identity{{Provider: "google", Subject: "attacker"}}
+	repository.users[user.Id] = taken
+	other := primitive.NewObjectID()
+	repository.apiTokens = []entities.APIToken{{CreatedBy: user.Id}, {CreatedBy: other}}
+
+	err := c.RevertEmailChange(context.Background(), dto.RevertEmailChange{Token: token})
+	assert.Equal(t, err, nil)
+
+	reverted := repository.users[user.Id]
+	assert.Equal(t, reverted.Email, "old@example.com")
+	assert.Equal(t, reverted.Password, "")
+	assert.Equal(t, reverted.TOTP.Enabled, false)
+	assert.Equal(t, len(reverted.Passkeys), 0)
+	assert.Equal(t, len(reverted.Identities), 0)
+	assert.Equal(t, repository.apiTokens, []entities.APIToken{{CreatedBy: other}})
+	assert.Equal(t, j.removed[len(j.removed)-1], user.Id.Hex())
+
+	reset := codesController.sent[len(codesController.sent)-1]
+	assert.Equal(t, reset.Type, codesEntities.PasswordReset)
+	assert.Equal(t, reset.Email, "old@example.com")
+	assert.Equal(t, reset.UserID, user.Id)
+
+	// the change is reverted once
+	err = c.RevertEmailChange(context.Background(), dto.RevertEmailChange{Token: token})
+	assert.Equal(t, err, controllers.ForbiddenErr)
+}
+
+func TestRevertExpiredEmailChange(t *testing.T) {
+	user := entities.User{Id: primitive.NewObjectID(), Login: "user", Email: "old@example.com", Password: "hash", VerifiedEmail: true}
+	c, repository, codesController, _, mailer := newTestController(user)
+
+	token := changeEmail(t, c, codesController, mailer, user, "new@example.com")
+	repository.changes[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+	err := c.RevertEmailChange(context.Background(), dto.RevertEmailChange{Token: token})
+	assert.Equal(t, err, controllers.ForbiddenErr)
+
+	assert.Equal(t, repository.users[user.Id].Email, "new@example.com")
+	assert.Equal(t, repository.users[user.Id].Password, "hash")
+}
+
+func TestRevertEmailChangeToTakenEmail(t *testing.T) {
+	user := entities.User{Id: primitive.NewObjectID(), Login: "user", Email: "old@example.com", Password: "hash", VerifiedEmail: true}
+	c, repository, codesController, _, mailer := newTestController(user)
+
+	token := changeEmail(t, c, codesController, mailer, user, "new@example.com")
+
+	other := entities.User{Id: primitive.NewObjectID(), Email: "old@example.com"}
+	repository.users[other.Id] = other
+
+	err := c.RevertEmailChange(context.Background(), dto.RevertEmailChange{Token: token})
+	assert.Equal(t, err, ErrEmailTaken)
+	assert.Equal(t, repository.users[user.Id].Password, "hash")
+
+	// the link still works once the old address is free
+	delete(repository.users, other.Id)
+	err = c.RevertEmailChange(context.Background(), dto.RevertEmailChange{Token: token})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, repository.users[user.Id].Email, "old@example.com")
+}
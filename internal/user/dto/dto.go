@@ -1,8 +1,9 @@
 package dto
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type Edit struct {
 	Login    string `json:"login" binding:"req"`
-	Email    string `json:"email" binding:"email"`
 	Picture  string `json:"picture" binding:"req"`
 	Password string `json:"password" binding:"min=8|eq="`
 }
@@ -13,9 +14,37 @@ type CreateCode struct {
 	Role     string `json:"role" binding:"req"`
 	RoleName string `json:"roleName" binding:"req"`
 	Password string `json:"password" binding:"min=8"`
+
+	Roles    []primitive.ObjectID `json:"roles"`
+	Children []primitive.ObjectID `json:"children"`
+}
+
+type CreateGuardianCode struct {
+	Code     string               `json:"code" binding:"req"`
+	Name     string               `json:"name" binding:"req"`
+	Password string               `json:"password" binding:"min=8"`
+	Children []primitive.ObjectID `json:"children" binding:"req"`
+}
+
+type LinkChildren struct {
+	Code string `json:"code" binding:"req"`
 }
 
 type ResetPassword struct {
+	Email       string `json:"email" binding:"req"`
 	Code        string `json:"code" binding:"req"`
 	NewPassword string `json:"password" binding:"min=8"`
 }
+
+type ChangeEmail struct {
+	Email string `json:"email" binding:"email"`
+}
+
+type ConfirmEmailChange struct {
+	Email string `json:"email" binding:"email"`
+	Code  string `json:"code" binding:"req"`
+}
+
+type RevertEmailChange struct {
+	Token string `json:"token" binding:"req"`
+}
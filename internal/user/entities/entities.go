@@ -2,6 +2,7 @@ package entities
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
 type AcceptUser struct {
@@ -12,11 +13,25 @@ type AcceptUser struct {
 }
 
 type SignUpCode struct {
-	Id           primitive.ObjectID `json:"id" bson:"_id"`
-	Code         string             `json:"code" bson:"code"`
-	Name         string             `json:"name" bson:"name" encryption:""`
-	StudyPlaceID primitive.ObjectID `json:"studyPlaceID" bson:"studyPlaceID"`
-	Role         string             `json:"role" bson:"role"`
-	RoleName     string             `json:"roleName" bson:"roleName"`
-	Password     string             `json:"-" bson:"defaultPassword"`
+	Id           primitive.ObjectID   `json:"id" bson:"_id"`
+	Code         string               `json:"code" bson:"code"`
+	Name         string               `json:"name" bson:"name" encryption:""`
+	StudyPlaceID primitive.ObjectID   `json:"studyPlaceID" bson:"studyPlaceID"`
+	Role         string               `json:"role" bson:"role"`
+	RoleName     string               `json:"roleName" bson:"roleName"`
+	Roles        []primitive.ObjectID `json:"roles,omitempty" bson:"roles,omitempty"`
+	Password     string               `json:"-" bson:"defaultPassword"`
+	Children     []primitive.ObjectID `json:"children,omitempty" bson:"children,omitempty"`
+}
+
+// EmailChange records a confirmed email change, the old address can revert it with the token until ExpiresAt
+type EmailChange struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	UserID     primitive.ObjectID `bson:"userID"`
+	OldEmail   string             `bson:"oldEmail"`
+	NewEmail   string             `bson:"newEmail"`
+	TokenHash  string             `bson:"tokenHash"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+	ExpiresAt  time.Time          `bson:"expiresAt"`
+	RevertedAt *time.Time         `bson:"revertedAt"`
 }
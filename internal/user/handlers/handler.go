@@ -21,6 +21,20 @@ type Handler interface {
 
 	ResetPassword(ctx *gin.Context)
 	ResetPasswordViaCode(ctx *gin.Context)
+
+	RequestEmailChange(ctx *gin.Context)
+	ConfirmEmailChange(ctx *gin.Context)
+	RevertEmailChange(ctx *gin.Context)
+
+	CreateGuardianCode(ctx *gin.Context)
+	LinkChildren(ctx *gin.Context)
+	GetChildren(ctx *gin.Context)
+}
+
+// Limits throttle requests sending codes and requests confirming them
+type Limits struct {
+	Request gin.HandlerFunc
+	Code    gin.HandlerFunc
 }
 
 type handler struct {
@@ -31,22 +45,33 @@ type handler struct {
 	Group *gin.RouterGroup
 }
 
-func NewUserHandler(middleware auth.Middleware, controller controllers.Controller, group *gin.RouterGroup) Handler {
+func NewUserHandler(middleware auth.Middleware, controller controllers.Controller, limits Limits, group *gin.RouterGroup) Handler {
 	h := &handler{Middleware: middleware, controller: controller, Group: group}
 
 	group.GET("", h.Auth(), h.GetUser)
-	group.PUT("", h.Auth(), h.UpdateUser)
+	group.PUT("", h.Auth(), h.NotImpersonated(), h.UpdateUser)
 
 	group.GET("accept", h.MemberAuth("manageUsers"), h.GetAccept)
-	group.POST("accept", h.MemberAuth("manageUsers"), h.Accept)
-	group.POST("block", h.MemberAuth("manageUsers"), h.Block)
+	group.POST("accept", h.MemberAuth("manageUsers"), h.NotImpersonated(), h.Accept)
+	group.POST("block", h.MemberAuth("manageUsers"), h.NotImpersonated(), h.Block)
+
+	group.PUT("firebase/token", h.Auth(), h.NotImpersonated(), h.PutFirebaseToken)
+
+	group.POST("password/reset", limits.Request, h.ResetPassword)
+	group.PUT("password/reset", limits.Code, h.ResetPasswordViaCode)
 
-	group.PUT("firebase/token", h.Auth(), h.PutFirebaseToken)
+	group.POST("email", h.Auth(), h.NotImpersonated(), limits.Request, h.RequestEmailChange)
+	group.PUT("email", h.Auth(), h.NotImpersonated(), limits.Code, h.ConfirmEmailChange)
+	group.PUT("email/revert", limits.Code, h.RevertEmailChange)
 
-	group.POST("password/reset", h.ResetPassword)
-	group.PUT("password/reset", h.ResetPasswordViaCode)
+	group.POST("code", h.MemberAuth("manageUsers"), h.NotImpersonated(), h.CreateCode)
 
-	group.POST("code", h.MemberAuth("manageUsers"), h.CreateCode)
+	guardian := group.Group("/guardian")
+	{
+		guardian.POST("code", h.MemberAuth(), h.NotImpersonated(), h.CreateGuardianCode)
+		guardian.POST("children", h.Auth(), h.NotImpersonated(), h.LinkChildren)
+		guardian.GET("children", h.MemberAuth(), h.GetChildren)
+	}
 
 	return h
 }
@@ -184,6 +209,69 @@ func (h *handler) ResetPassword(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// RequestEmailChange godoc
+// @Param data body dto.ChangeEmail true "New email"
+// @Router /email [post]
+func (h *handler) RequestEmailChange(ctx *gin.Context) {
+	user := h.Middleware.GetUser(ctx)
+
+	var data dto.ChangeEmail
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.controller.RequestEmailChange(ctx, user, data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ConfirmEmailChange godoc
+// @Param data body dto.ConfirmEmailChange true "Code"
+// @Router /email [put]
+func (h *handler) ConfirmEmailChange(ctx *gin.Context) {
+	user := h.Middleware.GetUser(ctx)
+
+	var data dto.ConfirmEmailChange
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	token, _ := ctx.Cookie("refresh")
+	user, pair, err := h.controller.ConfirmEmailChange(ctx, user, token, ctx.ClientIP(), data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.SetTokenPairCookie(ctx, pair)
+	h.SetTokenPairHeader(ctx, pair)
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// RevertEmailChange godoc
+// @Param data body dto.RevertEmailChange true "Token"
+// @Router /email/revert [put]
+func (h *handler) RevertEmailChange(ctx *gin.Context) {
+	var data dto.RevertEmailChange
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.controller.RevertEmailChange(ctx, data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 // ResetPasswordViaCode godoc
 // @Router /password/reset [put]
 func (h *handler) ResetPasswordViaCode(ctx *gin.Context) {
@@ -221,3 +309,56 @@ func (h *handler) CreateCode(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusCreated, code)
 }
+
+// CreateGuardianCode godoc
+// @Router /guardian/code [post]
+func (h *handler) CreateGuardianCode(ctx *gin.Context) {
+	user := h.Middleware.GetUser(ctx)
+
+	var data dto.CreateGuardianCode
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	code, err := h.controller.CreateGuardianCode(ctx, user, data)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, code)
+}
+
+// LinkChildren godoc
+// @Router /guardian/children [post]
+func (h *handler) LinkChildren(ctx *gin.Context) {
+	user := h.Middleware.GetUser(ctx)
+
+	var data dto.LinkChildren
+	if err := ctx.BindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.controller.LinkChildren(ctx, user, data); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetChildren godoc
+// @Router /guardian/children [get]
+func (h *handler) GetChildren(ctx *gin.Context) {
+	user := h.Middleware.GetUser(ctx)
+
+	children, err := h.controller.GetChildren(ctx, user)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, children)
+}
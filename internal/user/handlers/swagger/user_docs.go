@@ -36,11 +36,75 @@ const docTemplateuser = `{
                 "responses": {}
             }
         },
+        "/code": {
+            "post": {
+                "responses": {}
+            }
+        },
+        "/email": {
+            "put": {
+                "parameters": [
+                    {
+                        "description": "Code",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConfirmEmailChange"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "parameters": [
+                    {
+                        "description": "New email",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangeEmail"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/email/revert": {
+            "put": {
+                "parameters": [
+                    {
+                        "description": "Token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RevertEmailChange"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/firebase/token": {
             "put": {
                 "responses": {}
             }
         },
+        "/guardian/children": {
+            "get": {
+                "responses": {}
+            },
+            "post": {
+                "responses": {}
+            }
+        },
+        "/guardian/code": {
+            "post": {
+                "responses": {}
+            }
+        },
         "/password/reset": {
             "put": {
                 "responses": {}
@@ -49,6 +113,35 @@ const docTemplateuser = `{
                 "responses": {}
             }
         }
+    },
+    "definitions": {
+        "dto.ChangeEmail": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConfirmEmailChange": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RevertEmailChange": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        }
     }
 }`
 
@@ -56,7 +149,7 @@ const docTemplateuser = `{
 var SwaggerInfouser = &swag.Spec{
 	Version:          "",
 	Host:             "",
-	BasePath:         "/api/schedule",
+	BasePath:         "/api/user",
 	Schemes:          []string{},
 	Title:            "",
 	Description:      "",
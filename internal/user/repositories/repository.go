@@ -2,11 +2,14 @@ package repositories
 
 import (
 	"context"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"studyum/internal/auth/entities"
 	entities2 "studyum/internal/user/entities"
+	"time"
 )
 
 type Repository interface {
@@ -27,16 +30,41 @@ type Repository interface {
 
 	GetUserByEmail(ctx context.Context, email string) (entities.User, error)
 
+	GetUsersByIDs(ctx context.Context, studyPlaceID primitive.ObjectID, ids []primitive.ObjectID) ([]entities2.AcceptUser, error)
+	AddChildren(ctx context.Context, userID primitive.ObjectID, children []primitive.ObjectID) error
+
 	SetPasswordByUserID(ctx context.Context, id primitive.ObjectID, password string) error
+	ResetCredentials(ctx context.Context, id primitive.ObjectID) error
+	DeleteAPITokensByCreator(ctx context.Context, userID primitive.ObjectID) error
+
+	SetEmail(ctx context.Context, id primitive.ObjectID, email string) error
+	AddEmailChange(ctx context.Context, change entities2.EmailChange) error
+	GetEmailChange(ctx context.Context, tokenHash string, now time.Time) (entities2.EmailChange, error)
+	RevertEmailChange(ctx context.Context, tokenHash string, revertedAt time.Time) (entities2.EmailChange, error)
 }
 
 type repository struct {
-	users       *mongo.Collection
-	signupCodes *mongo.Collection
+	users        *mongo.Collection
+	signupCodes  *mongo.Collection
+	emailChanges *mongo.Collection
+	apiTokens    *mongo.Collection
 }
 
-func NewUserRepository(users *mongo.Collection, signupCodes *mongo.Collection) Repository {
-	return &repository{users: users, signupCodes: signupCodes}
+func NewUserRepository(users *mongo.Collection, signupCodes *mongo.Collection, emailChanges *mongo.Collection, apiTokens *mongo.Collection) Repository {
+	r := &repository{users: users, signupCodes: signupCodes, emailChanges: emailChanges, apiTokens: apiTokens}
+	r.createIndexes(context.Background())
+
+	return r
+}
+
+func (u *repository) createIndexes(ctx context.Context) {
+	_, err := u.emailChanges.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating email changes indexes: " + err.Error())
+	}
 }
 
 func (u *repository) GetUserByID(ctx context.Context, id primitive.ObjectID) (user entities.User, err error) {
@@ -44,8 +72,13 @@ func (u *repository) GetUserByID(ctx context.Context, id primitive.ObjectID) (us
 	return
 }
 
+// UpdateUserByID updates the profile fields only, study place info of the context user holds resolved permissions
 func (u *repository) UpdateUserByID(ctx context.Context, user entities.User) error {
-	_, err := u.users.UpdateByID(ctx, user.Id, bson.M{"$set": user})
+	_, err := u.users.UpdateByID(ctx, user.Id, bson.M{"$set": bson.M{
+		"password": user.Password,
+		"login":    user.Login,
+		"picture":  user.PictureUrl,
+	}})
 	return err
 }
 
@@ -98,3 +131,56 @@ func (u *repository) SetPasswordByUserID(ctx context.Context, id primitive.Objec
 	_, err := u.users.UpdateByID(ctx, id, bson.M{"$set": bson.M{"password": password}})
 	return err
 }
+
+// ResetCredentials removes every way to sign in except the email: the password, TOTP, passkeys and linked identities
+func (u *repository) ResetCredentials(ctx context.Context, id primitive.ObjectID) error {
+	_, err := u.users.UpdateByID(ctx, id, bson.M{
+		"$set":   bson.M{"password": "", "totp": bson.M{"enabled": false}},
+		"$unset": bson.M{"passkeys": "", "identities": ""},
+	})
+	return err
+}
+
+func (u *repository) DeleteAPITokensByCreator(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := u.apiTokens.DeleteMany(ctx, bson.M{"createdBy": userID})
+	return err
+}
+
+func (u *repository) GetUsersByIDs(ctx context.Context, studyPlaceID primitive.ObjectID, ids []primitive.ObjectID) (users []entities2.AcceptUser, err error) {
+	cursor, err := u.users.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "studyPlaceID": studyPlaceID})
+	if err != nil {
+		return
+	}
+
+	err = cursor.All(ctx, &users)
+	return
+}
+
+func (u *repository) AddChildren(ctx context.Context, userID primitive.ObjectID, children []primitive.ObjectID) error {
+	_, err := u.users.UpdateByID(ctx, userID, bson.M{"$addToSet": bson.M{"children": bson.M{"$each": children}}})
+	return err
+}
+
+// SetEmail sets the confirmed email of the user
+func (u *repository) SetEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	_, err := u.users.UpdateByID(ctx, id, bson.M{"$set": bson.M{"email": email, "verifiedEmail": true}})
+	return err
+}
+
+func (u *repository) AddEmailChange(ctx context.Context, change entities2.EmailChange) error {
+	_, err := u.emailChanges.InsertOne(ctx, change)
+	return err
+}
+
+// GetEmailChange returns the change if it can still be reverted
+func (u *repository) GetEmailChange(ctx context.Context, tokenHash string, now time.Time) (change entities2.EmailChange, err error) {
+	err = u.emailChanges.FindOne(ctx, bson.M{"tokenHash": tokenHash, "revertedAt": nil, "expiresAt": bson.M{"$gt": now}}).Decode(&change)
+	return
+}
+
+// RevertEmailChange marks the change as reverted, it fails if the change has expired or has been reverted already
+func (u *repository) RevertEmailChange(ctx context.Context, tokenHash string, revertedAt time.Time) (change entities2.EmailChange, err error) {
+	filter := bson.M{"tokenHash": tokenHash, "revertedAt": nil, "expiresAt": bson.M{"$gt": revertedAt}}
+	err = u.emailChanges.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"revertedAt": revertedAt}}).Decode(&change)
+	return
+}
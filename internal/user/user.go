@@ -2,6 +2,7 @@ package user
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	auth "studyum/internal/auth/handlers"
 	codes "studyum/internal/codes/controllers"
@@ -10,22 +11,36 @@ import (
 	"studyum/internal/user/handlers/swagger"
 	"studyum/internal/user/repositories"
 	"studyum/internal/utils/jwt"
+	"studyum/internal/utils/middlewares"
 	"studyum/pkg/encryption"
+	"studyum/pkg/mail"
+	"studyum/pkg/ratelimit"
+	"time"
 )
 
 // @BasePath /api/user
 
 //go:generate swag init --instanceName user -o handlers/swagger -g user.go -ot go,yaml
-func New(core *gin.RouterGroup, auth auth.Middleware, encrypt encryption.Encryption, codesController codes.Controller, sessionsController jwt.JWT, db *mongo.Database) (handlers.Handler, controllers.Controller) {
+func New(core *gin.RouterGroup, auth auth.Middleware, encrypt encryption.Encryption, codesController codes.Controller, sessionsController jwt.JWT, mailer mail.Mail, db *mongo.Database, redisClient *redis.Client) (handlers.Handler, controllers.Controller) {
 	swagger.SwaggerInfouser.BasePath = "/api/user"
 
 	users := db.Collection("Users")
 	signUpCodes := db.Collection("SignUpCodes")
+	emailChanges := db.Collection("EmailChanges")
+	apiTokens := db.Collection("APITokens")
 
-	repository := repositories.NewUserRepository(users, signUpCodes)
+	repository := repositories.NewUserRepository(users, signUpCodes, emailChanges, apiTokens)
 
-	controller := controllers.NewUserController(repository, codesController, sessionsController, encrypt)
+	emailLimiter := ratelimit.NewRedis(redisClient, "password-reset", ratelimit.Options{Attempts: 5, Window: time.Hour, Lockout: time.Hour, MaxLockout: time.Hour * 24})
+	requestLimiter := ratelimit.NewRedis(redisClient, "password-reset-ip", ratelimit.Options{Attempts: 10, Window: time.Hour, Lockout: time.Minute * 15, MaxLockout: time.Hour * 24})
+	codeLimiter := ratelimit.NewRedis(redisClient, "password-reset-code-ip", ratelimit.Options{Attempts: 20, Window: time.Minute * 15, Lockout: time.Minute, MaxLockout: time.Hour})
 
-	handler := handlers.NewUserHandler(auth, controller, core)
+	controller := controllers.NewUserController(repository, codesController, sessionsController, emailLimiter, mailer, encrypt)
+
+	limits := handlers.Limits{
+		Request: middlewares.RateLimitMiddleware(requestLimiter, false),
+		Code:    middlewares.RateLimitMiddleware(codeLimiter, true),
+	}
+	handler := handlers.NewUserHandler(auth, controller, limits, core)
 	return handler, controller
 }
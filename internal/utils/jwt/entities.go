@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	auth "studyum/internal/auth/entities"
 	jwt "studyum/pkg/jwt/controllers"
 	"studyum/pkg/jwt/entities"
 )
@@ -15,6 +16,13 @@ type Claims struct {
 	Email          string               `json:"email"`
 	VerifiedEmail  bool                 `json:"verifiedEmail"`
 	StudyPlaceInfo ClaimsStudyPlaceInfo `json:"studyPlaceInfo"`
+	ActorID        string               `json:"actorID,omitempty"`
+}
+
+// WithActor marks the claims as issued to the actor impersonating the user
+func (c Claims) WithActor(actorID string) entities.IIDClaims {
+	c.ActorID = actorID
+	return c
 }
 
 type ClaimsStudyPlaceInfo struct {
@@ -26,3 +34,24 @@ type ClaimsStudyPlaceInfo struct {
 	Permissions  []string `json:"permissions"`
 	Accepted     bool     `json:"accepted"`
 }
+
+// NewClaims builds claims of the session with the id for the decrypted user
+func NewClaims(id string, user auth.User) Claims {
+	return Claims{
+		IDClaims:      entities.IDClaims{ID: id},
+		UserID:        user.Id.Hex(),
+		Login:         user.Login,
+		PictureURL:    user.PictureUrl,
+		Email:         user.Email,
+		VerifiedEmail: user.VerifiedEmail,
+		StudyPlaceInfo: ClaimsStudyPlaceInfo{
+			Id:           user.StudyPlaceInfo.ID.Hex(),
+			Name:         user.StudyPlaceInfo.Name,
+			Role:         user.StudyPlaceInfo.Role,
+			RoleName:     user.StudyPlaceInfo.RoleName,
+			TuitionGroup: user.StudyPlaceInfo.TuitionGroup,
+			Permissions:  user.StudyPlaceInfo.Permissions,
+			Accepted:     user.StudyPlaceInfo.Accepted,
+		},
+	}
+}
@@ -6,13 +6,19 @@ import (
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"strconv"
+	apps "studyum/internal/apps/controllers"
 	auth "studyum/internal/auth/controllers"
+	codes "studyum/internal/codes/controllers"
 	"studyum/internal/journal/controllers"
 	controllers2 "studyum/internal/schedule/controllers"
 	"studyum/internal/schedule/controllers/validators"
+	user "studyum/internal/user/controllers"
 	"studyum/pkg/datetime"
 	controllers3 "studyum/pkg/jwt/controllers"
 	"studyum/pkg/jwt/repositories"
+	"studyum/pkg/ratelimit"
+	"studyum/pkg/webauthn"
 )
 
 func ErrorMiddleware() gin.HandlerFunc {
@@ -23,6 +29,12 @@ func ErrorMiddleware() gin.HandlerFunc {
 		}
 
 		code := GetHttpCodeByError(ctx.Errors[0])
+
+		var limitErr ratelimit.Error
+		if errors.As(ctx.Errors[0].Err, &limitErr) {
+			ctx.Header("Retry-After", strconv.Itoa(limitErr.Seconds()))
+		}
+
 		ctx.JSON(code, ctx.Errors[0].Error())
 	}
 }
@@ -46,22 +58,58 @@ func GetHttpCodeByError(err error) int {
 		errors.Is(err, mongo.ErrNilCursor),
 		errors.Is(err, auth.ValidationError),
 		errors.Is(err, auth.ErrExpired),
+		errors.Is(err, auth.ErrLastIdentity),
+		errors.Is(err, webauthn.ErrUnsupportedKey),
 		errors.Is(err, datetime.DurationError),
 		errors.Is(err, controllers.NotValidParams),
+		errors.Is(err, controllers.ErrCheckInClosed),
 		errors.Is(err, controllers2.NotValidParams),
+		errors.Is(err, apps.NotValidParams),
+		errors.Is(err, apps.ErrNotValidResumeToken),
 		errors.Is(err, validators.ValidationError):
 		code = http.StatusUnprocessableEntity
+	case
+		errors.Is(err, auth.ErrOIDCInvalidRequest),
+		errors.Is(err, auth.ErrOIDCInvalidScope),
+		errors.Is(err, auth.ErrOIDCUnsupportedResponse):
+		code = http.StatusBadRequest
 	case
 		errors.Is(err, controllers3.ValidationErr),
+		errors.Is(err, auth.ErrTwoFactorCode),
 		errors.Is(err, j.ErrSignatureInvalid),
 		errors.Is(err, controllers3.RefreshTokenErr),
+		errors.Is(err, controllers3.ReusedTokenErr),
+		errors.Is(err, auth.ErrNotValidAPIToken),
+		errors.Is(err, auth.ErrNotValidPasskey),
+		errors.Is(err, webauthn.ErrNotValidResponse),
+		errors.Is(err, webauthn.ErrSignCount),
 		errors.Is(err, http.ErrNoCookie),
 		errors.Is(err, repositories.NotValidRefreshTokenErr):
 		code = http.StatusUnauthorized
 	case
 		errors.Is(err, auth.ForbiddenErr),
-		errors.Is(err, controllers.ErrNoPermission):
+		errors.Is(err, auth.ErrTwoFactorRequired),
+		errors.Is(err, auth.ErrImpersonationForbidden),
+		errors.Is(err, auth.ErrImpersonating),
+		errors.Is(err, codes.ErrForbidden),
+		errors.Is(err, auth.ErrOIDCAccessDenied),
+		errors.Is(err, auth.ErrLinkConfirmationRequired),
+		errors.Is(err, controllers.ErrNoPermission),
+		errors.Is(err, controllers2.ErrNoPermission):
 		code = http.StatusForbidden
+	case
+		errors.Is(err, ratelimit.ErrTooManyRequests):
+		code = http.StatusTooManyRequests
+	case
+		errors.Is(err, controllers3.NotFoundErr):
+		code = http.StatusNotFound
+	case
+		errors.Is(err, controllers.ErrConflict),
+		errors.Is(err, auth.ErrIdentityTaken),
+		errors.Is(err, auth.ErrRoleInUse),
+		errors.Is(err, auth.ErrIdentityEmailTaken),
+		errors.Is(err, user.ErrEmailTaken):
+		code = http.StatusConflict
 	default:
 		code = http.StatusInternalServerError
 	}
@@ -0,0 +1,154 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// GrpcUnaryInterceptors logs calls, recovers panics and maps errors to status codes, auth is expected to be chained after them
+func GrpcUnaryInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{grpcUnaryLogger, grpcUnaryRecovery, grpcUnaryErrors}
+}
+
+// GrpcStreamInterceptors are GrpcUnaryInterceptors for streaming calls
+func GrpcStreamInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{grpcStreamLogger, grpcStreamRecovery, grpcStreamErrors}
+}
+
+func grpcUnaryLogger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	requestID := grpcRequestID(ctx)
+	start := time.Now()
+
+	response, err := handler(ctx, req)
+	logGrpc(requestID, info.FullMethod, start, err)
+
+	return response, err
+}
+
+func grpcStreamLogger(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	requestID := grpcRequestID(stream.Context())
+	start := time.Now()
+
+	err := handler(srv, stream)
+	logGrpc(requestID, info.FullMethod, start, err)
+
+	return err
+}
+
+func grpcUnaryRecovery(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	defer grpcRecovery(info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func grpcStreamRecovery(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer grpcRecovery(info.FullMethod, &err)
+	return handler(srv, stream)
+}
+
+func grpcUnaryErrors(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	response, err := handler(ctx, req)
+	return response, GrpcError(err)
+}
+
+func grpcStreamErrors(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return GrpcError(handler(srv, stream))
+}
+
+// GrpcError converts domain errors to status errors with the code of GetGrpcCodeByError
+func GrpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return status.Error(GetGrpcCodeByError(err), err.Error())
+}
+
+// GetGrpcCodeByError uses the same mapping as GetHttpCodeByError
+func GetGrpcCodeByError(err error) codes.Code {
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	switch GetHttpCodeByError(err) {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// grpcRequestID takes the x-request-id metadata of the caller or generates a new one and sends it back in the header
+func grpcRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var requestID string
+	if values := md.Get("x-request-id"); len(values) != 0 && values[0] != "" {
+		requestID = values[0]
+	} else {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		requestID = hex.EncodeToString(id)
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+	return requestID
+}
+
+func grpcRecovery(method string, err *error) {
+	if r := recover(); r != nil {
+		logrus.WithField("method", method).Errorf("Panic recieved: %v\n%s", r, debug.Stack())
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+func logGrpc(requestID string, method string, start time.Time, err error) {
+	code := status.Code(err)
+	entry := logrus.WithFields(logrus.Fields{
+		"requestID": requestID,
+		"method":    method,
+		"code":      code.String(),
+		"duration":  time.Since(start),
+	})
+
+	switch code {
+	case codes.OK:
+		entry.Info("grpc call")
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+		entry.WithError(err).Error("grpc call")
+	default:
+		entry.WithError(err).Warn("grpc call")
+	}
+}
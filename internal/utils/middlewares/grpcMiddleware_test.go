@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	auth "studyum/internal/auth/controllers"
+	"studyum/internal/journal/controllers"
+	"testing"
+)
+
+func TestGrpcUnaryInterceptors(t *testing.T) {
+	call := func(handler grpc.UnaryHandler) error {
+		interceptors := GrpcUnaryInterceptors()
+		info := &grpc.UnaryServerInfo{FullMethod: "/Auth/AuthUser"}
+
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+
+		_, err := chained(context.Background(), nil)
+		return err
+	}
+
+	err := call(func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("auth user")
+	})
+	assert.Equal(t, status.Code(err), codes.Internal)
+
+	err = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, auth.ForbiddenErr
+	})
+	assert.Equal(t, status.Code(err), codes.PermissionDenied)
+
+	err = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.Wrap(controllers.ErrConflict, "mark")
+	})
+	assert.Equal(t, status.Code(err), codes.Aborted)
+
+	err = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.OutOfRange, "resume token expired")
+	})
+	assert.Equal(t, status.Code(err), codes.OutOfRange)
+
+	err = call(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, err, nil)
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"studyum/pkg/ratelimit"
+)
+
+// RateLimitMiddleware throttles requests per client ip,
+// with failuresOnly set only requests ended with an error are counted
+func RateLimitMiddleware(limiter ratelimit.Limiter, failuresOnly bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := "ip:" + ctx.ClientIP()
+		if err := limiter.Check(ctx, key); err != nil {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+
+		if failuresOnly && len(ctx.Errors) == 0 && ctx.Writer.Status() < http.StatusBadRequest {
+			return
+		}
+
+		if err := limiter.Hit(ctx, key); err != nil && !errors.Is(err, ratelimit.ErrTooManyRequests) {
+			logrus.Warningln("Error counting request for rate limit: " + err.Error())
+		}
+	}
+}
@@ -22,11 +22,5 @@ func GetViaCtx[G any](ctx *gin.Context, name string) G {
 }
 
 func HasPermission(user auth.User, permission string) bool {
-	for _, permission_ := range user.StudyPlaceInfo.Permissions {
-		if permission_ == permission || permission_ == "admin" {
-			return true
-		}
-	}
-
-	return false
+	return user.HasPermission(permission) || user.HasPermission("admin")
 }
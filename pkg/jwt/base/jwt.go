@@ -20,6 +20,8 @@ type JWT[C any] interface {
 
 	GenerateRefresh() (string, error)
 
+	Sign(claims jwt.Claims) (string, error)
+
 	RefreshPair(ctx context.Context, claims C) (entities.TokenPair, error)
 
 	GetValidTime() time.Duration
@@ -27,21 +29,49 @@ type JWT[C any] interface {
 
 type GetClaimsByRefreshToken[C any] func(ctx context.Context, refresh string) (C, error)
 
+// Signer signs access tokens and returns keys to verify them
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+	Key(token *jwt.Token) (interface{}, error)
+}
+
+type hmac struct {
+	secret []byte
+}
+
+func NewHMAC(secret string) Signer {
+	return &hmac{secret: []byte(secret)}
+}
+
+func (h *hmac) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.secret)
+}
+
+func (h *hmac) Key(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return h.secret, nil
+}
+
 type j[C any] struct {
 	validTime time.Duration
-	secret    string
+	signer    Signer
 }
 
 func NewJWT[C any](validTime time.Duration, secret string) JWT[C] {
-	return &j[C]{validTime: validTime, secret: secret}
+	return NewJWTWithSigner[C](validTime, NewHMAC(secret))
+}
+
+func NewJWTWithSigner[C any](validTime time.Duration, signer Signer) JWT[C] {
+	return &j[C]{validTime: validTime, signer: signer}
 }
 
 func (c *j[C]) Validate(token string) (entities.BaseClaims[C], bool) {
 	claims := entities.BaseClaims[C]{}
 
-	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(c.secret), nil
-	})
+	_, err := jwt.ParseWithClaims(token, &claims, c.signer.Key)
 	if err != nil {
 		return entities.BaseClaims[C]{}, false
 	}
@@ -81,8 +111,12 @@ func (c *j[C]) GenerateAccessWithExpireTime(claims C, d time.Duration) (string,
 		},
 		Claims: claims,
 	}
-	str, err := jwt.NewWithClaims(jwt.SigningMethodHS256, cl).SignedString([]byte(c.secret))
-	return str, err
+	return c.signer.Sign(cl)
+}
+
+// Sign signs any claims with the same key as access tokens
+func (c *j[C]) Sign(claims jwt.Claims) (string, error) {
+	return c.signer.Sign(claims)
 }
 
 func (c *j[C]) GenerateRefresh() (string, error) {
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"github.com/golang-jwt/jwt"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron"
 	"github.com/sirupsen/logrus"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"studyum/pkg/jwt/base"
 	"studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/keys"
 	"studyum/pkg/jwt/repositories"
 	"studyum/pkg/jwt/utils"
 	"time"
@@ -17,17 +19,29 @@ import (
 var (
 	ValidationErr   = errors.New("Validation error")
 	RefreshTokenErr = errors.New("access token has expired")
+	NotFoundErr     = errors.New("session not found")
+	ReusedTokenErr  = errors.New("refresh token has already been used")
 )
 
 type Controller[C entities.IIDClaims] interface {
 	Create(ctx context.Context, ip string, userID string) (entities.TokenPair, error)
 	CreateWithTime(ctx context.Context, ip string, userID string, d time.Duration) (entities.TokenPair, error)
+	CreateImpersonation(ctx context.Context, ip string, userID string, actorID string, d time.Duration) (entities.TokenPair, error)
 
 	Auth(ctx context.Context, pair entities.TokenPair) (string, bool, error)
+	AuthSession(ctx context.Context, pair entities.TokenPair) (entities.Session, bool, error)
 
 	RemoveByToken(ctx context.Context, token string) error
+	GetSessions(ctx context.Context, userID string) ([]entities.Session, error)
+	RemoveFamily(ctx context.Context, userID string, id string) (entities.Session, error)
+	RemoveOtherSessions(ctx context.Context, userID string, pair entities.TokenPair) error
+	RemoveAllSessions(ctx context.Context, userID string) error
+	GetSessionID(pair entities.TokenPair) (string, error)
 	UpdateTokensByRefresh(ctx context.Context, token string, ip string) (entities.TokenPair, error)
 
+	JWKS() entities.JWKS
+	Sign(claims jwt.Claims) (string, error)
+
 	SetCreateClaimsFunc(func(ctx context.Context, id, userID string) (C, error))
 
 	LaunchCron()
@@ -41,6 +55,7 @@ type controller[C entities.IIDClaims] struct {
 	cron *cron.Cron
 
 	jwt        base.JWT[C]
+	keys       keys.KeySet
 	repository repositories.Repository
 
 	createClaimsFunc func(ctx context.Context, id, userID string) (C, error)
@@ -59,6 +74,17 @@ func NewControllerWithCreateClaimsFunc[C entities.IIDClaims](cronPattern string,
 	return c
 }
 
+// NewControllerWithKeys signs access tokens with asymmetric keys which are rotated by the cron
+func NewControllerWithKeys[C entities.IIDClaims](cronPattern string, expire, refreshExpire, timeout time.Duration, keySet keys.KeySet, repository repositories.Repository) Controller[C] {
+	jwt := base.NewJWTWithSigner[C](expire, keySet)
+
+	c := &controller[C]{refreshExpire: refreshExpire, timeout: timeout, cron: cron.New(), jwt: jwt, keys: keySet, repository: repository}
+	_ = c.cron.AddFunc(cronPattern, c.ClearExpired)
+	_ = c.cron.AddFunc("@every 10m", c.RotateKeys)
+
+	return c
+}
+
 func NewControllerWithSimpleClaims(cronPattern string, expire, refreshExpire, timeout time.Duration, secret string, repository repositories.Repository) Controller[entities.IIDClaims] {
 	return NewControllerWithCreateClaimsFunc[entities.IIDClaims](cronPattern, expire, refreshExpire, timeout, secret, repository, func(ctx context.Context, id, userID string) (entities.IIDClaims, error) {
 		return entities.IDClaims{ID: id}, nil
@@ -70,18 +96,55 @@ func (c *controller[C]) Create(ctx context.Context, ip string, userID string) (e
 }
 
 func (c *controller[C]) CreateWithTime(ctx context.Context, ip string, userID string, d time.Duration) (entities.TokenPair, error) {
+	session := entities.Session{IP: ip, UserID: userID, Expire: time.Now().Add(c.refreshExpire)}
+	return c.create(ctx, session, d, entities.Session{})
+}
+
+// CreateImpersonation issues a pair of the user for the actor, the session can not be refreshed beyond d
+func (c *controller[C]) CreateImpersonation(ctx context.Context, ip string, userID string, actorID string, d time.Duration) (entities.TokenPair, error) {
+	if actorID == "" || actorID == userID {
+		return entities.TokenPair{}, ValidationErr
+	}
+
+	session := entities.Session{IP: ip, UserID: userID, ActorID: actorID, Expire: time.Now().Add(d)}
+	return c.create(ctx, session, c.accessTime(session), entities.Session{})
+}
+
+// accessTime limits access tokens of impersonation sessions by the session expiration
+func (c *controller[C]) accessTime(session entities.Session) time.Duration {
+	d := c.jwt.GetValidTime()
+	if left := time.Until(session.Expire); session.ActorID != "" && left < d {
+		return left
+	}
+
+	return d
+}
+
+// create issues a new pair for the session, the session joins the family of the parent one if it is given
+func (c *controller[C]) create(ctx context.Context, session entities.Session, d time.Duration, parent entities.Session) (entities.TokenPair, error) {
 	//839_299_365_868_340_224
 	id := utils.RandomString(10)
 
 	if c.createClaimsFunc == nil {
 		return entities.TokenPair{}, errors.New("createClaimsFunc is nil")
 	}
-	claims, err := c.createClaimsFunc(ctx, id, userID)
+	claims, err := c.createClaimsFunc(ctx, id, session.UserID)
 
 	if err != nil {
 		return entities.TokenPair{}, err
 	}
 
+	if session.ActorID != "" {
+		actorClaims, ok := any(claims).(entities.IActorClaims)
+		if !ok {
+			return entities.TokenPair{}, errors.New("claims do not support actors")
+		}
+
+		if claims, ok = actorClaims.WithActor(session.ActorID).(C); !ok {
+			return entities.TokenPair{}, errors.New("claims do not support actors")
+		}
+	}
+
 	pair, err := c.jwt.GeneratePairWithExpireTime(claims, d)
 	if err != nil {
 		return entities.TokenPair{}, err
@@ -89,13 +152,13 @@ func (c *controller[C]) CreateWithTime(ctx context.Context, ip string, userID st
 
 	pair.Refresh = id + "|" + pair.Refresh
 
-	session := entities.Session{
-		ID:      id,
-		Token:   pair.Refresh,
-		IP:      ip,
-		UserID:  userID,
-		Expire:  time.Now().Add(c.refreshExpire),
-		Updated: false,
+	session.ID = id
+	session.Token = pair.Refresh
+	session.Updated = false
+	session.Family = id
+	if parent.ID != "" {
+		session.Family = parent.GetFamily()
+		session.Parent = parent.ID
 	}
 	if err = c.repository.Add(ctx, session); err != nil {
 		return entities.TokenPair{}, err
@@ -105,28 +168,39 @@ func (c *controller[C]) CreateWithTime(ctx context.Context, ip string, userID st
 }
 
 func (c *controller[C]) Auth(ctx context.Context, pair entities.TokenPair) (string, bool, error) {
+	session, needUpdate, err := c.AuthSession(ctx, pair)
+	if err != nil {
+		return "", false, err
+	}
+
+	return session.UserID, needUpdate, nil
+}
+
+// AuthSession returns the session the pair belongs to, the refresh token is only checked,
+// it is marked as rotated by UpdateTokensByRefresh, so a rejected request keeps the token valid
+func (c *controller[C]) AuthSession(ctx context.Context, pair entities.TokenPair) (entities.Session, bool, error) {
 	var id string
 	needUpdate := false
 
 	claims, ok := c.jwt.Validate(pair.Access)
 	if !ok {
 		var err error
-		id, needUpdate, err = c.authViaRefreshToken(ctx, pair.Refresh)
+		id, needUpdate, err = c.authViaRefreshToken(ctx, pair.Refresh, false)
 		if err != nil {
-			return "", false, RefreshTokenErr
+			return entities.Session{}, false, RefreshTokenErr
 		}
 	} else {
 		id = claims.Claims.GetID()
 	}
 	session, err := c.repository.GetByID(ctx, id)
 	if err != nil {
-		return "", false, err
+		return entities.Session{}, false, err
 	}
 
-	return session.UserID, needUpdate, nil
+	return session, needUpdate, nil
 }
 
-func (c *controller[C]) authViaRefreshToken(ctx context.Context, token string) (string, bool, error) {
+func (c *controller[C]) authViaRefreshToken(ctx context.Context, token string, rotate bool) (string, bool, error) {
 	i := strings.IndexByte(token, '|')
 	if i == -1 {
 		return "", false, ValidationErr
@@ -147,11 +221,22 @@ func (c *controller[C]) authViaRefreshToken(ctx context.Context, token string) (
 	}
 
 	if session.Updated {
+		// a rotated token is accepted only during the timeout, later use means it has leaked
+		if time.Now().After(session.UpdatedAt.Add(c.timeout)) {
+			c.revokeFamily(ctx, session)
+			return "", false, ReusedTokenErr
+		}
+
 		return session.ID, false, nil
 	}
 
+	if !rotate {
+		return session.ID, true, nil
+	}
+
+	// rotated sessions are kept until they expire to detect reuse of their tokens
 	session.Updated = true
-	session.Expire = time.Now().Add(c.timeout)
+	session.UpdatedAt = time.Now()
 	if err = c.repository.Update(ctx, session); err != nil {
 		return "", false, err
 	}
@@ -159,18 +244,91 @@ func (c *controller[C]) authViaRefreshToken(ctx context.Context, token string) (
 	return session.ID, true, nil
 }
 
-func (c *controller[C]) RemoveByToken(ctx context.Context, token string) error {
+func (c *controller[C]) revokeFamily(ctx context.Context, session entities.Session) {
+	logger := logrus.WithFields(logrus.Fields{"userID": session.UserID, "session": session.ID, "family": session.GetFamily()})
+	logger.Warningln("Security event: refresh token reuse detected, revoking session family")
+
+	if err := c.repository.RemoveByFamily(ctx, session.GetFamily()); err != nil {
+		logger.Error("Error revoking session family: " + err.Error())
+	}
+}
+
+func (c *controller[C]) sessionID(token string) (string, error) {
 	i := strings.IndexByte(token, '|')
 	if i == -1 {
-		return ValidationErr
+		return "", ValidationErr
+	}
+
+	return token[:i], nil
+}
+
+func (c *controller[C]) RemoveByToken(ctx context.Context, token string) error {
+	id, err := c.sessionID(token)
+	if err != nil {
+		return err
 	}
 
-	id := token[:i]
 	return c.repository.RemoveByID(ctx, id)
 }
 
+// GetSessions returns active sessions of the user, sessions already exchanged for a new pair are skipped
+func (c *controller[C]) GetSessions(ctx context.Context, userID string) ([]entities.Session, error) {
+	sessions, err := c.repository.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]entities.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Updated || time.Now().After(session.Expire) {
+			continue
+		}
+
+		active = append(active, session)
+	}
+
+	return active, nil
+}
+
+// RemoveFamily removes the session with every session refreshed from the same login
+func (c *controller[C]) RemoveFamily(ctx context.Context, userID string, id string) (entities.Session, error) {
+	session, err := c.repository.GetByID(ctx, id)
+	if errors.Is(err, repositories.NotValidRefreshTokenErr) || (err == nil && session.UserID != userID) {
+		return entities.Session{}, NotFoundErr
+	}
+	if err != nil {
+		return entities.Session{}, err
+	}
+
+	return session, c.repository.RemoveByFamily(ctx, session.GetFamily())
+}
+
+// RemoveOtherSessions removes every session of the user except the one the pair belongs to
+func (c *controller[C]) RemoveOtherSessions(ctx context.Context, userID string, pair entities.TokenPair) error {
+	id, err := c.GetSessionID(pair)
+	if err != nil {
+		return err
+	}
+
+	return c.repository.RemoveByUserID(ctx, userID, id)
+}
+
+// RemoveAllSessions signs the user out everywhere
+func (c *controller[C]) RemoveAllSessions(ctx context.Context, userID string) error {
+	return c.repository.RemoveByUserID(ctx, userID)
+}
+
+// GetSessionID returns id of the session the pair belongs to without checking the session itself
+func (c *controller[C]) GetSessionID(pair entities.TokenPair) (string, error) {
+	if claims, ok := c.jwt.Validate(pair.Access); ok {
+		return claims.Claims.GetID(), nil
+	}
+
+	return c.sessionID(pair.Refresh)
+}
+
 func (c *controller[C]) UpdateTokensByRefresh(ctx context.Context, refresh string, ip string) (entities.TokenPair, error) {
-	id, _, err := c.authViaRefreshToken(ctx, refresh)
+	id, _, err := c.authViaRefreshToken(ctx, refresh, true)
 	if err != nil {
 		return entities.TokenPair{}, err
 	}
@@ -179,7 +337,26 @@ func (c *controller[C]) UpdateTokensByRefresh(ctx context.Context, refresh strin
 		return entities.TokenPair{}, err
 	}
 
-	return c.Create(ctx, ip, session.UserID)
+	child := entities.Session{IP: ip, UserID: session.UserID, ActorID: session.ActorID, Expire: time.Now().Add(c.refreshExpire)}
+	if session.ActorID != "" {
+		// impersonation does not outlive the first session
+		child.Expire = session.Expire
+	}
+
+	return c.create(ctx, child, c.accessTime(child), session)
+}
+
+// JWKS returns public keys to verify access tokens, it is empty for tokens signed with a secret
+func (c *controller[C]) JWKS() entities.JWKS {
+	if c.keys == nil {
+		return entities.JWKS{Keys: []entities.JWK{}}
+	}
+
+	return c.keys.JWKS()
+}
+
+func (c *controller[C]) Sign(claims jwt.Claims) (string, error) {
+	return c.jwt.Sign(claims)
 }
 
 func (c *controller[C]) SetCreateClaimsFunc(f func(ctx context.Context, id, userID string) (C, error)) {
@@ -194,6 +371,12 @@ func (c *controller[C]) StopCron() {
 	c.cron.Stop()
 }
 
+func (c *controller[C]) RotateKeys() {
+	if err := c.keys.Rotate(context.Background()); err != nil {
+		logrus.Error("Error rotating signing keys: " + err.Error())
+	}
+}
+
 func (c *controller[C]) ClearExpired() {
 	logrus.Infoln("Clear expired tokens at " + time.Now().Format(time.ANSIC))
 
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	r "github.com/redis/go-redis/v9"
+	"strings"
+	"studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/repositories"
+	"testing"
+	"time"
+)
+
+const testTimeout = time.Millisecond * 50
+
+func newTestController(t *testing.T) (Controller[entities.IIDClaims], repositories.Repository) {
+	server := miniredis.RunT(t)
+	repository := repositories.NewRedis(r.NewClient(&r.Options{Addr: server.Addr()}))
+
+	return NewControllerWithSimpleClaims("@daily", time.Minute, time.Hour, testTimeout, "secret", repository), repository
+}
+
+func sessionOf(refresh string) string {
+	return refresh[:strings.IndexByte(refresh, '|')]
+}
+
+func TestRotationKeepsFamily(t *testing.T) {
+	ctx := context.Background()
+	c, repository := newTestController(t)
+
+	root, err := c.Create(ctx, "ip", "user")
+	assert.Equal(t, err, nil)
+
+	child, err := c.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	grandchild, err := c.UpdateTokensByRefresh(ctx, child.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	session, err := repository.GetByID(ctx, sessionOf(grandchild.Refresh))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.Family, sessionOf(root.Refresh))
+	assert.Equal(t, session.Parent, sessionOf(child.Refresh))
+
+	sessions, err := c.GetSessions(ctx, "user")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sessions), 1)
+}
+
+func TestRotatedTokenGraceWindow(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestController(t)
+
+	root, err := c.Create(ctx, "ip", "user")
+	assert.Equal(t, err, nil)
+
+	_, err = c.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	// concurrent requests with the same token are accepted during the timeout
+	_, err = c.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, err, nil)
+}
+
+func TestRotatedTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	c, repository := newTestController(t)
+
+	root, err := c.Create(ctx, "ip", "user")
+	assert.Equal(t, err, nil)
+
+	child, err := c.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	grandchild, err := c.UpdateTokensByRefresh(ctx, child.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	other, err := c.Create(ctx, "ip", "user")
+	assert.Equal(t, err, nil)
+
+	time.Sleep(testTimeout * 2)
+
+	_, err = c.UpdateTokensByRefresh(ctx, root.Refresh, "ip")
+	assert.Equal(t, errors.Is(err, ReusedTokenErr), true)
+
+	for _, pair := range []string{root.Refresh, child.Refresh, grandchild.Refresh} {
+		_, err = repository.GetByID(ctx, sessionOf(pair))
+		assert.Equal(t, errors.Is(err, repositories.NotValidRefreshTokenErr), true)
+	}
+
+	_, err = c.UpdateTokensByRefresh(ctx, grandchild.Refresh, "ip")
+	assert.NotEqual(t, err, nil)
+
+	_, err = c.UpdateTokensByRefresh(ctx, other.Refresh, "ip")
+	assert.Equal(t, err, nil)
+}
+
+type actorClaims struct {
+	entities.IDClaims
+	ActorID string `json:"actorID"`
+}
+
+func (c actorClaims) WithActor(actorID string) entities.IIDClaims {
+	c.ActorID = actorID
+	return c
+}
+
+func TestImpersonationKeepsActorAndExpiration(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	repository := repositories.NewRedis(r.NewClient(&r.Options{Addr: server.Addr()}))
+	c := NewControllerWithCreateClaimsFunc[actorClaims]("@daily", time.Minute, time.Hour, testTimeout, "secret", repository, func(ctx context.Context, id, userID string) (actorClaims, error) {
+		return actorClaims{IDClaims: entities.IDClaims{ID: id}}, nil
+	})
+
+	pair, err := c.CreateImpersonation(ctx, "ip", "user", "admin", time.Minute*30)
+	assert.Equal(t, err, nil)
+
+	session, _, err := c.AuthSession(ctx, pair)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.UserID, "user")
+	assert.Equal(t, session.ActorID, "admin")
+
+	child, err := c.UpdateTokensByRefresh(ctx, pair.Refresh, "ip")
+	assert.Equal(t, err, nil)
+
+	refreshed, err := repository.GetByID(ctx, sessionOf(child.Refresh))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, refreshed.ActorID, "admin")
+	assert.Equal(t, refreshed.Expire.Unix(), session.Expire.Unix())
+
+	_, err = c.RemoveFamily(ctx, "admin", sessionOf(child.Refresh))
+	assert.Equal(t, errors.Is(err, NotFoundErr), true)
+
+	_, err = c.RemoveFamily(ctx, "user", sessionOf(child.Refresh))
+	assert.Equal(t, err, nil)
+
+	sessions, err := c.GetSessions(ctx, "user")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sessions), 0)
+}
+
+func TestImpersonationRequiresActorClaims(t *testing.T) {
+	c, _ := newTestController(t)
+
+	_, err := c.CreateImpersonation(context.Background(), "ip", "user", "admin", time.Minute)
+	assert.NotEqual(t, err, nil)
+
+	_, err = c.CreateImpersonation(context.Background(), "ip", "user", "user", time.Minute)
+	assert.Equal(t, errors.Is(err, ValidationErr), true)
+}
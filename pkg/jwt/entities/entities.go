@@ -14,6 +14,11 @@ type IIDClaims interface {
 	GetID() string
 }
 
+// IActorClaims are claims which can name the user acting on behalf of the session user
+type IActorClaims interface {
+	WithActor(actorID string) IIDClaims
+}
+
 type IDClaims struct {
 	ID string `json:"id" bson:"id"`
 }
@@ -28,10 +33,49 @@ type BaseClaims[C any] struct {
 }
 
 type Session struct {
-	ID      string    `json:"id" bson:"_id"`
-	Token   string    `json:"token" bson:"token"`
-	IP      string    `json:"ip" bson:"ip"`
-	UserID  string    `json:"userID" bson:"userID"`
-	Expire  time.Time `json:"expire" bson:"expire"`
-	Updated bool      `json:"updated" bson:"updated"`
+	ID        string    `json:"id" bson:"_id"`
+	Token     string    `json:"-" bson:"token"`
+	IP        string    `json:"ip" bson:"ip"`
+	UserID    string    `json:"userID" bson:"userID"`
+	Expire    time.Time `json:"expire" bson:"expire"`
+	Updated   bool      `json:"updated" bson:"updated"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	Family    string    `json:"family" bson:"family"`
+	Parent    string    `json:"parent" bson:"parent"`
+	// ActorID is the user who impersonates the session user
+	ActorID string `json:"actorID,omitempty" bson:"actorID,omitempty"`
+}
+
+// GetFamily returns id of the first session of the rotation chain
+func (s Session) GetFamily() string {
+	if s.Family == "" {
+		return s.ID
+	}
+
+	return s.Family
+}
+
+// SigningKey is a PKCS #8 encoded private key used to sign access tokens
+type SigningKey struct {
+	ID         string    `json:"id" bson:"_id"`
+	Algorithm  string    `json:"algorithm" bson:"algorithm"`
+	PrivateKey []byte    `json:"privateKey" bson:"privateKey"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+	ExpireAt   time.Time `json:"expireAt" bson:"expireAt"`
+}
+
+// JWK is a public key in the RFC 7517 format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
 }
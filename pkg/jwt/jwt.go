@@ -1,11 +1,14 @@
 package jwt
 
 import (
+	"context"
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
+	"studyum/pkg/encryption"
 	"studyum/pkg/jwt/base"
 	"studyum/pkg/jwt/controllers"
 	"studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/keys"
 	"studyum/pkg/jwt/repositories"
 	"time"
 )
@@ -20,6 +23,30 @@ func NewWithRedis[C entities.IIDClaims](cronPattern string, expire time.Duration
 	return NewWithRepository[C](cronPattern, expire, refreshExpire, timeout, secret, r)
 }
 
+// NewWithRedisKeys signs tokens with the algorithm (RS256 or EdDSA), keys are rotated every rotation
+// and stored in redis encrypted with the encryption
+func NewWithRedisKeys[C entities.IIDClaims](cronPattern string, expire time.Duration, refreshExpire time.Duration, timeout time.Duration, algorithm string, rotation time.Duration, client *redis.Client, encryption encryption.Encryption) (controllers.Controller[C], error) {
+	keySet, err := keys.New(context.Background(), algorithm, rotation, expire, repositories.NewRedisKeys(client, encryption))
+	if err != nil {
+		return nil, err
+	}
+
+	r := repositories.NewRedis(client)
+	return controllers.NewControllerWithKeys[C](cronPattern, expire, refreshExpire, timeout, keySet, r), nil
+}
+
+// NewWithMongoKeys signs tokens with the algorithm (RS256 or EdDSA), keys are rotated every rotation
+// and stored in mongo encrypted with the encryption
+func NewWithMongoKeys[C entities.IIDClaims](cronPattern string, expire time.Duration, refreshExpire time.Duration, timeout time.Duration, algorithm string, rotation time.Duration, sessions *mongo.Collection, signingKeys *mongo.Collection, encryption encryption.Encryption) (controllers.Controller[C], error) {
+	keySet, err := keys.New(context.Background(), algorithm, rotation, expire, repositories.NewMongoKeys(signingKeys, encryption))
+	if err != nil {
+		return nil, err
+	}
+
+	r := repositories.NewMongo(sessions)
+	return controllers.NewControllerWithKeys[C](cronPattern, expire, refreshExpire, timeout, keySet, r), nil
+}
+
 func NewWithRepository[C entities.IIDClaims](cronPattern string, expire time.Duration, refreshExpire time.Duration, timeout time.Duration, secret string, repo repositories.Repository) controllers.Controller[C] {
 	c := controllers.NewController[C](cronPattern, expire, refreshExpire, timeout, secret, repo)
 	return c
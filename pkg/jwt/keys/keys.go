@@ -0,0 +1,282 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+	"math/big"
+	"studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/repositories"
+	"studyum/pkg/jwt/utils"
+	"sync"
+	"time"
+)
+
+const (
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+
+	rsaBits = 2048
+
+	// reloadTimeout limits reloads caused by tokens signed with unknown keys
+	reloadTimeout = time.Minute
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown signing algorithm")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrNoKeys           = errors.New("no signing keys")
+)
+
+// KeySet signs tokens with the newest key and verifies them with any of the keys which are not expired yet.
+// A new key is created every rotation and is kept long enough to verify every token signed with it.
+type KeySet interface {
+	Sign(claims jwt.Claims) (string, error)
+	Key(token *jwt.Token) (interface{}, error)
+
+	Load(ctx context.Context) error
+	Rotate(ctx context.Context) error
+
+	JWKS() entities.JWKS
+}
+
+type key struct {
+	entities.SigningKey
+
+	private crypto.Signer
+	method  jwt.SigningMethod
+}
+
+type keySet struct {
+	algorithm string
+	rotation  time.Duration
+	validTime time.Duration
+
+	repository repositories.KeyRepository
+
+	mutex    sync.RWMutex
+	keys     []key
+	loadedAt time.Time
+}
+
+func New(ctx context.Context, algorithm string, rotation, validTime time.Duration, repository repositories.KeyRepository) (KeySet, error) {
+	if _, err := method(algorithm); err != nil {
+		return nil, err
+	}
+
+	s := &keySet{algorithm: algorithm, rotation: rotation, validTime: validTime, repository: repository}
+	if err := s.Rotate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func method(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case RS256:
+		return jwt.SigningMethodRS256, nil
+	case EdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.Wrap(ErrUnknownAlgorithm, algorithm)
+	}
+}
+
+func (s *keySet) Sign(claims jwt.Claims) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.keys) == 0 {
+		return "", ErrNoKeys
+	}
+
+	current := s.keys[0]
+	token := jwt.NewWithClaims(current.method, claims)
+	token.Header["kid"] = current.ID
+
+	return token.SignedString(current.private)
+}
+
+func (s *keySet) Key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k, ok := s.get(kid)
+	if !ok && s.shouldReload() {
+		if err := s.Load(context.Background()); err != nil {
+			return nil, err
+		}
+
+		k, ok = s.get(kid)
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != k.Algorithm {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return k.private.Public(), nil
+}
+
+func (s *keySet) get(kid string) (key, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, k := range s.keys {
+		if k.ID == kid && time.Now().Before(k.ExpireAt) {
+			return k, true
+		}
+	}
+
+	return key{}, false
+}
+
+func (s *keySet) shouldReload() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return time.Since(s.loadedAt) > reloadTimeout
+}
+
+// Load replaces keys with the ones from the repository, so keys rotated by other instances are picked up
+func (s *keySet) Load(ctx context.Context) error {
+	stored, err := s.repository.GetKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]key, 0, len(stored))
+	for _, signingKey := range stored {
+		k, err := parse(signingKey)
+		if err != nil {
+			// keys which can't be decrypted are skipped, Rotate replaces them with a new one
+			logrus.Warningf("Can't parse signing key %s, error: %s", signingKey.ID, err.Error())
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	slices.SortFunc(keys, func(a, b key) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	s.mutex.Lock()
+	s.keys = keys
+	s.loadedAt = time.Now()
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Rotate loads keys and creates a new one if the current key is older than the rotation period
+func (s *keySet) Rotate(ctx context.Context) error {
+	if err := s.Load(ctx); err != nil {
+		return err
+	}
+
+	s.mutex.RLock()
+	fresh := len(s.keys) != 0 && s.keys[0].Algorithm == s.algorithm && time.Since(s.keys[0].CreatedAt) < s.rotation
+	s.mutex.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	signingKey, err := generate(s.algorithm, time.Now(), s.rotation+s.validTime)
+	if err != nil {
+		return err
+	}
+
+	if err = s.repository.AddKey(ctx, signingKey); err != nil {
+		return err
+	}
+
+	return s.Load(ctx)
+}
+
+func (s *keySet) JWKS() entities.JWKS {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	jwks := entities.JWKS{Keys: make([]entities.JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		jwk := entities.JWK{Kid: k.ID, Alg: k.Algorithm, Use: "sig"}
+
+		switch public := k.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// generate creates a key which stays valid for the ttl
+func generate(algorithm string, now time.Time, ttl time.Duration) (entities.SigningKey, error) {
+	var private any
+	var err error
+
+	switch algorithm {
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = errors.Wrap(ErrUnknownAlgorithm, algorithm)
+	}
+	if err != nil {
+		return entities.SigningKey{}, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return entities.SigningKey{}, err
+	}
+
+	return entities.SigningKey{
+		ID:         utils.RandomString(16),
+		Algorithm:  algorithm,
+		PrivateKey: der,
+		CreatedAt:  now,
+		ExpireAt:   now.Add(ttl),
+	}, nil
+}
+
+func parse(signingKey entities.SigningKey) (key, error) {
+	m, err := method(signingKey.Algorithm)
+	if err != nil {
+		return key{}, err
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(signingKey.PrivateKey)
+	if err != nil {
+		return key{}, err
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return key{}, errors.Wrap(ErrUnknownAlgorithm, signingKey.Algorithm)
+	}
+
+	return key{SigningKey: signingKey, private: signer, method: m}, nil
+}
@@ -0,0 +1,169 @@
+package keys
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt"
+	"github.com/redis/go-redis/v9"
+	"math/big"
+	"studyum/pkg/encryption"
+	"studyum/pkg/jwt/base"
+	"studyum/pkg/jwt/entities"
+	"studyum/pkg/jwt/repositories"
+	"testing"
+	"time"
+)
+
+func newTestRepository(t *testing.T) repositories.KeyRepository {
+	server := miniredis.RunT(t)
+	return repositories.NewRedisKeys(redis.NewClient(&redis.Options{Addr: server.Addr()}), encryption.NewEncryption("0123456789abcdef"))
+}
+
+func newTestKeySet(t *testing.T, algorithm string, rotation time.Duration, repository repositories.KeyRepository) KeySet {
+	keySet, err := New(context.Background(), algorithm, rotation, time.Minute, repository)
+	assert.Equal(t, err, nil)
+
+	return keySet
+}
+
+func TestSignAndValidate(t *testing.T) {
+	for _, algorithm := range []string{RS256, EdDSA} {
+		keySet := newTestKeySet(t, algorithm, time.Hour, newTestRepository(t))
+		j := base.NewJWTWithSigner[entities.IDClaims](time.Minute, keySet)
+
+		access, err := j.GenerateAccess(entities.IDClaims{ID: "session"})
+		assert.Equal(t, err, nil)
+
+		claims, ok := j.Validate(access)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, claims.Claims.ID, "session")
+
+		token, _, err := new(jwt.Parser).ParseUnverified(access, &jwt.StandardClaims{})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, token.Method.Alg(), algorithm)
+		assert.Equal(t, token.Header["kid"], keySet.JWKS().Keys[0].Kid)
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	_, err := New(context.Background(), "HS256", time.Hour, time.Minute, newTestRepository(t))
+	assert.NotEqual(t, err, nil)
+}
+
+func TestRotationKeepsPreviousKeys(t *testing.T) {
+	ctx := context.Background()
+	keySet := newTestKeySet(t, EdDSA, time.Millisecond, newTestRepository(t))
+	j := base.NewJWTWithSigner[entities.IDClaims](time.Minute, keySet)
+
+	old, err := j.GenerateAccess(entities.IDClaims{ID: "old"})
+	assert.Equal(t, err, nil)
+
+	time.Sleep(time.Millisecond * 5)
+	assert.Equal(t, keySet.Rotate(ctx), nil)
+	assert.Equal(t, len(keySet.JWKS().Keys), 2)
+
+	current, err := j.GenerateAccess(entities.IDClaims{ID: "current"})
+	assert.Equal(t, err, nil)
+
+	_, ok := j.Validate(old)
+	assert.Equal(t, ok, true)
+	_, ok = j.Validate(current)
+	assert.Equal(t, ok, true)
+
+	oldToken, _, _ := new(jwt.Parser).ParseUnverified(old, &jwt.StandardClaims{})
+	currentToken, _, _ := new(jwt.Parser).ParseUnverified(current, &jwt.StandardClaims{})
+	assert.NotEqual(t, oldToken.Header["kid"], currentToken.Header["kid"])
+}
+
+func TestRotationIsSkippedForFreshKey(t *testing.T) {
+	keySet := newTestKeySet(t, EdDSA, time.Hour, newTestRepository(t))
+
+	assert.Equal(t, keySet.Rotate(context.Background()), nil)
+	assert.Equal(t, len(keySet.JWKS().Keys), 1)
+}
+
+func TestKeysAreSharedBetweenInstances(t *testing.T) {
+	repository := newTestRepository(t)
+	first := newTestKeySet(t, RS256, time.Hour, repository)
+	second := newTestKeySet(t, RS256, time.Hour, repository)
+
+	access, err := base.NewJWTWithSigner[entities.IDClaims](time.Minute, first).GenerateAccess(entities.IDClaims{ID: "session"})
+	assert.Equal(t, err, nil)
+
+	_, ok := base.NewJWTWithSigner[entities.IDClaims](time.Minute, second).Validate(access)
+	assert.Equal(t, ok, true)
+}
+
+func TestRejectsOtherAlgorithms(t *testing.T) {
+	keySet := newTestKeySet(t, RS256, time.Hour, newTestRepository(t))
+	j := base.NewJWTWithSigner[entities.IDClaims](time.Minute, keySet)
+	jwk := keySet.JWKS().Keys[0]
+
+	// the public key must not be accepted as an HMAC secret
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, entities.BaseClaims[entities.IDClaims]{Claims: entities.IDClaims{ID: "session"}})
+	token.Header["kid"] = jwk.Kid
+	forged, err := token.SignedString([]byte(jwk.N))
+	assert.Equal(t, err, nil)
+
+	_, ok := j.Validate(forged)
+	assert.Equal(t, ok, false)
+
+	hmac := base.NewJWT[entities.IDClaims](time.Minute, "secret")
+	signed, err := hmac.GenerateAccess(entities.IDClaims{ID: "session"})
+	assert.Equal(t, err, nil)
+
+	_, ok = j.Validate(signed)
+	assert.Equal(t, ok, false)
+}
+
+func TestJWKSVerifiesTokens(t *testing.T) {
+	for _, algorithm := range []string{RS256, EdDSA} {
+		keySet := newTestKeySet(t, algorithm, time.Hour, newTestRepository(t))
+		access, err := base.NewJWTWithSigner[entities.IDClaims](time.Minute, keySet).GenerateAccess(entities.IDClaims{ID: "session"})
+		assert.Equal(t, err, nil)
+
+		jwk := keySet.JWKS().Keys[0]
+		assert.Equal(t, jwk.Alg, algorithm)
+		assert.Equal(t, jwk.Use, "sig")
+
+		_, err = jwt.Parse(access, func(token *jwt.Token) (interface{}, error) {
+			if jwk.Kty == "OKP" {
+				x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+				return ed25519.PublicKey(x), err
+			}
+
+			n, _ := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, _ := base64.RawURLEncoding.DecodeString(jwk.E)
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+		})
+		assert.Equal(t, err, nil)
+	}
+}
+
+func TestPrivateKeysAreEncrypted(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	keySet := newTestKeySet(t, EdDSA, time.Hour, repositories.NewRedisKeys(client, encryption.NewEncryption("0123456789abcdef")))
+	kid := keySet.JWKS().Keys[0].Kid
+
+	var stored entities.SigningKey
+	data, err := client.HGet(ctx, "signing-keys", kid).Bytes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, json.Unmarshal(data, &stored), nil)
+
+	_, err = x509.ParsePKCS8PrivateKey(stored.PrivateKey)
+	assert.NotEqual(t, err, nil)
+
+	// keys that can't be decrypted with the secret are replaced with a new one
+	other := newTestKeySet(t, EdDSA, time.Hour, repositories.NewRedisKeys(client, encryption.NewEncryption("fedcba9876543210")))
+	assert.Equal(t, len(other.JWKS().Keys), 1)
+	assert.NotEqual(t, other.JWKS().Keys[0].Kid, kid)
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	m "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"studyum/pkg/encryption"
+	"studyum/pkg/jwt/entities"
+	"time"
+)
+
+type mongoKeys struct {
+	keys       *m.Collection
+	encryption encryption.Encryption
+}
+
+func NewMongoKeys(keys *m.Collection, encryption encryption.Encryption) KeyRepository {
+	_, err := keys.Indexes().CreateOne(context.Background(), m.IndexModel{
+		Keys:    bson.D{{Key: "expireAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logrus.Warningln("Error creating signing keys index: " + err.Error())
+	}
+
+	return &mongoKeys{keys: keys, encryption: encryption}
+}
+
+func (r *mongoKeys) AddKey(ctx context.Context, key entities.SigningKey) error {
+	_, err := r.keys.InsertOne(ctx, encryptKey(r.encryption, key))
+	return err
+}
+
+func (r *mongoKeys) GetKeys(ctx context.Context) ([]entities.SigningKey, error) {
+	cursor, err := r.keys.Find(ctx, bson.M{"expireAt": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []entities.SigningKey
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		keys[i] = decryptKey(r.encryption, keys[i])
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	r "github.com/redis/go-redis/v9"
+	"studyum/pkg/encryption"
+	"studyum/pkg/jwt/entities"
+	"time"
+)
+
+const signingKeysKey = "signing-keys"
+
+type redisKeys struct {
+	client     *r.Client
+	encryption encryption.Encryption
+}
+
+func NewRedisKeys(client *r.Client, encryption encryption.Encryption) KeyRepository {
+	return &redisKeys{client: client, encryption: encryption}
+}
+
+func (repo *redisKeys) AddKey(ctx context.Context, key entities.SigningKey) error {
+	data, err := json.Marshal(encryptKey(repo.encryption, key))
+	if err != nil {
+		return err
+	}
+
+	return repo.client.HSet(ctx, signingKeysKey, key.ID, data).Err()
+}
+
+// GetKeys returns keys which are not expired yet and drops the expired ones
+func (repo *redisKeys) GetKeys(ctx context.Context) ([]entities.SigningKey, error) {
+	result, err := repo.client.HGetAll(ctx, signingKeysKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]entities.SigningKey, 0, len(result))
+	for id, data := range result {
+		var key entities.SigningKey
+		if err = json.Unmarshal([]byte(data), &key); err != nil {
+			return nil, err
+		}
+
+		if time.Now().After(key.ExpireAt) {
+			if err = repo.client.HDel(ctx, signingKeysKey, id).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		keys = append(keys, decryptKey(repo.encryption, key))
+	}
+
+	return keys, nil
+}
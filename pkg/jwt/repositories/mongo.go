@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	m "go.mongodb.org/mongo-driver/mongo"
 	"studyum/pkg/jwt/entities"
@@ -14,6 +15,14 @@ type mongo struct {
 }
 
 func NewMongo(sessions *m.Collection) Repository {
+	_, err := sessions.Indexes().CreateMany(context.Background(), []m.IndexModel{
+		{Keys: bson.D{{Key: "userID", Value: 1}}},
+		{Keys: bson.D{{Key: "family", Value: 1}}},
+	})
+	if err != nil {
+		logrus.Warningln("Error creating sessions indexes: " + err.Error())
+	}
+
 	return &mongo{sessions: sessions}
 }
 
@@ -35,13 +44,42 @@ func (r *mongo) GetByID(ctx context.Context, id string) (session entities.Sessio
 	return
 }
 
+func (r *mongo) GetByUserID(ctx context.Context, userID string) ([]entities.Session, error) {
+	cursor, err := r.sessions.Find(ctx, bson.M{"userID": userID, "expire": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []entities.Session
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *mongo) RemoveByUserID(ctx context.Context, userID string, except ...string) error {
+	filter := bson.M{"userID": userID}
+	if len(except) != 0 {
+		filter["_id"] = bson.M{"$nin": except}
+	}
+
+	_, err := r.sessions.DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *mongo) RemoveByFamily(ctx context.Context, family string) error {
+	_, err := r.sessions.DeleteMany(ctx, bson.M{"$or": bson.A{bson.M{"_id": family}, bson.M{"family": family}}})
+	return err
+}
+
 func (r *mongo) Update(ctx context.Context, session entities.Session) error {
 	_, err := r.sessions.UpdateByID(ctx, session.ID, bson.M{"$set": session})
 	return err
 }
 
 func (r *mongo) RemoveExpired(ctx context.Context) (int, error) {
-	many, err := r.sessions.DeleteMany(ctx, bson.M{"expire": bson.M{"$gte": time.Now()}})
+	many, err := r.sessions.DeleteMany(ctx, bson.M{"expire": bson.M{"$lt": time.Now()}})
 	if err != nil {
 		return 0, err
 	}
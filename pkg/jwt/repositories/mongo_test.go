@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"github.com/go-playground/assert/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"studyum/pkg/jwt/entities"
+	"testing"
+	"time"
+)
+
+func TestMongoSessionFamily(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("get by id", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := NewMongo(mt.Coll)
+		mt.ClearEvents()
+
+		updatedAt := time.Now().Truncate(time.Millisecond)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.sessions", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "child"},
+			{Key: "token", Value: "child|t"},
+			{Key: "userID", Value: "user"},
+			{Key: "updated", Value: true},
+			{Key: "updatedAt", Value: updatedAt},
+			{Key: "family", Value: "root"},
+			{Key: "parent", Value: "root"},
+		}))
+
+		session, err := repo.GetByID(context.Background(), "child")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, session.Updated, true)
+		assert.Equal(t, session.UpdatedAt.Equal(updatedAt), true)
+		assert.Equal(t, session.Family, "root")
+		assert.Equal(t, session.Parent, "root")
+	})
+
+	mt.Run("update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := NewMongo(mt.Coll)
+		mt.ClearEvents()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		err := repo.Update(context.Background(), entities.Session{ID: "root", Updated: true, UpdatedAt: time.Now(), Family: "root"})
+		assert.Equal(t, err, nil)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set")
+		assert.Equal(t, update.Document().Lookup("updated").Boolean(), true)
+		assert.Equal(t, update.Document().Lookup("family").StringValue(), "root")
+	})
+
+	mt.Run("remove by family", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := NewMongo(mt.Coll)
+		mt.ClearEvents()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		assert.Equal(t, repo.RemoveByFamily(context.Background(), "root"), nil)
+
+		event := mt.GetStartedEvent()
+		assert.Equal(t, event.CommandName, "delete")
+
+		filter := event.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "$or").Array()
+		assert.Equal(t, filter.Index(0).Value().Document().Lookup("_id").StringValue(), "root")
+		assert.Equal(t, filter.Index(1).Value().Document().Lookup("family").StringValue(), "root")
+	})
+
+	mt.Run("remove expired", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := NewMongo(mt.Coll)
+		mt.ClearEvents()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}))
+		amount, err := repo.RemoveExpired(context.Background())
+		assert.Equal(t, err, nil)
+		assert.Equal(t, amount, 3)
+
+		filter := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "expire")
+		_, ok := filter.Document().Lookup("$lt").DateTimeOK()
+		assert.Equal(t, ok, true)
+	})
+}
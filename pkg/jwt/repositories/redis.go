@@ -4,6 +4,7 @@ import (
 	"context"
 	r "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 	"studyum/pkg/jwt/entities"
 	"time"
 )
@@ -23,15 +24,56 @@ func (repo *redis) Add(ctx context.Context, session entities.Session) error {
 		"userID", session.UserID,
 		"expire", session.Expire.Format(time.RFC3339),
 		"updated", session.Updated,
+		"updatedAt", session.UpdatedAt.Format(time.RFC3339Nano),
+		"family", session.Family,
+		"parent", session.Parent,
+		"actorID", session.ActorID,
 	).Err()
 	if err != nil {
 		return err
 	}
 
-	return repo.client.Expire(ctx, "user:"+session.ID, time.Until(session.Expire)).Err()
+	if err = repo.client.Expire(ctx, "user:"+session.ID, time.Until(session.Expire)).Err(); err != nil {
+		return err
+	}
+
+	if err = repo.index(ctx, "user-sessions:"+session.UserID, session); err != nil {
+		return err
+	}
+
+	return repo.index(ctx, "session-family:"+session.GetFamily(), session)
+}
+
+// index adds the session to the set, the set lives as long as its longest session
+func (repo *redis) index(ctx context.Context, key string, session entities.Session) error {
+	if err := repo.client.SAdd(ctx, key, session.ID).Err(); err != nil {
+		return err
+	}
+
+	ttl, err := repo.client.TTL(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	if ttl < time.Until(session.Expire) {
+		return repo.client.Expire(ctx, key, time.Until(session.Expire)).Err()
+	}
+
+	return nil
 }
 
 func (repo *redis) RemoveByID(ctx context.Context, id string) error {
+	userID, err := repo.client.HGet(ctx, "user:"+id, "userID").Result()
+	if err != nil && err != r.Nil {
+		return err
+	}
+
+	if userID != "" {
+		if err = repo.client.SRem(ctx, "user-sessions:"+userID, id).Err(); err != nil {
+			return err
+		}
+	}
+
 	return repo.client.Del(ctx, "user:"+id).Err()
 }
 
@@ -41,7 +83,7 @@ func (repo *redis) GetByID(ctx context.Context, id string) (session entities.Ses
 		return entities.Session{}, err
 	}
 
-	if len(result) != 5 {
+	if len(result) < 5 {
 		return entities.Session{}, NotValidRefreshTokenErr
 	}
 
@@ -51,6 +93,10 @@ func (repo *redis) GetByID(ctx context.Context, id string) (session entities.Ses
 	session.UserID = result["userID"]
 	session.Expire, err = time.Parse(time.RFC3339, result["expire"])
 	session.Updated = result["updated"] == "1"
+	session.UpdatedAt, _ = time.Parse(time.RFC3339Nano, result["updatedAt"])
+	session.Family = result["family"]
+	session.Parent = result["parent"]
+	session.ActorID = result["actorID"]
 
 	if err != nil {
 		return entities.Session{}, err
@@ -59,6 +105,63 @@ func (repo *redis) GetByID(ctx context.Context, id string) (session entities.Ses
 	return
 }
 
+func (repo *redis) GetByUserID(ctx context.Context, userID string) ([]entities.Session, error) {
+	ids, err := repo.client.SMembers(ctx, "user-sessions:"+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]entities.Session, 0, len(ids))
+	for _, id := range ids {
+		session, err := repo.GetByID(ctx, id)
+		if err == NotValidRefreshTokenErr {
+			repo.client.SRem(ctx, "user-sessions:"+userID, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (repo *redis) RemoveByUserID(ctx context.Context, userID string, except ...string) error {
+	ids, err := repo.client.SMembers(ctx, "user-sessions:"+userID).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if slices.Contains(except, id) {
+			continue
+		}
+
+		if err = repo.RemoveByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *redis) RemoveByFamily(ctx context.Context, family string) error {
+	ids, err := repo.client.SMembers(ctx, "session-family:"+family).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range append(ids, family) {
+		if err = repo.RemoveByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return repo.client.Del(ctx, "session-family:"+family).Err()
+}
+
 func (repo *redis) Update(ctx context.Context, session entities.Session) error {
 	return repo.Add(ctx, session)
 }
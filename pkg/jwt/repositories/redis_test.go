@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	r "github.com/redis/go-redis/v9"
+	"studyum/pkg/jwt/entities"
+	"testing"
+	"time"
+)
+
+func newTestRedis(t *testing.T) Repository {
+	server := miniredis.RunT(t)
+	return NewRedis(r.NewClient(&r.Options{Addr: server.Addr()}))
+}
+
+func TestRedisSessionFamily(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRedis(t)
+
+	expire := time.Now().Add(time.Hour).Truncate(time.Second)
+	updatedAt := time.Now()
+	sessions := []entities.Session{
+		{ID: "root", Token: "root|t", UserID: "user", Expire: expire, Updated: true, UpdatedAt: updatedAt, Family: "root"},
+		{ID: "child", Token: "child|t", UserID: "user", Expire: expire, Family: "root", Parent: "root"},
+		{ID: "other", Token: "other|t", UserID: "user", Expire: expire, Family: "other"},
+	}
+	for _, session := range sessions {
+		assert.Equal(t, repo.Add(ctx, session), nil)
+	}
+
+	session, err := repo.GetByID(ctx, "root")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.Updated, true)
+	assert.Equal(t, session.UpdatedAt.Equal(updatedAt), true)
+	assert.Equal(t, session.Expire.Equal(expire), true)
+
+	session, err = repo.GetByID(ctx, "child")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.Family, "root")
+	assert.Equal(t, session.Parent, "root")
+
+	assert.Equal(t, repo.RemoveByFamily(ctx, "root"), nil)
+
+	for _, id := range []string{"root", "child"} {
+		_, err = repo.GetByID(ctx, id)
+		assert.Equal(t, errors.Is(err, NotValidRefreshTokenErr), true)
+	}
+
+	left, err := repo.GetByUserID(ctx, "user")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(left), 1)
+	assert.Equal(t, left[0].ID, "other")
+}
+
+func TestRedisSessionWithoutFamily(t *testing.T) {
+	ctx := context.Background()
+	client := r.NewClient(&r.Options{Addr: miniredis.RunT(t).Addr()})
+	repo := NewRedis(client)
+
+	// sessions stored before families were introduced
+	err := client.HSet(ctx, "user:legacy",
+		"token", "legacy|t",
+		"ip", "ip",
+		"userID", "user",
+		"expire", time.Now().Add(time.Hour).Format(time.RFC3339),
+		"updated", false,
+	).Err()
+	assert.Equal(t, err, nil)
+
+	session, err := repo.GetByID(ctx, "legacy")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, session.GetFamily(), "legacy")
+
+	assert.Equal(t, repo.RemoveByFamily(ctx, "legacy"), nil)
+
+	_, err = repo.GetByID(ctx, "legacy")
+	assert.Equal(t, errors.Is(err, NotValidRefreshTokenErr), true)
+}
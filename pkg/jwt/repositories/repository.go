@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"github.com/pkg/errors"
+	"studyum/pkg/encryption"
 	"studyum/pkg/jwt/entities"
 )
 
@@ -12,6 +13,25 @@ type Repository interface {
 	Add(ctx context.Context, session entities.Session) error
 	RemoveByID(ctx context.Context, id string) error
 	GetByID(ctx context.Context, id string) (entities.Session, error)
+	GetByUserID(ctx context.Context, userID string) ([]entities.Session, error)
+	RemoveByUserID(ctx context.Context, userID string, except ...string) error
+	RemoveByFamily(ctx context.Context, family string) error
 	Update(ctx context.Context, session entities.Session) error
 	RemoveExpired(ctx context.Context) (int, error)
 }
+
+// KeyRepository stores signing keys, private keys are kept encrypted with the encryption secret
+type KeyRepository interface {
+	AddKey(ctx context.Context, key entities.SigningKey) error
+	GetKeys(ctx context.Context) ([]entities.SigningKey, error)
+}
+
+func encryptKey(encryption encryption.Encryption, key entities.SigningKey) entities.SigningKey {
+	key.PrivateKey = []byte(encryption.EncryptString(string(key.PrivateKey)))
+	return key
+}
+
+func decryptKey(encryption encryption.Encryption, key entities.SigningKey) entities.SigningKey {
+	key.PrivateKey = []byte(encryption.DecryptString(string(key.PrivateKey)))
+	return key
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+var ErrTooManyRequests = errors.New("too many requests")
+
+// Error is returned while a key is locked, RetryAfter is the time left until the lock is released
+type Error struct {
+	RetryAfter time.Duration
+}
+
+func (e Error) Error() string {
+	return ErrTooManyRequests.Error() + ", retry after " + strconv.Itoa(e.Seconds()) + "s"
+}
+
+func (e Error) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// Seconds returns RetryAfter rounded up as it is sent in the Retry-After header
+func (e Error) Seconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+type Options struct {
+	// Attempts is the amount of hits allowed during the Window before the key gets locked
+	Attempts int64
+	Window   time.Duration
+
+	// Lockout is the first lock duration, it doubles with every next lock up to MaxLockout
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+type Limiter interface {
+	Check(ctx context.Context, keys ...string) error
+	Hit(ctx context.Context, keys ...string) error
+	Reset(ctx context.Context, keys ...string) error
+}
+
+type limiter struct {
+	client  *redis.Client
+	prefix  string
+	options Options
+}
+
+func NewRedis(client *redis.Client, prefix string, options Options) Limiter {
+	return &limiter{client: client, prefix: "rate-limit:" + prefix + ":", options: options}
+}
+
+// Check returns Error if any of the keys is locked
+func (l *limiter) Check(ctx context.Context, keys ...string) error {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		ttl, err := l.client.PTTL(ctx, l.prefix+key+":lock").Result()
+		if err != nil {
+			return err
+		}
+
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	if retryAfter > 0 {
+		return Error{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// Hit counts an attempt for every key and locks the keys which run out of attempts
+func (l *limiter) Hit(ctx context.Context, keys ...string) error {
+	if err := l.Check(ctx, keys...); err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		lockout, err := l.hit(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if lockout > retryAfter {
+			retryAfter = lockout
+		}
+	}
+
+	if retryAfter > 0 {
+		return Error{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (l *limiter) hit(ctx context.Context, key string) (time.Duration, error) {
+	hits, err := l.client.Incr(ctx, l.prefix+key+":hits").Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if hits == 1 {
+		if err = l.client.Expire(ctx, l.prefix+key+":hits", l.options.Window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	if hits < l.options.Attempts {
+		return 0, nil
+	}
+
+	lockouts, err := l.client.Incr(ctx, l.prefix+key+":lockouts").Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// lockouts are remembered long enough for the next lock to be longer
+	if err = l.client.Expire(ctx, l.prefix+key+":lockouts", l.options.MaxLockout*2).Err(); err != nil {
+		return 0, err
+	}
+
+	lockout := lockoutDuration(l.options.Lockout, l.options.MaxLockout, lockouts)
+	if err = l.client.Set(ctx, l.prefix+key+":lock", lockouts, lockout).Err(); err != nil {
+		return 0, err
+	}
+
+	return lockout, l.client.Del(ctx, l.prefix+key+":hits").Err()
+}
+
+// Reset forgets attempts of the keys, active locks stay in place
+func (l *limiter) Reset(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := l.client.Del(ctx, l.prefix+key+":hits", l.prefix+key+":lockouts").Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func lockoutDuration(lockout, max time.Duration, lockouts int64) time.Duration {
+	d := lockout
+	for i := int64(1); i < lockouts && d < max; i++ {
+		d *= 2
+	}
+
+	if d > max {
+		return max
+	}
+
+	return d
+}
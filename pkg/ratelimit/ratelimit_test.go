@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+var testOptions = Options{Attempts: 3, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Minute * 5}
+
+func newTestLimiter(t *testing.T) (Limiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	return NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test", testOptions), server
+}
+
+func TestLockoutDuration(t *testing.T) {
+	assert.Equal(t, lockoutDuration(time.Minute, time.Minute*5, 1), time.Minute)
+	assert.Equal(t, lockoutDuration(time.Minute, time.Minute*5, 2), time.Minute*2)
+	assert.Equal(t, lockoutDuration(time.Minute, time.Minute*5, 3), time.Minute*4)
+	assert.Equal(t, lockoutDuration(time.Minute, time.Minute*5, 4), time.Minute*5)
+	assert.Equal(t, lockoutDuration(time.Minute, time.Minute*5, 100), time.Minute*5)
+}
+
+func TestErrorSeconds(t *testing.T) {
+	assert.Equal(t, Error{RetryAfter: time.Millisecond * 1500}.Seconds(), 2)
+	assert.Equal(t, Error{RetryAfter: time.Minute}.Seconds(), 60)
+	assert.Equal(t, errors.Is(errors.Wrap(Error{}, "login"), ErrTooManyRequests), true)
+}
+
+func TestHitLocksAfterAttempts(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newTestLimiter(t)
+
+	assert.Equal(t, limiter.Hit(ctx, "ip:1"), nil)
+	assert.Equal(t, limiter.Hit(ctx, "ip:1"), nil)
+
+	var limitErr Error
+	assert.Equal(t, errors.As(limiter.Hit(ctx, "ip:1"), &limitErr), true)
+	assert.Equal(t, limitErr.RetryAfter, time.Minute)
+
+	assert.Equal(t, errors.Is(limiter.Check(ctx, "ip:1"), ErrTooManyRequests), true)
+	assert.Equal(t, errors.Is(limiter.Check(ctx, "ip:2", "ip:1"), ErrTooManyRequests), true)
+	assert.Equal(t, limiter.Check(ctx, "ip:2"), nil)
+}
+
+func TestLockoutGrowsExponentially(t *testing.T) {
+	ctx := context.Background()
+	limiter, server := newTestLimiter(t)
+
+	lockouts := []time.Duration{time.Minute, time.Minute * 2, time.Minute * 4, time.Minute * 5}
+	for _, lockout := range lockouts {
+		var err error
+		for i := int64(0); i < testOptions.Attempts; i++ {
+			err = limiter.Hit(ctx, "account:user")
+		}
+
+		var limitErr Error
+		assert.Equal(t, errors.As(err, &limitErr), true)
+		assert.Equal(t, limitErr.RetryAfter, lockout)
+
+		server.FastForward(lockout)
+		assert.Equal(t, limiter.Check(ctx, "account:user"), nil)
+	}
+}
+
+func TestResetForgetsAttempts(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newTestLimiter(t)
+
+	assert.Equal(t, limiter.Hit(ctx, "account:user"), nil)
+	assert.Equal(t, limiter.Hit(ctx, "account:user"), nil)
+	assert.Equal(t, limiter.Reset(ctx, "account:user"), nil)
+
+	assert.Equal(t, limiter.Hit(ctx, "account:user"), nil)
+	assert.Equal(t, limiter.Hit(ctx, "account:user"), nil)
+}
@@ -0,0 +1,41 @@
+package totp
+
+import (
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+// base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(testSecret, Step(time.Unix(unix, 0)))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, code, expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, _ := Code(testSecret, Step(now)-1)
+
+	step, ok := Validate(testSecret, code, now, 0)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, step, Step(now)-1)
+
+	_, ok = Validate(testSecret, code, now, step)
+	assert.Equal(t, ok, false)
+
+	_, ok = Validate(testSecret, code, now.Add(time.Minute*2), 0)
+	assert.Equal(t, ok, false)
+}
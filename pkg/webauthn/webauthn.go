@@ -0,0 +1,436 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotValidResponse = errors.New("not valid webauthn response")
+	// ErrSignCount is returned when the authenticator counter goes back, the credential may be cloned
+	ErrSignCount = errors.New("webauthn signature counter did not increase")
+)
+
+const (
+	ChallengeSize = 32
+
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedData     = 0x40
+	authenticatorDataMin = 37
+)
+
+var encoding = base64.RawURLEncoding
+
+// Bytes is encoded as unpadded base64url in json like the WebAuthn JSON serialization does
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	decoded, err := encoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+type Config struct {
+	// RPID is the domain the credentials are scoped to, it must be the origin host or its registrable suffix
+	RPID   string
+	RPName string
+	// Origins are the allowed origins of the client data, e.g. https://studyum.net
+	Origins []string
+	Timeout time.Duration
+}
+
+type User struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// Credential is a registered public key credential, PublicKey is COSE encoded
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+	AAGUID    []byte
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   Bytes  `json:"id"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are passed to navigator.credentials.create as publicKey
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   User                   `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are passed to navigator.credentials.get as publicKey,
+// empty AllowCredentials let the authenticator offer discoverable credentials
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+type AttestationResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes `json:"clientDataJSON"`
+		AttestationObject Bytes `json:"attestationObject"`
+	} `json:"response"`
+}
+
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    Bytes  `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Bytes `json:"clientDataJSON"`
+		AuthenticatorData Bytes `json:"authenticatorData"`
+		Signature         Bytes `json:"signature"`
+		UserHandle        Bytes `json:"userHandle"`
+	} `json:"response"`
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// RelyingParty runs registration and authentication ceremonies, user verification is always required
+type RelyingParty struct {
+	config Config
+}
+
+func NewRelyingParty(config Config) RelyingParty {
+	if config.Timeout == 0 {
+		config.Timeout = time.Minute * 5
+	}
+
+	return RelyingParty{config: config}
+}
+
+// NewChallenge returns random bytes which are sent in the options and checked in the response
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	list := make([]CredentialDescriptor, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, CredentialDescriptor{Type: "public-key", ID: id})
+	}
+
+	return list
+}
+
+// CreationOptions builds registration options, exclude lists credentials the user already has
+func (rp RelyingParty) CreationOptions(user User, challenge []byte, exclude [][]byte) CreationOptions {
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.config.RPID, Name: rp.config.RPName},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:                rp.config.Timeout.Milliseconds(),
+		ExcludeCredentials:     descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{ResidentKey: "preferred", UserVerification: "required"},
+		Attestation:            "none",
+	}
+}
+
+// RequestOptions builds authentication options, allow lists credentials of a known user
+func (rp RelyingParty) RequestOptions(challenge []byte, allow [][]byte) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		RPID:             rp.config.RPID,
+		Timeout:          rp.config.Timeout.Milliseconds(),
+		AllowCredentials: descriptors(allow),
+		UserVerification: "required",
+	}
+}
+
+// VerifyRegistration checks the attestation response of the challenge and returns the new credential.
+// Only none and packed attestations are accepted, attestation certificates are not checked against a trust store.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, response AttestationResponse) (Credential, error) {
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	value, rest, err := decodeCBOR(response.Response.AttestationObject)
+	if err != nil || len(rest) != 0 {
+		return Credential{}, fmt.Errorf("%w: attestation object", ErrNotValidResponse)
+	}
+
+	object, ok := value.(map[any]any)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: attestation object", ErrNotValidResponse)
+	}
+
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[any]any)
+	rawData, _ := object["authData"].([]byte)
+
+	data, err := rp.verifyAuthenticatorData(rawData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if data.flags&flagAttestedData == 0 || len(data.credentialID) == 0 {
+		return Credential{}, fmt.Errorf("%w: attested credential data", ErrNotValidResponse)
+	}
+
+	if len(response.RawID) != 0 && !bytes.Equal(response.RawID, data.credentialID) {
+		return Credential{}, fmt.Errorf("%w: credential id", ErrNotValidResponse)
+	}
+
+	key, err := parsePublicKey(data.publicKey)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	clientDataHash := sha256.Sum256(response.Response.ClientDataJSON)
+	if err = verifyAttestation(format, statement, key, append(append([]byte{}, rawData...), clientDataHash[:]...)); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{ID: data.credentialID, PublicKey: data.publicKey, SignCount: data.signCount, AAGUID: data.aaguid}, nil
+}
+
+// VerifyAssertion checks the assertion of the challenge made with the credential and returns the new signature counter
+func (rp RelyingParty) VerifyAssertion(challenge []byte, response AssertionResponse, credential Credential) (uint32, error) {
+	if len(response.RawID) != 0 && !bytes.Equal(response.RawID, credential.ID) {
+		return 0, fmt.Errorf("%w: credential id", ErrNotValidResponse)
+	}
+
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	data, err := rp.verifyAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(response.Response.ClientDataJSON)
+	message := append(append([]byte{}, response.Response.AuthenticatorData...), clientDataHash[:]...)
+	if !key.verify(message, response.Response.Signature) {
+		return 0, fmt.Errorf("%w: signature", ErrNotValidResponse)
+	}
+
+	if (data.signCount != 0 || credential.SignCount != 0) && data.signCount <= credential.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return data.signCount, nil
+}
+
+func (rp RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("%w: client data", ErrNotValidResponse)
+	}
+
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: client data type", ErrNotValidResponse)
+	}
+
+	received, err := encoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil || len(challenge) == 0 || !bytes.Equal(received, challenge) {
+		return fmt.Errorf("%w: challenge", ErrNotValidResponse)
+	}
+
+	for _, origin := range rp.config.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: origin %s", ErrNotValidResponse, data.Origin)
+}
+
+func (rp RelyingParty) verifyAuthenticatorData(raw []byte) (authenticatorData, error) {
+	data, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return authenticatorData{}, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.config.RPID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return authenticatorData{}, fmt.Errorf("%w: relying party id", ErrNotValidResponse)
+	}
+
+	if data.flags&flagUserPresent == 0 {
+		return authenticatorData{}, fmt.Errorf("%w: user presence", ErrNotValidResponse)
+	}
+
+	if data.flags&flagUserVerified == 0 {
+		return authenticatorData{}, fmt.Errorf("%w: user verification", ErrNotValidResponse)
+	}
+
+	return data, nil
+}
+
+func parseAuthenticatorData(raw []byte) (authenticatorData, error) {
+	if len(raw) < authenticatorDataMin {
+		return authenticatorData{}, fmt.Errorf("%w: authenticator data", ErrNotValidResponse)
+	}
+
+	data := authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	if data.flags&flagAttestedData == 0 {
+		return data, nil
+	}
+
+	rest := raw[authenticatorDataMin:]
+	if len(rest) < 18 {
+		return authenticatorData{}, fmt.Errorf("%w: attested credential data", ErrNotValidResponse)
+	}
+
+	data.aaguid = rest[:16]
+	length := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < length {
+		return authenticatorData{}, fmt.Errorf("%w: credential id", ErrNotValidResponse)
+	}
+
+	data.credentialID = rest[:length]
+	rest = rest[length:]
+
+	// the key is followed by extensions, decoding tells where it ends
+	_, extensions, err := decodeCBOR(rest)
+	if err != nil {
+		return authenticatorData{}, fmt.Errorf("%w: credential public key", ErrNotValidResponse)
+	}
+
+	data.publicKey = rest[:len(rest)-len(extensions)]
+	return data, nil
+}
+
+func verifyAttestation(format string, statement map[any]any, key publicKey, message []byte) error {
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return fmt.Errorf("%w: attestation statement", ErrNotValidResponse)
+		}
+
+		return nil
+	case "packed":
+		alg, _ := statement["alg"].(int64)
+		signature, _ := statement["sig"].([]byte)
+
+		chain, ok := statement["x5c"].([]any)
+		if !ok {
+			if alg != key.alg || !key.verify(message, signature) {
+				return fmt.Errorf("%w: self attestation", ErrNotValidResponse)
+			}
+
+			return nil
+		}
+
+		if len(chain) == 0 {
+			return fmt.Errorf("%w: attestation certificate", ErrNotValidResponse)
+		}
+
+		leaf, _ := chain[0].([]byte)
+		certificate, err := x509.ParseCertificate(leaf)
+		if err != nil {
+			return fmt.Errorf("%w: attestation certificate", ErrNotValidResponse)
+		}
+
+		var algorithm x509.SignatureAlgorithm
+		switch alg {
+		case AlgES256:
+			algorithm = x509.ECDSAWithSHA256
+		case AlgRS256:
+			algorithm = x509.SHA256WithRSA
+		case AlgEdDSA:
+			algorithm = x509.PureEd25519
+		default:
+			return fmt.Errorf("%w: attestation algorithm", ErrNotValidResponse)
+		}
+
+		if err = certificate.CheckSignature(algorithm, message, signature); err != nil {
+			return fmt.Errorf("%w: attestation signature", ErrNotValidResponse)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: attestation format %s", ErrNotValidResponse, format)
+	}
+}
@@ -0,0 +1,91 @@
+package webauthn_test
+
+import (
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/errors"
+	"studyum/pkg/webauthn"
+	"studyum/pkg/webauthn/webauthntest"
+	"testing"
+)
+
+const testOrigin = "https://studyum.net"
+
+var testConfig = webauthn.Config{RPID: "studyum.net", RPName: "Studyum", Origins: []string{testOrigin}}
+
+func register(t *testing.T, rp webauthn.RelyingParty, authenticator *webauthntest.Authenticator) webauthn.Credential {
+	challenge, err := webauthn.NewChallenge()
+	assert.Equal(t, err, nil)
+
+	response, err := authenticator.Create(rp.CreationOptions(webauthn.User{ID: []byte("user"), Name: "teacher"}, challenge, nil))
+	assert.Equal(t, err, nil)
+
+	credential, err := rp.VerifyRegistration(challenge, response)
+	assert.Equal(t, err, nil)
+
+	return credential
+}
+
+func TestCeremonies(t *testing.T) {
+	rp := webauthn.NewRelyingParty(testConfig)
+	authenticator := webauthntest.New(testOrigin)
+
+	credential := register(t, rp, authenticator)
+	assert.Equal(t, len(credential.ID), 16)
+
+	challenge, _ := webauthn.NewChallenge()
+	response, err := authenticator.Get(rp.RequestOptions(challenge, [][]byte{credential.ID}))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, []byte(response.Response.UserHandle), []byte("user"))
+
+	signCount, err := rp.VerifyAssertion(challenge, response, credential)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, signCount, uint32(1))
+
+	// the same assertion can not be replayed with an updated counter
+	credential.SignCount = signCount
+	_, err = rp.VerifyAssertion(challenge, response, credential)
+	assert.Equal(t, errors.Is(err, webauthn.ErrSignCount), true)
+}
+
+func TestAssertionChecks(t *testing.T) {
+	rp := webauthn.NewRelyingParty(testConfig)
+	authenticator := webauthntest.New(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	challenge, _ := webauthn.NewChallenge()
+	other, _ := webauthn.NewChallenge()
+
+	response, _ := authenticator.Get(rp.RequestOptions(challenge, nil))
+	_, err := rp.VerifyAssertion(other, response, credential)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+
+	response, _ = authenticator.Get(rp.RequestOptions(challenge, nil))
+	response.Response.Signature[len(response.Response.Signature)-1] ^= 0xff
+	_, err = rp.VerifyAssertion(challenge, response, credential)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+
+	authenticator.Origin = "https://studyum.example"
+	response, _ = authenticator.Get(rp.RequestOptions(challenge, nil))
+	_, err = rp.VerifyAssertion(challenge, response, credential)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+	authenticator.Origin = testOrigin
+
+	authenticator.SkipUserVerification = true
+	response, _ = authenticator.Get(rp.RequestOptions(challenge, nil))
+	_, err = rp.VerifyAssertion(challenge, response, credential)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+}
+
+func TestRegistrationChecks(t *testing.T) {
+	rp := webauthn.NewRelyingParty(testConfig)
+	challenge, _ := webauthn.NewChallenge()
+
+	response, _ := webauthntest.New(testOrigin).Create(webauthn.NewRelyingParty(webauthn.Config{RPID: "evil.net"}).CreationOptions(webauthn.User{ID: []byte("user")}, challenge, nil))
+	_, err := rp.VerifyRegistration(challenge, response)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+
+	response, _ = webauthntest.New(testOrigin).Create(rp.CreationOptions(webauthn.User{ID: []byte("user")}, challenge, nil))
+	response.Response.AttestationObject = response.Response.AttestationObject[:20]
+	_, err = rp.VerifyRegistration(challenge, response)
+	assert.Equal(t, errors.Is(err, webauthn.ErrNotValidResponse), true)
+}
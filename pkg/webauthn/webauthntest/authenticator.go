@@ -0,0 +1,160 @@
+// Package webauthntest provides a software authenticator for testing WebAuthn ceremonies
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"studyum/pkg/webauthn"
+)
+
+var ErrNoCredential = errors.New("authenticator has no allowed credential")
+
+type credential struct {
+	id         []byte
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+// Authenticator is a platform authenticator which creates ES256 credentials with none attestation.
+// It always reports user presence and verification unless SkipUserVerification is set.
+type Authenticator struct {
+	Origin               string
+	SkipUserVerification bool
+
+	credentials []*credential
+}
+
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// Create performs navigator.credentials.create with the options
+func (a *Authenticator) Create(options webauthn.CreationOptions) (webauthn.AttestationResponse, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return webauthn.AttestationResponse{}, err
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return webauthn.AttestationResponse{}, err
+	}
+
+	c := &credential{id: id, userHandle: options.User.ID, key: key}
+	a.credentials = append(a.credentials, c)
+
+	attested := make([]byte, 18, 18+len(id))
+	binary.BigEndian.PutUint16(attested[16:], uint16(len(id)))
+	attested = append(append(attested, id...), publicKey(&key.PublicKey)...)
+
+	authData := a.authenticatorData(options.RP.ID, 0x40, 0, attested)
+	attestation := encodeMap(
+		pair{"fmt", "none"},
+		pair{"attStmt", []pair{}},
+		pair{"authData", authData},
+	)
+
+	var response webauthn.AttestationResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(id)
+	response.RawID = id
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = a.clientData("webauthn.create", options.Challenge)
+	response.Response.AttestationObject = attestation
+
+	return response, nil
+}
+
+// Get performs navigator.credentials.get with the options, the newest allowed credential signs the assertion
+func (a *Authenticator) Get(options webauthn.RequestOptions) (webauthn.AssertionResponse, error) {
+	var c *credential
+	for i := len(a.credentials) - 1; i >= 0 && c == nil; i-- {
+		if allowed(options.AllowCredentials, a.credentials[i].id) {
+			c = a.credentials[i]
+		}
+	}
+	if c == nil {
+		return webauthn.AssertionResponse{}, ErrNoCredential
+	}
+
+	c.signCount++
+	authData := a.authenticatorData(options.RPID, 0, c.signCount, nil)
+	clientData := a.clientData("webauthn.get", options.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return webauthn.AssertionResponse{}, err
+	}
+
+	var response webauthn.AssertionResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(c.id)
+	response.RawID = c.id
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = clientData
+	response.Response.AuthenticatorData = authData
+	response.Response.Signature = signature
+	response.Response.UserHandle = c.userHandle
+
+	return response, nil
+}
+
+func allowed(list []webauthn.CredentialDescriptor, id []byte) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, descriptor := range list {
+		if string(descriptor.ID) == string(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *Authenticator) authenticatorData(rpID string, flags byte, signCount uint32, attested []byte) []byte {
+	flags |= 0x01
+	if !a.SkipUserVerification {
+		flags |= 0x04
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], signCount)
+
+	return append(data, attested...)
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge []byte) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.Origin,
+	})
+
+	return data
+}
+
+// publicKey encodes the key as an ES256 COSE_Key
+func publicKey(key *ecdsa.PublicKey) []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+
+	return encodeMap(
+		pair{int64(1), int64(2)},
+		pair{int64(3), int64(webauthn.AlgES256)},
+		pair{int64(-1), int64(1)},
+		pair{int64(-2), x},
+		pair{int64(-3), y},
+	)
+}
@@ -0,0 +1,55 @@
+package webauthntest
+
+import "encoding/binary"
+
+// pair is a map entry, maps are encoded as ordered lists of pairs to keep the output deterministic
+type pair struct {
+	key   any
+	value any
+}
+
+func encodeMap(pairs ...pair) []byte {
+	data := header(5, uint64(len(pairs)))
+	for _, p := range pairs {
+		data = append(data, encode(p.key)...)
+		data = append(data, encode(p.value)...)
+	}
+
+	return data
+}
+
+func encode(value any) []byte {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return header(1, uint64(-1-v))
+		}
+		return header(0, uint64(v))
+	case []byte:
+		return append(header(2, uint64(len(v))), v...)
+	case string:
+		return append(header(3, uint64(len(v))), v...)
+	case []pair:
+		return encodeMap(v...)
+	default:
+		panic("webauthntest: unsupported cbor value")
+	}
+}
+
+func header(major byte, argument uint64) []byte {
+	major <<= 5
+	switch {
+	case argument < 24:
+		return []byte{major | byte(argument)}
+	case argument <= 0xff:
+		return []byte{major | 24, byte(argument)}
+	case argument <= 0xffff:
+		data := []byte{major | 25, 0, 0}
+		binary.BigEndian.PutUint16(data[1:], uint16(argument))
+		return data
+	default:
+		data := []byte{major | 26, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(data[1:], uint32(argument))
+		return data
+	}
+}